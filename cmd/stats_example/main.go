@@ -59,10 +59,10 @@ func main() {
 			}
 		}
 
-		// Count wins (Winner: -1 = player1, 1 = player2)
-		if game.Winner == -1 {
+		// Count wins
+		if game.Winner == xgparser.Player1 {
 			player1Wins++
-		} else if game.Winner == 1 {
+		} else if game.Winner == xgparser.Player2 {
 			player2Wins++
 		}
 	}
@@ -81,37 +81,25 @@ func main() {
 	// Analyze move quality (for games with analysis)
 	fmt.Printf("=== Move Quality Analysis ===\n")
 	analyzedMoves := 0
-	totalEquityLoss := float32(0.0)
+	analyzedCubes := 0
 
 	for _, game := range match.Games {
 		for _, move := range game.Moves {
-			if move.MoveType == "checker" && move.CheckerMove != nil {
-				analysis := move.CheckerMove.Analysis
-				if len(analysis) > 1 {
-					// Compare played move (assumed to be first) with best move
-					playedEquity := analysis[0].Equity
-					bestEquity := playedEquity
-
-					// Find best equity
-					for _, a := range analysis {
-						if a.Equity > bestEquity {
-							bestEquity = a.Equity
-						}
-					}
-
-					equityLoss := bestEquity - playedEquity
-					if equityLoss > 0 {
-						totalEquityLoss += equityLoss
-					}
-					analyzedMoves++
-				}
+			if move.CheckerMove != nil && len(move.CheckerMove.Analysis) > 1 {
+				analyzedMoves++
+			}
+			if move.CubeMove != nil && move.CubeMove.Analysis != nil {
+				analyzedCubes++
 			}
 		}
 	}
 
-	if analyzedMoves > 0 {
+	checkerLoss, cubeLoss := match.TotalEquityLoss()
+	if analyzedMoves > 0 || analyzedCubes > 0 {
 		fmt.Printf("Analyzed Checker Moves: %d\n", analyzedMoves)
-		fmt.Printf("Average Equity Loss: %.4f\n", totalEquityLoss/float32(analyzedMoves))
+		fmt.Printf("Analyzed Cube Decisions: %d\n", analyzedCubes)
+		fmt.Printf("Checker Equity Loss: %.4f\n", checkerLoss)
+		fmt.Printf("Cube Equity Loss: %.4f\n", cubeLoss)
 	} else {
 		fmt.Printf("No analyzed moves found in this match.\n")
 	}
@@ -120,9 +108,9 @@ func main() {
 	fmt.Printf("\n=== Game-by-Game Summary ===\n")
 	for _, game := range match.Games {
 		winner := "Unknown"
-		if game.Winner == -1 {
+		if game.Winner == xgparser.Player1 {
 			winner = match.Metadata.Player1Name
-		} else if game.Winner == 1 {
+		} else if game.Winner == xgparser.Player2 {
 			winner = match.Metadata.Player2Name
 		}
 		fmt.Printf("Game %d: Score %d-%d, %d moves, Winner: %s (%d points)\n",
@@ -12,6 +12,7 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -31,6 +32,15 @@ type MatchSummary struct {
 	TotalMoves  int    `json:"total_moves"`
 }
 
+// parseErrorStatus returns the HTTP status for an XG parse error:
+// uploads that are not XG files at all are rejected as unsupported media
+func parseErrorStatus(err error) int {
+	if errors.Is(err, xgparser.ErrNotXGFile) {
+		return http.StatusUnsupportedMediaType
+	}
+	return http.StatusBadRequest
+}
+
 // uploadHandler handles XG file uploads
 func uploadHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -60,13 +70,10 @@ func uploadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create a ReadSeeker from the data
-	reader := io.NewSectionReader(bytes.NewReader(fileData), 0, int64(len(fileData)))
-
-	// Parse the XG file
-	match, err := xgparser.ParseXGFromReader(reader)
+	// Parse the XG file, accepting gzip or base64 wrapped uploads
+	match, err := xgparser.ParseXGAuto(bytes.NewReader(fileData))
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to parse XG file: %v", err), http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("Failed to parse XG file: %v", err), parseErrorStatus(err))
 		return
 	}
 
@@ -121,10 +128,9 @@ func fullMatchHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse
-	reader := io.NewSectionReader(bytes.NewReader(fileData), 0, int64(len(fileData)))
-	match, err := xgparser.ParseXGFromReader(reader)
+	match, err := xgparser.ParseXGAuto(bytes.NewReader(fileData))
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to parse XG file: %v", err), http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("Failed to parse XG file: %v", err), parseErrorStatus(err))
 		return
 	}
 
@@ -134,6 +140,12 @@ func fullMatchHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonData)
 }
 
+// schemaHandler returns the JSON schema of the /full output
+func schemaHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Write(xgparser.MatchJSONSchema())
+}
+
 // homeHandler serves a simple HTML form
 func homeHandler(w http.ResponseWriter, r *http.Request) {
 	html := `
@@ -231,11 +243,13 @@ func main() {
 	http.HandleFunc("/upload", uploadHandler)
 	http.HandleFunc("/full", fullMatchHandler)
 	http.HandleFunc("/text", textPositionHandler)
+	http.HandleFunc("/schema", schemaHandler)
 
 	fmt.Println("Server starting on http://localhost:8080")
 	fmt.Println("Upload XG files to analyze matches via web interface")
 	fmt.Println("  - /upload : Quick summary of XG match files")
 	fmt.Println("  - /full   : Full match analysis of XG files")
 	fmt.Println("  - /text   : Parse XG text positions (EN, FR, DE, JP)")
+	fmt.Println("  - /schema : JSON schema of the /full output")
 	log.Fatal(http.ListenAndServe(":8080", nil))
 }
@@ -81,14 +81,14 @@ func parseFile(filename string) {
 	// Print analysis
 	if cubeMove.Analysis != nil {
 		fmt.Printf("\nAnalysis (Depth: %d-ply):\n", cubeMove.Analysis.AnalysisDepth)
-		fmt.Printf("  Player Winning Chances: %.2f%% (G: %.2f%%, BG: %.2f%%)\n",
-			cubeMove.Analysis.Player1WinRate*100,
-			cubeMove.Analysis.Player1GammonRate*100,
-			cubeMove.Analysis.Player1BgRate*100)
-		fmt.Printf("  Opponent Winning Chances: %.2f%% (G: %.2f%%, BG: %.2f%%)\n",
-			(1.0-cubeMove.Analysis.Player1WinRate)*100,
-			cubeMove.Analysis.Player2GammonRate*100,
-			cubeMove.Analysis.Player2BgRate*100)
+		fmt.Printf("  Player Winning Chances: %s (G: %s, BG: %s)\n",
+			xgparser.FormatPercent(cubeMove.Analysis.Player1WinRate),
+			xgparser.FormatPercent(cubeMove.Analysis.Player1GammonRate),
+			xgparser.FormatPercent(cubeMove.Analysis.Player1BgRate))
+		fmt.Printf("  Opponent Winning Chances: %s (G: %s, BG: %s)\n",
+			xgparser.FormatPercent(1.0-cubeMove.Analysis.Player1WinRate),
+			xgparser.FormatPercent(cubeMove.Analysis.Player2GammonRate),
+			xgparser.FormatPercent(cubeMove.Analysis.Player2BgRate))
 
 		fmt.Printf("\n  Cubeless Equities:\n")
 		fmt.Printf("    No Double: %+.3f\n", cubeMove.Analysis.CubelessNoDouble)
@@ -101,14 +101,18 @@ func parseFile(filename string) {
 
 		fmt.Printf("\n  Best Action: ")
 		switch cubeMove.CubeAction {
-		case 0:
+		case xgparser.CubeNoDouble:
 			fmt.Println("No Double")
-		case 1:
+		case xgparser.CubeDouble:
 			fmt.Println("Double/Redouble")
-		case 2:
+		case xgparser.CubeTake:
 			fmt.Println("Take")
-		case 3:
+		case xgparser.CubePass:
 			fmt.Println("Pass")
+		case xgparser.CubeBeaver:
+			fmt.Println("Beaver")
+		case xgparser.CubeRaccoon:
+			fmt.Println("Raccoon")
 		default:
 			fmt.Println("Unknown")
 		}
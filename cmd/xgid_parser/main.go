@@ -110,10 +110,10 @@ func parseFile(filename string) {
 				fmt.Printf(" (%+.3f)", equityDiff)
 			}
 			fmt.Println()
-			fmt.Printf("     Player: %.2f%% (G: %.2f%%, BG: %.2f%%)\n",
-				analysis.Player1WinRate*100, analysis.Player1GammonRate*100, analysis.Player1BgRate*100)
-			fmt.Printf("     Opponent: %.2f%% (G: %.2f%%, BG: %.2f%%)\n",
-				(1.0-analysis.Player1WinRate)*100, analysis.Player2GammonRate*100, analysis.Player2BgRate*100)
+			fmt.Printf("     Player: %s (G: %s, BG: %s)\n",
+				xgparser.FormatPercent(analysis.Player1WinRate), xgparser.FormatPercent(analysis.Player1GammonRate), xgparser.FormatPercent(analysis.Player1BgRate))
+			fmt.Printf("     Opponent: %s (G: %s, BG: %s)\n",
+				xgparser.FormatPercent(1.0-analysis.Player1WinRate), xgparser.FormatPercent(analysis.Player2GammonRate), xgparser.FormatPercent(analysis.Player2BgRate))
 
 			// Only print first 3 moves in summary, unless verbose mode
 			if i >= 2 && len(move.Analysis) > 3 {
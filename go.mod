@@ -1,3 +1,5 @@
 module github.com/kevung/xgparser
 
 go 1.21
+
+require golang.org/x/text v0.14.0
@@ -0,0 +1,300 @@
+//
+//   xgboard.go - XG ASCII board diagrams
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var boardPointRegex = regexp.MustCompile(`\d+`)
+
+// boardColumns maps each point number in a board header line to the column of its checkers.
+// Checkers are aligned with the last digit of the point number.
+func boardColumns(header string) map[int]int {
+	cols := make(map[int]int)
+	for _, m := range boardPointRegex.FindAllStringIndex(header, -1) {
+		point, _ := strconv.Atoi(header[m[0]:m[1]])
+		if point >= 1 && point <= 24 {
+			cols[point] = m[1] - 1
+		}
+	}
+	return cols
+}
+
+// boardStack accumulates the content of one board column.
+// XG draws at most 5 checkers per column; taller stacks show the total as a number.
+type boardStack struct {
+	x, o  int
+	total int
+}
+
+func (s *boardStack) add(line string, col int) {
+	if col >= len(line) {
+		return
+	}
+	switch c := line[col]; {
+	case c == 'X':
+		s.x++
+	case c == 'O':
+		s.o++
+	case c >= '0' && c <= '9':
+		start, end := col, col+1
+		for start > 0 && line[start-1] >= '0' && line[start-1] <= '9' {
+			start--
+		}
+		for end < len(line) && line[end] >= '0' && line[end] <= '9' {
+			end++
+		}
+		s.total, _ = strconv.Atoi(line[start:end])
+	}
+}
+
+// count returns the signed number of checkers, X positive and O negative
+func (s *boardStack) count() (int8, error) {
+	if s.x > 0 && s.o > 0 {
+		return 0, fmt.Errorf("column has both X and O checkers")
+	}
+	n := s.x + s.o
+	if s.total > 0 {
+		n = s.total
+	}
+	if s.o > 0 {
+		return -int8(n), nil
+	}
+	return int8(n), nil
+}
+
+// ParseASCIIBoard reads the checker position from an XG ASCII board diagram.
+// lines may contain the surrounding text of an XG position file; only the board
+// between the "+13-14-...-24-+" and "+12-11-...--1-+" lines is used.
+// The position is returned from X's point of view, as drawn: index 1-24 are the
+// board points, X checkers are positive, O checkers negative, X's bar is index 25
+// and O's bar index 0.
+func ParseASCIIBoard(lines []string) (Position, error) {
+	var pos Position
+	var topCols, bottomCols map[int]int
+	var top, bottom []string
+
+	inBottom := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "+13-14-15"):
+			topCols = boardColumns(line)
+		case strings.HasPrefix(trimmed, "+12-11-10"):
+			bottomCols = boardColumns(line)
+		case topCols == nil || bottomCols != nil || !strings.HasPrefix(trimmed, "|"):
+			// Outside the board
+		case strings.Contains(line, "|BAR|"):
+			inBottom = true
+		case inBottom:
+			bottom = append(bottom, line)
+		default:
+			top = append(top, line)
+		}
+		if bottomCols != nil {
+			break
+		}
+	}
+
+	if len(topCols) != 12 || len(bottomCols) != 12 {
+		return pos, fmt.Errorf("board diagram not found")
+	}
+
+	// The bar column lies in the middle between points 18 and 19
+	barCol := (topCols[18] + topCols[19]) / 2
+
+	for point := 1; point <= 24; point++ {
+		col, rows := bottomCols[point], bottom
+		if point > 12 {
+			col, rows = topCols[point], top
+		}
+
+		var stack boardStack
+		for _, line := range rows {
+			stack.add(line, col)
+		}
+		count, err := stack.count()
+		if err != nil {
+			return pos, fmt.Errorf("point %d: %v", point, err)
+		}
+		pos.Checkers[point] = count
+	}
+
+	// Both players can be on the bar, each half of the bar column is counted on its own
+	for _, half := range [][]string{top, bottom} {
+		var bar boardStack
+		for _, line := range half {
+			bar.add(line, barCol)
+		}
+		count, err := bar.count()
+		if err != nil {
+			return pos, fmt.Errorf("bar: %v", err)
+		}
+		if count > 0 {
+			pos.Checkers[25] += count
+		} else {
+			pos.Checkers[0] += count
+		}
+	}
+
+	if x, o := checkerCount(pos.Checkers); x > 15 || o > 15 {
+		return pos, fmt.Errorf("board has %d X and %d O checkers", x, o)
+	}
+
+	return pos, nil
+}
+
+// xgidBoardPosition decodes an XGID position string in board order:
+// character i is point i from X's point of view, 0 is O's bar and 25 is X's bar
+func xgidBoardPosition(positionID string) [26]int8 {
+	var checkers [26]int8
+	for i := 0; i < 26 && i < len(positionID); i++ {
+		c := positionID[i]
+		if c >= 'A' && c <= 'O' {
+			checkers[i] = int8(c - 'A' + 1)
+		} else if c >= 'a' && c <= 'o' {
+			checkers[i] = -int8(c - 'a' + 1)
+		}
+	}
+	return checkers
+}
+
+// PointMismatch is a board index where the XGID and the board diagram of a
+// position file disagree. Index follows XGIDToPosition: 0 is O's bar, 1-24 are
+// X's points and 25 is X's bar; counts are positive for X and negative for O.
+type PointMismatch struct {
+	Index int  `json:"index"`
+	XGID  int8 `json:"xgid"`  // Checkers decoded from the XGID
+	Board int8 `json:"board"` // Checkers drawn in the board diagram
+}
+
+// VerifyXGIDAgainstBoard reads an XG position text and compares the position
+// decoded from its XGID line with its ASCII board diagram. It returns the
+// indexes where they differ, none for a consistent file. An error is returned
+// when the XGID or the board diagram cannot be read.
+func VerifyXGIDAgainstBoard(r io.Reader) (mismatches []PointMismatch, err error) {
+	var lines []string
+	var positionID string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if positionID == "" && strings.HasPrefix(line, "XGID=") {
+			components, err := ParseXGID(line)
+			if err != nil {
+				return nil, err
+			}
+			positionID = components.PositionID
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(positionID) != 26 {
+		return nil, fmt.Errorf("no valid XGID found")
+	}
+
+	board, err := ParseASCIIBoard(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	xgid := XGIDToPosition(positionID)
+	for i := range xgid {
+		if xgid[i] != board.Checkers[i] {
+			mismatches = append(mismatches, PointMismatch{Index: i, XGID: xgid[i], Board: board.Checkers[i]})
+		}
+	}
+	return mismatches, nil
+}
+
+const (
+	boardTopHeader    = " +13-14-15-16-17-18------19-20-21-22-23-24-+"
+	boardBottomHeader = " +12-11-10--9--8--7-------6--5--4--3--2--1-+"
+	boardEmptyLine    = " |                  |   |                  |"
+	boardBarLine      = " |                  |BAR|                  |"
+	boardRows         = 5
+)
+
+// drawStack draws n checkers into the given rows, nearest to the edge first.
+// Stacks taller than the column show the total on the last row, right aligned
+// with the checker column.
+func drawStack(rows [][]byte, col, n int, symbol byte) {
+	for i := 0; i < n && i < len(rows); i++ {
+		rows[i][col] = symbol
+	}
+	if n > len(rows) {
+		total := strconv.Itoa(n)
+		copy(rows[len(rows)-1][col-len(total)+1:], total)
+	}
+}
+
+// RenderASCIIBoard draws a position as an XG ASCII board diagram.
+// The position is read from X's point of view, the same orientation returned
+// by ParseASCIIBoard: index 1-24 are the board points, X checkers are positive,
+// O checkers negative, X's bar is index 25 and O's bar index 0.
+func RenderASCIIBoard(pos Position) string {
+	topCols, bottomCols := boardColumns(boardTopHeader), boardColumns(boardBottomHeader)
+	barCol := (topCols[18] + topCols[19]) / 2
+
+	top := make([][]byte, boardRows)
+	bottom := make([][]byte, boardRows)
+	for i := range top {
+		top[i] = []byte(boardEmptyLine)
+		bottom[i] = []byte(boardEmptyLine)
+	}
+
+	// Bottom half stacks grow up from the lower edge
+	bottomUp := make([][]byte, boardRows)
+	for i := range bottom {
+		bottomUp[i] = bottom[boardRows-1-i]
+	}
+
+	for point := 1; point <= 24; point++ {
+		rows, col := bottomUp, bottomCols[point]
+		if point > 12 {
+			rows, col = top, topCols[point]
+		}
+		switch n := int(pos.Checkers[point]); {
+		case n > 0:
+			drawStack(rows, col, n, 'X')
+		case n < 0:
+			drawStack(rows, col, -n, 'O')
+		}
+	}
+
+	// Bar checkers are drawn next to the BAR line, X in the top half and O in the bottom half
+	topDown := make([][]byte, boardRows)
+	for i := range top {
+		topDown[i] = top[boardRows-1-i]
+	}
+	if n := int(pos.Checkers[25]); n > 0 {
+		drawStack(topDown, barCol, n, 'X')
+	}
+	if n := int(pos.Checkers[0]); n < 0 {
+		drawStack(bottom, barCol, -n, 'O')
+	}
+
+	var sb strings.Builder
+	sb.WriteString(boardTopHeader + "\n")
+	for _, row := range top {
+		sb.Write(row)
+		sb.WriteByte('\n')
+	}
+	sb.WriteString(boardBarLine + "\n")
+	for _, row := range bottom {
+		sb.Write(row)
+		sb.WriteByte('\n')
+	}
+	sb.WriteString(boardBottomHeader + "\n")
+	return sb.String()
+}
@@ -0,0 +1,125 @@
+//
+//   xgboard_test.go - Unit tests for XG ASCII board diagrams
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseASCIIBoard_Fixtures(t *testing.T) {
+	files, err := filepath.Glob("../test/2025-11-04/*.txt")
+	if err != nil || len(files) == 0 {
+		t.Skip("no fixtures found")
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+			components, err := ParseXGID(lines[0])
+			if err != nil {
+				t.Fatalf("ParseXGID() error = %v", err)
+			}
+
+			pos, err := ParseASCIIBoard(lines)
+			if err != nil {
+				t.Fatalf("ParseASCIIBoard() error = %v", err)
+			}
+			if want := xgidBoardPosition(components.PositionID); pos.Checkers != want {
+				t.Errorf("ParseASCIIBoard() = %v, want %v", pos.Checkers, want)
+			}
+		})
+	}
+}
+
+func TestVerifyXGIDAgainstBoard(t *testing.T) {
+	data, err := os.ReadFile("../test/2025-11-04/01_checkerPosition_EN.txt")
+	if err != nil {
+		t.Skip("fixture not found")
+	}
+
+	mismatches, err := VerifyXGIDAgainstBoard(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("VerifyXGIDAgainstBoard() error = %v", err)
+	}
+	if len(mismatches) != 0 {
+		t.Errorf("VerifyXGIDAgainstBoard() = %+v, want no mismatch", mismatches)
+	}
+
+	// A third X checker on the 6 point that the board does not show
+	tampered := strings.Replace(string(data), "XGID=-B-CBBB", "XGID=-B-CBBC", 1)
+	mismatches, err = VerifyXGIDAgainstBoard(strings.NewReader(tampered))
+	if err != nil {
+		t.Fatalf("VerifyXGIDAgainstBoard() error = %v", err)
+	}
+	want := []PointMismatch{{Index: 6, XGID: 3, Board: 2}}
+	if len(mismatches) != 1 || mismatches[0] != want[0] {
+		t.Errorf("VerifyXGIDAgainstBoard() = %+v, want %+v", mismatches, want)
+	}
+
+	if _, err := VerifyXGIDAgainstBoard(strings.NewReader("X to play 21\n")); err == nil {
+		t.Errorf("VerifyXGIDAgainstBoard() succeeded without an XGID")
+	}
+}
+
+func TestParseASCIIBoard_Bar(t *testing.T) {
+	board := []string{
+		" +13-14-15-16-17-18------19-20-21-22-23-24-+",
+		" |                  | X |                  |",
+		" |                  | X |                  |",
+		" |                  |BAR|                  |",
+		" |                  | O |                  |",
+		" |                  |   | X              O |",
+		" +12-11-10--9--8--7-------6--5--4--3--2--1-+",
+	}
+
+	pos, err := ParseASCIIBoard(board)
+	if err != nil {
+		t.Fatalf("ParseASCIIBoard() error = %v", err)
+	}
+	if pos.Checkers[25] != 2 || pos.Checkers[0] != -1 {
+		t.Errorf("bar = %d/%d, want 2/-1", pos.Checkers[25], pos.Checkers[0])
+	}
+	if pos.Checkers[6] != 1 || pos.Checkers[1] != -1 {
+		t.Errorf("points 6/1 = %d/%d, want 1/-1", pos.Checkers[6], pos.Checkers[1])
+	}
+}
+
+func TestParseASCIIBoard_NoBoard(t *testing.T) {
+	if _, err := ParseASCIIBoard([]string{"XGID=-b----E-C---eE---c-e----B-:0:0:1:52:0:0:0:13:10"}); err == nil {
+		t.Error("ParseASCIIBoard() succeeded without a board, want error")
+	}
+}
+
+func TestRenderASCIIBoard(t *testing.T) {
+	positions := []string{
+		"-B-CBBB---a---A---ABcbbbd-",
+		"----BaC-B---aD--aa-bcbbBbB",
+		"-b----E-C---eE---c-e----B-",
+		"b-----------------------aM",
+		"------G------------g------",
+	}
+
+	for _, id := range positions {
+		want := Position{Checkers: xgidBoardPosition(id)}
+		board := RenderASCIIBoard(want)
+
+		got, err := ParseASCIIBoard(strings.Split(board, "\n"))
+		if err != nil {
+			t.Fatalf("ParseASCIIBoard() error = %v\n%s", err, board)
+		}
+		if got.Checkers != want.Checkers {
+			t.Errorf("%s: round trip = %v, want %v\n%s", id, got.Checkers, want.Checkers, board)
+		}
+	}
+}
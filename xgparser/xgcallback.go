@@ -0,0 +1,185 @@
+//
+//   xgcallback.go - Streaming callback API for XG files
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Handler receives the content of an XG file from ParseXGCallback, in file
+// order. Returning an error from any method stops the parsing, and
+// ParseXGCallback returns that error.
+type Handler interface {
+	// OnMatch is called for each match header. The fields read from the
+	// match footer, such as FinalScore, are not known yet and left at zero.
+	OnMatch(metadata *MatchMetadata) error
+	// OnGame is called when a game starts. Only the fields known from the
+	// game header are set and Moves is empty.
+	OnGame(game *Game) error
+	// OnCheckerMove is called for each checker move of the current game
+	OnCheckerMove(move *CheckerMove, comment string) error
+	// OnCubeMove is called for each cube decision of the current game
+	OnCubeMove(move *CubeMove, comment string) error
+	// OnGameEnd is called once for each OnGame, with the game completed by its
+	// result. Moves is still empty: they were passed to the handler. A game
+	// without footer, ended by the next header or the file, has Winner 0.
+	OnGameEnd(game *Game) error
+	// OnMatchEnd is called once for each OnMatch, with the metadata of OnMatch
+	// completed by the last match footer and the match winner, 0 when the
+	// match has no footer.
+	OnMatchEnd(metadata *MatchMetadata, winner int32) error
+}
+
+// ParseXGCallback parses XG file segments as ParseXG, but passes the matches,
+// games and moves to h as they are read instead of building a Match. Records
+// are decoded one at a time and only the current game is kept in memory.
+// As in ParseXGMulti, a match header ends the game in progress; a game without
+// footer, as in xgp position files, ends with the file.
+// WithNameEncoding decodes the names of the match headers and WithTopN keeps
+// the n best candidates of each checker move passed to h; the other options
+// are rejected, see Option.
+func ParseXGCallback(segments []*Segment, h Handler, opts ...Option) error {
+	o, err := segmentOptions(opts)
+	if err != nil {
+		return err
+	}
+	if o.validate {
+		return fmt.Errorf("WithValidation needs a Match, use ParseXG")
+	}
+
+	productVersion := segmentsProductVersion(segments)
+	comments := segmentsComments(segments)
+	rollouts := segmentsRollouts(segments)
+	fileVersion := int32(-1)
+
+	var currentMatch *MatchMetadata
+	var currentGame *Game
+	winner := int32(0)    // Winner of the current match, from its last footer
+	moves := 0            // Moves of the current game
+	cubeOwner := int32(0) // Cube owner in the current game, see cubeOwnerAfter
+	endGame := func() error {
+		game := currentGame
+		currentGame = nil
+		if game == nil {
+			return nil
+		}
+		return h.OnGameEnd(game)
+	}
+	endMatch := func() error {
+		if err := endGame(); err != nil {
+			return err
+		}
+		metadata := currentMatch
+		currentMatch = nil
+		if metadata == nil {
+			return nil
+		}
+		return h.OnMatchEnd(metadata, winner)
+	}
+
+	for _, segment := range segments {
+		if segment.Type != SegmentXGGameFile {
+			continue
+		}
+
+		reader := bytes.NewReader(segment.Data)
+		for {
+			rec, err := nextGameFileRecord(reader, &fileVersion, true)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+
+			switch r := rec.(type) {
+			case *HeaderMatchEntry:
+				if err := endMatch(); err != nil {
+					return err
+				}
+
+				metadata := newMatchMetadata(r, o.nameEncoding)
+				metadata.ProductVersion = productVersion
+				metadata.HeaderComment = commentAt(comments, r.CommentHeaderMatch)
+				metadata.FooterComment = commentAt(comments, r.CommentFooterMatch)
+				currentMatch = &metadata
+				winner = 0
+				if err := h.OnMatch(currentMatch); err != nil {
+					return err
+				}
+
+			case *HeaderGameEntry:
+				if err := endGame(); err != nil {
+					return err
+				}
+				currentGame = newGame(r, comments)
+				moves = 0
+				cubeOwner = 0
+				if err := h.OnGame(currentGame); err != nil {
+					return err
+				}
+
+			case *CubeEntry:
+				// Initial position cube entries (Double == -2) are not cube decisions
+				if currentGame == nil || r.Double == -2 {
+					continue
+				}
+				currentGame.turnCube(r)
+				moves++
+				cubeMove := convertCubeEntry(r)
+				setCubeRollout(cubeMove, r, rollouts)
+				if !r.hasCubePos() {
+					cubeMove.Position.CubePos = cubeOwner * r.ActiveP // Seen from the player on roll
+				}
+				cubeOwner = cubeOwnerAfter(cubeOwner, r)
+				if err := h.OnCubeMove(cubeMove, commentAt(comments, r.CommentCube)); err != nil {
+					return err
+				}
+
+			case *MoveEntry:
+				if currentGame == nil {
+					continue
+				}
+				moves++
+				checkerMove := convertMoveEntry(r)
+				if !r.hasCubePos() {
+					checkerMove.Position.CubePos = cubeOwner * r.ActiveP
+				}
+				setMoveRollouts(checkerMove, r, rollouts)
+				if o.topN > 0 {
+					checkerMove.Analysis = topAnalysis(checkerMove.Analysis, o.topN)
+				}
+				if err := h.OnCheckerMove(checkerMove, commentAt(comments, r.CommentMove)); err != nil {
+					return err
+				}
+
+			case *FooterGameEntry:
+				if currentGame == nil {
+					continue
+				}
+				currentGame.setResult(r)
+				game := currentGame
+				currentGame = nil
+				if err := h.OnGameEnd(game); err != nil {
+					return err
+				}
+
+			case *FooterMatchEntry:
+				if currentMatch == nil {
+					continue
+				}
+				// As in ParseXG, the last footer wins
+				winner = r.WinnerM
+				currentMatch.FinalScore = [2]int32{r.Score1m, r.Score2m}
+				currentMatch.FinalElo = [2]float64{r.Elo1m, r.Elo2m}
+			}
+		}
+	}
+
+	return endMatch()
+}
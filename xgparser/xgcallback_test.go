@@ -0,0 +1,207 @@
+//
+//   xgcallback_test.go - Unit tests for the streaming callback API
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// recordingHandler rebuilds the games passed to a Handler and the sequence of calls
+type recordingHandler struct {
+	calls    []string
+	metadata []MatchMetadata // From OnMatch
+	ends     []MatchMetadata // From OnMatchEnd
+	winners  []int32
+	games    []Game
+	current  Game
+	stopAt   string // Call returning errStop
+}
+
+var errStop = errors.New("stop")
+
+func (h *recordingHandler) record(call string) error {
+	h.calls = append(h.calls, call)
+	if call == h.stopAt {
+		return errStop
+	}
+	return nil
+}
+
+func (h *recordingHandler) OnMatch(metadata *MatchMetadata) error {
+	h.metadata = append(h.metadata, *metadata)
+	return h.record("match")
+}
+
+func (h *recordingHandler) OnGame(game *Game) error {
+	h.current = *game
+	h.current.Moves = make([]Move, 0)
+	return h.record("game")
+}
+
+func (h *recordingHandler) OnCheckerMove(move *CheckerMove, comment string) error {
+	h.current.Moves = append(h.current.Moves, Move{MoveType: "checker", CheckerMove: move, Comment: comment})
+	return h.record("checker")
+}
+
+func (h *recordingHandler) OnCubeMove(move *CubeMove, comment string) error {
+	h.current.Moves = append(h.current.Moves, Move{MoveType: "cube", CubeMove: move, Comment: comment})
+	return h.record("cube")
+}
+
+func (h *recordingHandler) OnGameEnd(game *Game) error {
+	moves := h.current.Moves
+	h.current = *game
+	h.current.Moves = moves
+	h.games = append(h.games, h.current)
+	return h.record("end")
+}
+
+func (h *recordingHandler) OnMatchEnd(metadata *MatchMetadata, winner int32) error {
+	h.ends = append(h.ends, *metadata)
+	h.winners = append(h.winners, winner)
+	return h.record("matchend")
+}
+
+func TestParseXGCallback(t *testing.T) {
+	move := testMoveRecord(1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1})
+	putMoveCandidate(move, 0, [8]int8{8, 5, 6, 5, -1, -1, -1, -1}, [7]float32{0, 0, 0.5, 0, 0, 0, 0.1}, 2)
+	segments := testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		move,
+		testCubeRecord(-1, 1, 1, 1),
+		testGameFooter(1, 2),
+		testGameHeader(2, 0, 2),
+		testCubeRecord(1, 1, 0, 2),
+		testGameFooter(-1, 2),
+		testMatchFooter(2, 3, -1),
+	)
+
+	want, err := ParseXG(segments)
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+
+	h := &recordingHandler{}
+	if err := ParseXGCallback(segments, h); err != nil {
+		t.Fatalf("ParseXGCallback() error = %v", err)
+	}
+
+	wantCalls := []string{"match", "game", "checker", "cube", "end", "game", "cube", "end", "matchend"}
+	if !reflect.DeepEqual(h.calls, wantCalls) {
+		t.Errorf("calls = %v, want %v", h.calls, wantCalls)
+	}
+	// The match footer is passed to OnMatchEnd
+	if len(h.ends) != 1 || h.ends[0] != want.Metadata || h.winners[0] != want.Winner {
+		t.Errorf("match end = %+v %v, want %+v %v", h.ends, h.winners, want.Metadata, want.Winner)
+	}
+	want.Metadata.FinalScore = [2]int32{}
+	want.Metadata.FinalElo = [2]float64{}
+	if len(h.metadata) != 1 || h.metadata[0] != want.Metadata {
+		t.Errorf("metadata = %+v, want %+v", h.metadata, want.Metadata)
+	}
+	if !reflect.DeepEqual(h.games, want.Games) {
+		t.Errorf("games = %+v, want %+v", h.games, want.Games)
+	}
+
+	// An error from the handler stops the parsing
+	h = &recordingHandler{stopAt: "cube"}
+	if err := ParseXGCallback(segments, h); !errors.Is(err, errStop) {
+		t.Errorf("ParseXGCallback() error = %v, want %v", err, errStop)
+	}
+	if len(h.calls) != 4 {
+		t.Errorf("calls after the error = %v, want 4 calls", h.calls)
+	}
+}
+
+func TestParseXGCallback_Ends(t *testing.T) {
+	// Games without moves or footer, ended by the next game header, the next
+	// match header and the file, and matches with and without footer
+	segments := testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		testGameHeader(2, 0, 0),
+		testCubeRecord(1, 1, 0, 1),
+		testMatchFooter(0, 1, Player2),
+		testMatchHeader("Carol", "Dave", 3),
+		testGameHeader(1, 0, 0),
+	)
+
+	h := &recordingHandler{}
+	if err := ParseXGCallback(segments, h); err != nil {
+		t.Fatalf("ParseXGCallback() error = %v", err)
+	}
+
+	wantCalls := []string{"match", "game", "end", "game", "cube", "end", "matchend", "match", "game", "end", "matchend"}
+	if !reflect.DeepEqual(h.calls, wantCalls) {
+		t.Errorf("calls = %v, want %v", h.calls, wantCalls)
+	}
+	for i, game := range h.games {
+		if game.Winner != 0 {
+			t.Errorf("game %d winner = %d, want 0", i, game.Winner)
+		}
+	}
+	if wantWinners := []int32{Player2, 0}; !reflect.DeepEqual(h.winners, wantWinners) {
+		t.Errorf("match winners = %v, want %v", h.winners, wantWinners)
+	}
+	if len(h.ends) != 2 || h.ends[0].FinalScore != [2]int32{0, 1} || h.ends[1].FinalScore != [2]int32{} {
+		t.Errorf("match ends = %+v, want final scores 0-1 and none", h.ends)
+	}
+}
+
+func TestParseXGCallback_Streaming(t *testing.T) {
+	// The second match header has an unsupported version: the records before
+	// it reach the handler, which can stop before it is read
+	old := testMatchHeader("Carol", "Dave", 5)
+	putInt32(old, offHMVersion, MinSupportedVersion-1)
+	segments := testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		testCubeRecord(-1, 1, 0, 1),
+		testGameFooter(1, 1),
+		old,
+	)
+
+	h := &recordingHandler{}
+	if err := ParseXGCallback(segments, h); err == nil {
+		t.Errorf("ParseXGCallback() error = nil, want an unsupported version")
+	}
+	if wantCalls := []string{"match", "game", "cube", "end"}; !reflect.DeepEqual(h.calls, wantCalls) {
+		t.Errorf("calls = %v, want %v", h.calls, wantCalls)
+	}
+
+	h = &recordingHandler{stopAt: "end"}
+	if err := ParseXGCallback(segments, h); !errors.Is(err, errStop) {
+		t.Errorf("ParseXGCallback() error = %v, want %v", err, errStop)
+	}
+}
+
+func TestParseXGCallback_Options(t *testing.T) {
+	move := testMoveRecord(1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1})
+	putMoveCandidate(move, 0, [8]int8{8, 5, 6, 5, -1, -1, -1, -1}, [7]float32{0, 0, 0.5, 0, 0, 0, 0.1}, 2)
+	putMoveCandidate(move, 1, [8]int8{24, 21, 13, 12, -1, -1, -1, -1}, [7]float32{0, 0, 0.4, 0, 0, 0, 0}, 2)
+	segments := testGameFileSegments(
+		testMatchHeader("Ren\xe9", "J\xfcrgen", 5),
+		testGameHeader(1, 0, 0),
+		move,
+		testGameFooter(1, 1),
+	)
+
+	h := &recordingHandler{}
+	if err := ParseXGCallback(segments, h, WithNameEncoding(charmap.Windows1252), WithTopN(1)); err != nil {
+		t.Fatalf("ParseXGCallback() error = %v", err)
+	}
+	if len(h.metadata) != 1 || h.metadata[0].Player1Name != "René" || h.metadata[0].Player2Name != "Jürgen" {
+		t.Errorf("metadata = %+v, want René and Jürgen", h.metadata)
+	}
+	if len(h.games) != 1 || len(h.games[0].Moves) != 1 || len(h.games[0].Moves[0].CheckerMove.Analysis) != 1 {
+		t.Fatalf("games = %+v, want one move with one candidate", h.games)
+	}
+}
@@ -0,0 +1,95 @@
+//
+//   xgcsv.go - CSV export of move analysis
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// csvHeader lists the columns written by Match.ToCSV
+var csvHeader = []string{"game", "move", "player", "type", "dice", "played", "equity", "equity_loss", "win_pct", "classification"}
+
+// cubeActionNames are the names of CubeMove.CubeAction values
+var cubeActionNames = []string{"No double", "Double", "Take", "Pass", "Beaver", "Raccoon"}
+
+// formatFloat formats a number for the CSV columns
+func formatFloat(f float32, decimals int) string {
+	return strconv.FormatFloat(float64(f), 'f', decimals, 32)
+}
+
+// csvRow returns the CSV columns of a move.
+// Analysis columns are left empty when the decision was not analyzed.
+func (m *Match) csvRow(game *Game, index int, move *Move) []string {
+	var activePlayer int32
+	row := []string{strconv.Itoa(int(game.GameNumber)), strconv.Itoa(index + 1), "", move.MoveType, "", "", "", "", "", ""}
+
+	switch {
+	case move.CheckerMove != nil:
+		cm := move.CheckerMove
+		activePlayer = cm.ActivePlayer
+		row[4] = fmt.Sprintf("%d%d", cm.Dice[0], cm.Dice[1])
+
+		var played [8]int8
+		for i, p := range cm.PlayedMove {
+			played[i] = int8(p)
+		}
+		row[5] = FormatMove(played)
+
+		if a := cm.playedAnalysis(); a != nil {
+			loss, _ := cm.checkerEquityLoss()
+			row[6] = formatFloat(a.Equity, 3)
+			row[7] = formatFloat(loss, 3)
+			row[8] = formatFloat(a.Player1WinRate*100, 2)
+			row[9] = ClassifyEquityLoss(loss)
+		}
+	case move.CubeMove != nil:
+		cm := move.CubeMove
+		activePlayer = cm.ActivePlayer
+		if cm.CubeAction >= 0 && int(cm.CubeAction) < len(cubeActionNames) {
+			row[5] = cubeActionNames[cm.CubeAction]
+		}
+
+		if a := cm.Analysis; a != nil {
+			loss := cm.cubeEquityLoss()
+			row[6] = formatFloat(cm.cubeActionEquity(), 3)
+			row[7] = formatFloat(loss, 3)
+			row[8] = formatFloat(a.Player1WinRate*100, 2)
+			row[9] = ClassifyEquityLoss(loss)
+		}
+	}
+
+	row[2] = m.Metadata.Player1Name
+	if activePlayer == Player2 {
+		row[2] = m.Metadata.Player2Name
+	}
+	return row
+}
+
+// ToCSV writes one row per move with the game number, move number in the
+// game, player on roll, move type, dice, played move or cube action, equity
+// of the play, equity loss, winning chances in percent and the
+// ClassifyEquityLoss classification, after a header row.
+func (m *Match) ToCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for g := range m.Games {
+		game := &m.Games[g]
+		for i := range game.Moves {
+			if err := cw.Write(m.csvRow(game, i, &game.Moves[i])); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
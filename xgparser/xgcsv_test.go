@@ -0,0 +1,79 @@
+//
+//   xgcsv_test.go - Unit tests for the CSV export
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+)
+
+func TestToCSV(t *testing.T) {
+	var eval [7]float32
+
+	// Alice plays the best move
+	best := [8]int8{7, 4, 5, 4, -1, -1, -1, -1}
+	opening := testMoveRecord(1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1})
+	eval[2], eval[6] = 0.45, 0.2
+	putMoveCandidate(opening, 0, best, eval, 3)
+	eval[6] = 0.1
+	putMoveCandidate(opening, 1, [8]int8{23, 20, 23, 22, -1, -1, -1, -1}, eval, 3)
+
+	// Bob blunders
+	reply := testMoveRecord(-1, [2]int32{6, 5}, [8]int32{23, 17, 17, 12, -1, -1, -1, -1})
+	eval[6] = 0.05
+	putMoveCandidate(reply, 0, [8]int8{12, 6, 12, 7, -1, -1, -1, -1}, eval, 3)
+	eval[6] = -0.05
+	putMoveCandidate(reply, 1, [8]int8{23, 17, 17, 12, -1, -1, -1, -1}, eval, 3)
+
+	// Alice misses a double
+	cube := testCubeRecord(1, 0, 0, 1)
+	putFloat32(cube, offCEDEquB, 0.5)
+	putFloat32(cube, offCEDEquDouble, 0.7)
+	putFloat32(cube, offCEDEquDrop, 1.0)
+	putFloat32(cube, offCEDEval+8, 0.3) // Opponent's winning chances
+
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		opening,
+		reply,
+		cube,
+		testGameFooter(1, 1),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := match.ToCSV(&buf); err != nil {
+		t.Fatalf("ToCSV() error = %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+
+	wantHeader := []string{"game", "move", "player", "type", "dice", "played", "equity", "equity_loss", "win_pct", "classification"}
+	if !reflect.DeepEqual(rows[0], wantHeader) {
+		t.Errorf("header = %v, want %v", rows[0], wantHeader)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("row count = %d, want 4", len(rows))
+	}
+
+	want := [][]string{
+		{"1", "1", "Alice", "checker", "31", "8/5 6/5", "0.200", "0.000", "55.00", ""},
+		{"1", "2", "Bob", "checker", "65", "24/18 18/13", "-0.050", "0.100", "55.00", "blunder"},
+		{"1", "3", "Alice", "cube", "", "No double", "0.500", "0.200", "70.00", "blunder"},
+	}
+	for i, w := range want {
+		if !reflect.DeepEqual(rows[i+1], w) {
+			t.Errorf("row %d = %v, want %v", i+1, rows[i+1], w)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+//
+//   xgcube.go - Cube decision helpers
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+// MarketWindow returns the range of winning chances for the player on roll in
+// which doubling is correct: lower is the doubling point, where Double/Take
+// becomes worth more than No Double, and upper is the too-good point, where
+// No Double becomes worth more than cashing with Double/Pass. It is 0, 0 when
+// the decision was not analyzed.
+//
+// The bounds are estimated from the cubeful equities around the current
+// winning chances with a linear model: each point of winning chances is worth
+// 2 points of equity with the cube at its current level and 4 once doubled,
+// while Double/Pass stays constant. With met nil or for a match length of 0
+// this is done in money equity; in a match in match winning chances at the
+// score, as in CorrectAction.
+func (c *CubeMove) MarketWindow(met *MET) (lower, upper float32) {
+	a := c.Analysis
+	if a == nil {
+		return 0, 0
+	}
+	noDouble, doubleTake, doublePass := a.CubefulNoDouble, a.CubefulDoubleTake, a.CubefulDoublePass
+	var slopeNoDouble, slopeDoubled float32 = 2, 4
+	if stakes, ok := c.cubeStakes(met); ok {
+		noDouble, doubleTake, doublePass = stakes.mwc(noDouble, 1), stakes.mwc(doubleTake, 2), stakes.win[1]
+		slopeNoDouble, slopeDoubled = stakes.win[1]-stakes.lose[1], stakes.win[2]-stakes.lose[2]
+	}
+
+	if slopeDoubled > slopeNoDouble {
+		lower = a.Player1WinRate + (noDouble-doubleTake)/(slopeDoubled-slopeNoDouble)
+	} else if doubleTake < noDouble {
+		lower = 1
+	}
+	upper = 1
+	if slopeNoDouble > 0 {
+		upper = a.Player1WinRate + (doublePass-noDouble)/slopeNoDouble
+	}
+	return clampProbability(lower), clampProbability(upper)
+}
+
+// clampProbability limits p to the range [0, 1]
+func clampProbability(p float32) float32 {
+	if p < 0 {
+		return 0
+	}
+	if p > 1 {
+		return 1
+	}
+	return p
+}
+
+// RecubeValue estimates the value of cube ownership for the taker after a
+// double, for the doubler. It is the part of the cubeless Double/Take equity
+// the doubler loses because the taker can redouble, so it is close to 0 when
+// the cube is dead (e.g. a last-roll race). With met nil or for a match length
+// of 0 it is in money equity, in a match in match winning chances at the score.
+func (c *CubeMove) RecubeValue(met *MET) float32 {
+	a := c.Analysis
+	if a == nil {
+		return 0
+	}
+	if stakes, ok := c.cubeStakes(met); ok {
+		return stakes.mwc(a.CubelessDouble, 2) - stakes.mwc(a.CubefulDoubleTake, 2)
+	}
+	return a.CubelessDouble - a.CubefulDoubleTake
+}
+
+// bestCubeAction returns the correct cube action for the player on roll and
+// its cubeful equity: CubeNoDouble, CubeTake for double/take or CubePass for
+// double/pass.
+func (c *CubeAnalysis) bestCubeAction() (action CubeAction, equity float32) {
+	return bestCubeAction(c.CubefulNoDouble, c.CubefulDoubleTake, c.CubefulDoublePass)
+}
+
+// bestCubeAction returns the correct cube action from the values of no double,
+// double/take and double/pass for the player on roll, and the value of that
+// action
+func bestCubeAction(noDouble, doubleTake, doublePass float32) (action CubeAction, value float32) {
+	// The opponent picks the response that is worse for the doubler
+	action, value = CubeTake, doubleTake
+	if doublePass < value {
+		action, value = CubePass, doublePass
+	}
+	if noDouble >= value {
+		return CubeNoDouble, noDouble
+	}
+	return action, value
+}
+
+// CorrectAction returns the correct cube action for the player on roll:
+// CubeNoDouble, CubeTake for double/take or CubePass for double/pass. It is
+// CubeNoDouble when the decision was not analyzed.
+//
+// With met nil the cubeful equities are compared as in a money game. With a
+// match equity table they are read as money equities at the cube value of
+// each action and converted to match winning chances at the score of the
+// position: no double plays for the cube, double/take for twice the cube and
+// double/pass wins the cube.
+func (c *CubeMove) CorrectAction(met *MET) CubeAction {
+	if c.Analysis == nil {
+		return CubeNoDouble
+	}
+	a := c.Analysis
+	stakes, ok := c.cubeStakes(met)
+	if !ok {
+		action, _ := a.bestCubeAction()
+		return action
+	}
+	action, _ := bestCubeAction(stakes.mwc(a.CubefulNoDouble, 1), stakes.mwc(a.CubefulDoubleTake, 2), stakes.win[1])
+	return action
+}
+
+// cubeStakes holds the match winning chances of the player on roll after
+// winning or losing a single game for 1 and 2 times the cube value
+type cubeStakes struct {
+	win, lose [3]float32
+}
+
+// cubeStakes looks up the stakes of a cube decision in met at the score of
+// the position. ok is false for money games: met nil or for a match length of 0.
+func (c *CubeMove) cubeStakes(met *MET) (s cubeStakes, ok bool) {
+	if met == nil || met.MatchLength == 0 {
+		return s, false
+	}
+	away := met.MatchLength - c.Position.Score[0]
+	oppAway := met.MatchLength - c.Position.Score[1]
+	cube := c.Position.Cube
+	if cube < 1 {
+		cube = 1
+	}
+	// Doubling is not allowed in the Crawford game, so a player 1 away means
+	// the decision is post-Crawford
+	postCrawford := away == 1 || oppAway == 1
+	for k := int32(1); k <= 2; k++ {
+		s.win[k] = met.Equity(away-k*cube, oppAway, postCrawford)
+		s.lose[k] = met.Equity(away, oppAway-k*cube, postCrawford)
+	}
+	return s, true
+}
+
+// mwc converts a money equity for k times the cube value, between -k and k, to
+// match winning chances between losing and winning a single game
+func (s cubeStakes) mwc(equity float32, k int) float32 {
+	return s.lose[k] + (equity/float32(k)+1)/2*(s.win[k]-s.lose[k])
+}
+
+// MET is a match equity table: the chances of winning a match by the points
+// each player still needs to win it
+type MET struct {
+	MatchLength  int32       // Length of the match, 0 for money games
+	PreCrawford  [][]float32 // PreCrawford[i][j]: player i+1 away, opponent j+1 away, up to the Crawford game
+	PostCrawford []float32   // PostCrawford[i]: player i+1 away, opponent 1 away after the Crawford game
+}
+
+// NewMET computes a match equity table for a match of matchLength points from
+// gammonRate, the share of games won with a gammon. Games are played for the
+// cube value without doubling before the Crawford game, and for twice it
+// after, when the trailer doubles at once. Tables such as XG's Kazaross can be
+// used instead by filling a MET. A matchLength of 0 gives the empty table of a
+// money game.
+func NewMET(matchLength int32, gammonRate float32) *MET {
+	n := int(matchLength)
+	g := gammonRate
+	met := &MET{MatchLength: matchLength, PreCrawford: make([][]float32, n), PostCrawford: make([]float32, n)}
+
+	post := func(away int) float32 {
+		if away <= 0 {
+			return 1
+		}
+		return met.PostCrawford[away-1]
+	}
+	for i := 1; i <= n; i++ {
+		met.PostCrawford[i-1] = 0.5
+		if i > 1 {
+			met.PostCrawford[i-1] = 0.5 * ((1-g)*post(i-2) + g*post(i-4))
+		}
+	}
+
+	for i := range met.PreCrawford {
+		met.PreCrawford[i] = make([]float32, n)
+	}
+	pre := func(away, oppAway int) float32 {
+		switch {
+		case away <= 0:
+			return 1
+		case oppAway <= 0:
+			return 0
+		}
+		return met.PreCrawford[away-1][oppAway-1]
+	}
+	for sum := 2; sum <= 2*n; sum++ {
+		for i := 1; i <= n && i < sum; i++ {
+			j := sum - i
+			if j > n {
+				continue
+			}
+			var e float32
+			switch {
+			case i == 1 && j == 1:
+				e = 0.5
+			case i == 1:
+				// Crawford game: the trailer needs j-1 or j-2 points after it
+				e = 1 - 0.5*((1-g)*post(j-1)+g*post(j-2))
+			case j == 1:
+				e = 0.5 * ((1-g)*post(i-1) + g*post(i-2))
+			default:
+				e = 0.5*(1-g)*(pre(i-1, j)+pre(i, j-1)) + 0.5*g*(pre(i-2, j)+pre(i, j-2))
+			}
+			met.PreCrawford[i-1][j-1] = e
+		}
+	}
+	return met
+}
+
+// Equity returns the match winning chances of a player away points from
+// winning the match against an opponent oppAway points away. postCrawford
+// selects the post-Crawford equities when one player is 1 away. Scores beyond
+// the table are read at its edge.
+func (m *MET) Equity(away, oppAway int32, postCrawford bool) float32 {
+	switch {
+	case away <= 0:
+		return 1
+	case oppAway <= 0:
+		return 0
+	}
+	n := int32(len(m.PreCrawford))
+	away, oppAway = min(away, n), min(oppAway, n)
+	if postCrawford && oppAway == 1 && away > 1 {
+		return m.PostCrawford[away-1]
+	}
+	if postCrawford && away == 1 && oppAway > 1 {
+		return 1 - m.PostCrawford[oppAway-1]
+	}
+	return m.PreCrawford[away-1][oppAway-1]
+}
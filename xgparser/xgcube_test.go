@@ -0,0 +1,231 @@
+//
+//   xgcube_test.go - Unit tests for cube decision helpers
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"testing"
+)
+
+func TestMarketWindow(t *testing.T) {
+	tests := []struct {
+		file     string
+		inWindow bool
+	}{
+		{"../test/2025-11-04/03_DT_EN.txt", true},   // Double / Take
+		{"../test/2025-11-04/02_NDT_EN.txt", false}, // No double / Take
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			cubeMove, _, err := ParseXGIDCubeFile(tt.file)
+			if err != nil {
+				t.Fatalf("ParseXGIDCubeFile() error = %v", err)
+			}
+
+			a := cubeMove.Analysis
+			lower, upper := cubeMove.MarketWindow(nil)
+			if lower >= upper {
+				t.Errorf("MarketWindow() = %v, %v, want lower < upper", lower, upper)
+			}
+			if got := lower <= a.Player1WinRate && a.Player1WinRate <= upper; got != tt.inWindow {
+				t.Errorf("win rate %v in [%v, %v] = %v, want %v", a.Player1WinRate, lower, upper, got, tt.inWindow)
+			}
+		})
+	}
+}
+
+func TestMarketWindow_Clamped(t *testing.T) {
+	move := &CubeMove{Analysis: &CubeAnalysis{Player1WinRate: 0.95, CubefulNoDouble: 0.2, CubefulDoubleTake: 0.4, CubefulDoublePass: 1.0}}
+	if _, upper := move.MarketWindow(nil); upper != 1 {
+		t.Errorf("upper = %v, want 1", upper)
+	}
+}
+
+func TestMarketWindow_Match(t *testing.T) {
+	// Below the money doubling point, but the trailer 4-away post-Crawford
+	// against 1-away doubles at once
+	move := &CubeMove{
+		Position: Position{Cube: 1, Score: [2]int32{1, 4}},
+		Analysis: &CubeAnalysis{Player1WinRate: 0.4, CubefulNoDouble: 0.5, CubefulDoubleTake: 0.45, CubefulDoublePass: 1},
+	}
+
+	inWindow := func(lower, upper float32) bool { return lower <= 0.4 && 0.4 <= upper }
+	if lower, upper := move.MarketWindow(nil); inWindow(lower, upper) {
+		t.Errorf("money MarketWindow() = %v, %v, want 0.4 outside", lower, upper)
+	}
+	if lower, upper := move.MarketWindow(NewMET(0, 0.25)); inWindow(lower, upper) {
+		t.Errorf("MarketWindow() with a money table = %v, %v, want 0.4 outside", lower, upper)
+	}
+	if lower, upper := move.MarketWindow(NewMET(5, 0.25)); !inWindow(lower, upper) {
+		t.Errorf("4-away 1-away MarketWindow() = %v, %v, want 0.4 inside", lower, upper)
+	}
+}
+
+func TestRecubeValue(t *testing.T) {
+	cubeMove, _, err := ParseXGIDCubeFile("../test/2025-11-04/03_DT_EN.txt")
+	if err != nil {
+		t.Fatalf("ParseXGIDCubeFile() error = %v", err)
+	}
+	// Middle game: cubeless Double=+0.791, Double/Take=+0.689
+	money := cubeMove.RecubeValue(nil)
+	if money < 0.1 || money > 0.104 {
+		t.Errorf("RecubeValue() = %v, want ~0.102", money)
+	}
+	// At 7-away 5-away the value is in match winning chances
+	if got := cubeMove.RecubeValue(NewMET(9, 0.25)); got <= 0 || got >= money/4 {
+		t.Errorf("7-away 5-away RecubeValue() = %v, want between 0 and %v", got, money/4)
+	}
+
+	// Last roll race: the taker never gets to use the cube
+	lastRoll := &CubeMove{Analysis: &CubeAnalysis{
+		Player1WinRate:    0.75,
+		CubelessNoDouble:  0.5,
+		CubelessDouble:    1.0,
+		CubefulNoDouble:   0.5,
+		CubefulDoubleTake: 1.0,
+		CubefulDoublePass: 1.0,
+	}}
+	if got := lastRoll.RecubeValue(nil); got != 0 {
+		t.Errorf("last roll RecubeValue() = %v, want 0", got)
+	}
+}
+
+func TestCubeAnalysisTooGood(t *testing.T) {
+	// Gammonish position: playing on is worth more than cashing
+	tooGood := testCubeRecord(1, 0, 0, 1)
+	putFloat32(tooGood, offCEDEquB, 1.35)
+	putFloat32(tooGood, offCEDEquDouble, 1.9)
+	putFloat32(tooGood, offCEDEquDrop, 1.0)
+	double := testCubeRecord(1, 1, 0, 1)
+	putFloat32(double, offCEDEquB, 0.8)
+	putFloat32(double, offCEDEquDouble, 1.2)
+	putFloat32(double, offCEDEquDrop, 1.0)
+
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		tooGood,
+		double,
+		testGameFooter(-1, 1),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	moves := match.Games[0].Moves
+	if !moves[0].CubeMove.Analysis.TooGood {
+		t.Errorf("TooGood = false with No Double %v above Double/Pass %v", 1.35, 1.0)
+	}
+	if moves[1].CubeMove.Analysis.TooGood {
+		t.Errorf("TooGood = true for a double/pass")
+	}
+
+	cubeMove, _, err := ParseXGIDCubeFile("../test/2025-11-04/04_DP_EN.txt")
+	if err != nil {
+		t.Fatalf("ParseXGIDCubeFile() error = %v", err)
+	}
+	if cubeMove.Analysis.TooGood {
+		t.Errorf("TooGood = true for the double/pass fixture")
+	}
+}
+
+func TestCubeMoveCorrectAction(t *testing.T) {
+	tests := []struct {
+		file string
+		want CubeAction
+	}{
+		{"../test/2025-11-04/02_NDT_EN.txt", CubeNoDouble},
+		{"../test/2025-11-04/03_DT_EN.txt", CubeTake}, // 7-away 5-away, in the double window
+		{"../test/2025-11-04/04_DP_EN.txt", CubePass},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			cubeMove, _, err := ParseXGIDCubeFile(tt.file)
+			if err != nil {
+				t.Fatalf("ParseXGIDCubeFile() error = %v", err)
+			}
+			if got := cubeMove.CorrectAction(nil); got != tt.want {
+				t.Errorf("CorrectAction(nil) = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// A recorded double from a binary CubeEntry, in the double window
+	cube := testCubeRecord(1, 1, 1, 1)
+	putFloat32(cube, offCEDEquB, 0.58)
+	putFloat32(cube, offCEDEquDouble, 0.689)
+	putFloat32(cube, offCEDEquDrop, 1.0)
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 9),
+		testGameHeader(1, 2, 4),
+		cube,
+		testGameFooter(1, 1),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	move := match.Games[0].Moves[0].CubeMove
+	if got := move.CorrectAction(nil); got != CubeTake {
+		t.Errorf("CorrectAction(nil) = %d, want %d", got, CubeTake)
+	}
+
+	if got := (&CubeMove{}).CorrectAction(nil); got != CubeNoDouble {
+		t.Errorf("CorrectAction() without analysis = %d, want %d", got, CubeNoDouble)
+	}
+}
+
+func TestCubeMoveCorrectAction_MET(t *testing.T) {
+	// Not worth a double for money, but the trailer 4-away post-Crawford
+	// against 1-away loses the match with any loss, so it doubles at once
+	move := &CubeMove{
+		Position: Position{Cube: 1, Score: [2]int32{1, 4}},
+		Analysis: &CubeAnalysis{CubefulNoDouble: 0.5, CubefulDoubleTake: 0.45, CubefulDoublePass: 1},
+	}
+	met := NewMET(5, 0.25)
+
+	if got := move.CorrectAction(nil); got != CubeNoDouble {
+		t.Errorf("money CorrectAction() = %d, want %d", got, CubeNoDouble)
+	}
+	if got := move.CorrectAction(NewMET(0, 0.25)); got != CubeNoDouble {
+		t.Errorf("CorrectAction() with a money table = %d, want %d", got, CubeNoDouble)
+	}
+	if got := move.CorrectAction(met); got != CubeTake {
+		t.Errorf("4-away 1-away CorrectAction() = %d, want %d", got, CubeTake)
+	}
+
+	// Double/Take at 7-away 5-away stays a double/take at the score
+	cubeMove, _, err := ParseXGIDCubeFile("../test/2025-11-04/03_DT_EN.txt")
+	if err != nil {
+		t.Fatalf("ParseXGIDCubeFile() error = %v", err)
+	}
+	if got := cubeMove.CorrectAction(NewMET(9, 0.25)); got != CubeTake {
+		t.Errorf("7-away 5-away CorrectAction() = %d, want %d", got, CubeTake)
+	}
+}
+
+func TestNewMET(t *testing.T) {
+	met := NewMET(7, 0.25)
+	if got := met.Equity(1, 1, false); got != 0.5 {
+		t.Errorf("Equity(1, 1) = %v, want 0.5", got)
+	}
+	for i := int32(1); i <= 7; i++ {
+		for j := int32(1); j <= 7; j++ {
+			if sum := met.Equity(i, j, false) + met.Equity(j, i, false); sum < 0.9999 || sum > 1.0001 {
+				t.Errorf("Equity(%d, %d) + Equity(%d, %d) = %v, want 1", i, j, j, i, sum)
+			}
+			if j > 1 && met.Equity(i, j, false) < met.Equity(i, j-1, false) {
+				t.Errorf("Equity(%d, %d) below Equity(%d, %d)", i, j, i, j-1)
+			}
+		}
+	}
+	// The trailer 2-away post-Crawford wins the match with the next game
+	if got := met.Equity(2, 1, true); got != 0.5 {
+		t.Errorf("post-Crawford Equity(2, 1) = %v, want 0.5", got)
+	}
+	if got := met.Equity(0, 3, false); got != 1 {
+		t.Errorf("Equity(0, 3) = %v, want 1", got)
+	}
+}
@@ -0,0 +1,83 @@
+//
+//   xgdiff.go - Compare two analyses of the same match
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import "fmt"
+
+// MoveDiff reports the analysis of one decision in two versions of a match
+type MoveDiff struct {
+	Game        int     `json:"game"`          // Game index in the match (0-based)
+	Move        int     `json:"move"`          // Move index in the game (0-based)
+	MoveType    string  `json:"move_type"`     // "checker" or "cube"
+	EquityA     float32 `json:"equity_a"`      // Equity of the best play in the first match
+	EquityB     float32 `json:"equity_b"`      // Equity of the best play in the second match
+	BestMoveA   [8]int8 `json:"best_move_a"`   // Best checker play in the first match (checker only)
+	BestMoveB   [8]int8 `json:"best_move_b"`   // Best checker play in the second match (checker only)
+	BestActionA int32   `json:"best_action_a"` // Best cube action in the first match (cube only, 0=no double, 2=take, 3=pass)
+	BestActionB int32   `json:"best_action_b"` // Best cube action in the second match (cube only)
+	BestChanged bool    `json:"best_changed"`  // The best play or cube action differs
+}
+
+// bestCheckerAnalysis returns the candidate with the highest equity
+func bestCheckerAnalysis(analysis []CheckerAnalysis) *CheckerAnalysis {
+	var best *CheckerAnalysis
+	for i := range analysis {
+		if best == nil || analysis[i].Equity > best.Equity {
+			best = &analysis[i]
+		}
+	}
+	return best
+}
+
+// DiffMatches compares two analyses of the same match, e.g. before and after
+// re-analyzing at a higher ply. Games and moves are aligned by index, and one
+// MoveDiff is returned for every decision analyzed in both matches.
+// The matches must have the same games and moves.
+func DiffMatches(a, b *Match) ([]MoveDiff, error) {
+	if len(a.Games) != len(b.Games) {
+		return nil, fmt.Errorf("game count differs: %d and %d", len(a.Games), len(b.Games))
+	}
+
+	var diffs []MoveDiff
+	for g := range a.Games {
+		movesA, movesB := a.Games[g].Moves, b.Games[g].Moves
+		if len(movesA) != len(movesB) {
+			return nil, fmt.Errorf("game %d: move count differs: %d and %d", g+1, len(movesA), len(movesB))
+		}
+
+		for m := range movesA {
+			moveA, moveB := &movesA[m], &movesB[m]
+			if moveA.MoveType != moveB.MoveType {
+				return nil, fmt.Errorf("game %d, move %d: move type differs: %s and %s", g+1, m+1, moveA.MoveType, moveB.MoveType)
+			}
+
+			diff := MoveDiff{Game: g, Move: m, MoveType: moveA.MoveType}
+			switch {
+			case moveA.CheckerMove != nil && moveB.CheckerMove != nil:
+				bestA := bestCheckerAnalysis(moveA.CheckerMove.Analysis)
+				bestB := bestCheckerAnalysis(moveB.CheckerMove.Analysis)
+				if bestA == nil || bestB == nil {
+					continue
+				}
+				diff.EquityA, diff.BestMoveA = bestA.Equity, bestA.Move
+				diff.EquityB, diff.BestMoveB = bestB.Equity, bestB.Move
+				diff.BestChanged = bestA.Move != bestB.Move
+			case moveA.CubeMove != nil && moveB.CubeMove != nil:
+				if moveA.CubeMove.Analysis == nil || moveB.CubeMove.Analysis == nil {
+					continue
+				}
+				diff.BestActionA, diff.EquityA = moveA.CubeMove.Analysis.bestCubeAction()
+				diff.BestActionB, diff.EquityB = moveB.CubeMove.Analysis.bestCubeAction()
+				diff.BestChanged = diff.BestActionA != diff.BestActionB
+			default:
+				continue
+			}
+			diffs = append(diffs, diff)
+		}
+	}
+
+	return diffs, nil
+}
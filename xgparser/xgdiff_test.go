@@ -0,0 +1,85 @@
+//
+//   xgdiff_test.go - Unit tests for match analysis comparison
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import "testing"
+
+// testDiffMatch builds a one game match with two checker moves and a cube decision
+func testDiffMatch(secondMove []CheckerAnalysis, cube CubeAnalysis) *Match {
+	first := []CheckerAnalysis{
+		{Move: [8]int8{13, 10, 10, 9, -1, -1, -1, -1}, Equity: 0.05},
+		{Move: [8]int8{24, 23, 13, 10, -1, -1, -1, -1}, Equity: 0.01},
+	}
+	return &Match{Games: []Game{{
+		Moves: []Move{
+			{MoveType: "checker", CheckerMove: &CheckerMove{Analysis: first}},
+			{MoveType: "checker", CheckerMove: &CheckerMove{Analysis: secondMove}},
+			{MoveType: "cube", CubeMove: &CubeMove{Analysis: &cube}},
+			{MoveType: "checker", CheckerMove: &CheckerMove{}}, // not analyzed
+		},
+	}}}
+}
+
+func TestDiffMatches(t *testing.T) {
+	pointMove := [8]int8{8, 5, 6, 5, -1, -1, -1, -1}
+	runMove := [8]int8{24, 18, -1, -1, -1, -1, -1, -1}
+	cube := CubeAnalysis{CubefulNoDouble: 0.40, CubefulDoubleTake: 0.35, CubefulDoublePass: 1}
+
+	shallow := testDiffMatch([]CheckerAnalysis{
+		{Move: pointMove, Equity: 0.12},
+		{Move: runMove, Equity: 0.10},
+	}, cube)
+	deep := testDiffMatch([]CheckerAnalysis{
+		{Move: pointMove, Equity: 0.08},
+		{Move: runMove, Equity: 0.11},
+	}, cube)
+
+	diffs, err := DiffMatches(shallow, deep)
+	if err != nil {
+		t.Fatalf("DiffMatches() error = %v", err)
+	}
+	if len(diffs) != 3 {
+		t.Fatalf("len(diffs) = %d, want 3", len(diffs))
+	}
+
+	if d := diffs[0]; d.BestChanged || d.EquityA != d.EquityB {
+		t.Errorf("unchanged move reported as changed: %+v", d)
+	}
+	if d := diffs[1]; !d.BestChanged || d.Move != 1 || d.BestMoveA != pointMove || d.BestMoveB != runMove ||
+		d.EquityA != 0.12 || d.EquityB != 0.11 {
+		t.Errorf("changed move diff = %+v", d)
+	}
+	if d := diffs[2]; d.MoveType != "cube" || d.BestChanged || d.BestActionA != 0 || d.EquityA != 0.40 {
+		t.Errorf("cube diff = %+v", d)
+	}
+}
+
+func TestDiffMatches_CubeAction(t *testing.T) {
+	moves := []CheckerAnalysis{{Move: [8]int8{8, 5, 6, 5, -1, -1, -1, -1}, Equity: 0.1}}
+	noDouble := testDiffMatch(moves, CubeAnalysis{CubefulNoDouble: 0.60, CubefulDoubleTake: 0.55, CubefulDoublePass: 1})
+	doublePass := testDiffMatch(moves, CubeAnalysis{CubefulNoDouble: 0.80, CubefulDoubleTake: 1.2, CubefulDoublePass: 1})
+
+	diffs, err := DiffMatches(noDouble, doublePass)
+	if err != nil {
+		t.Fatalf("DiffMatches() error = %v", err)
+	}
+	if d := diffs[2]; !d.BestChanged || d.BestActionA != 0 || d.BestActionB != 3 || d.EquityB != 1 {
+		t.Errorf("cube diff = %+v", d)
+	}
+}
+
+func TestDiffMatches_Mismatch(t *testing.T) {
+	a := testDiffMatch(nil, CubeAnalysis{})
+	b := testDiffMatch(nil, CubeAnalysis{})
+	b.Games[0].Moves = b.Games[0].Moves[:2]
+
+	if _, err := DiffMatches(a, b); err == nil {
+		t.Errorf("DiffMatches() with different move counts returned no error")
+	}
+	if _, err := DiffMatches(a, &Match{}); err == nil {
+		t.Errorf("DiffMatches() with different game counts returned no error")
+	}
+}
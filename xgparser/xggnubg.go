@@ -0,0 +1,83 @@
+//
+//   xggnubg.go - GNU Backgammon position and match IDs
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"encoding/base64"
+	"math/bits"
+)
+
+// gnubgKey packs values into a GNU Backgammon key: bits are numbered from the
+// least significant bit of the first byte
+type gnubgKey []byte
+
+// put writes the n low bits of v at bit offset
+func (k gnubgKey) put(offset, n int, v uint32) {
+	for i := 0; i < n; i++ {
+		if v&(1<<i) != 0 {
+			k[(offset+i)/8] |= 1 << ((offset + i) % 8)
+		}
+	}
+}
+
+// GnuBgPositionID returns the 14-character GNU Backgammon position ID of the
+// position, seen from the player on roll as positions are stored.
+// Each side is written in turn, the opponent first: for its 24 points from its
+// ace point and then its bar, one 1 bit per checker followed by a 0 bit.
+func (p Position) GnuBgPositionID() string {
+	onRoll, opponent := p.sideCheckers()
+	key := make(gnubgKey, 10)
+	bit := 0
+	for _, side := range [2][26]int{opponent, onRoll} {
+		for _, n := range side[1:] {
+			if n > 15 {
+				n = 15
+			}
+			key.put(bit, n, 1<<n-1)
+			bit += n + 1
+		}
+	}
+	return base64.RawStdEncoding.EncodeToString(key)
+}
+
+// GnuBgMatchID returns the 12-character GNU Backgammon match ID of a game in
+// progress at this position. Player 1 is GNU Backgammon's player 0 and
+// player 2 its player 1; activePlayer is used as in PipCount and the cube
+// owner and score are read from the position. dice are the dice rolled,
+// {0, 0} before rolling, and matchLength is 0 for money games.
+func (p Position) GnuBgMatchID(activePlayer int32, dice [2]int32, cube int32, matchLength int32, crawford bool) string {
+	abs := p.Absolute(activePlayer)
+	key := make(gnubgKey, 9)
+
+	if cube > 1 {
+		key.put(0, 4, uint32(bits.Len32(uint32(cube))-1))
+	}
+	switch abs.CubePos {
+	case Player1:
+		key.put(4, 2, 0)
+	case Player2:
+		key.put(4, 2, 1)
+	default:
+		key.put(4, 2, 3) // Centered
+	}
+	onRoll := uint32(0)
+	if activePlayer == Player2 {
+		onRoll = 1
+	}
+	key.put(6, 1, onRoll)
+	if crawford {
+		key.put(7, 1, 1)
+	}
+	key.put(8, 3, 1) // Game being played
+	key.put(11, 1, onRoll)
+	key.put(15, 3, uint32(dice[0]))
+	key.put(18, 3, uint32(dice[1]))
+	key.put(21, 15, uint32(matchLength))
+	key.put(36, 15, uint32(abs.Score[0]))
+	key.put(51, 15, uint32(abs.Score[1]))
+
+	return base64.StdEncoding.EncodeToString(key)
+}
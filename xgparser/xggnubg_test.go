@@ -0,0 +1,41 @@
+//
+//   xggnubg_test.go - Unit tests for GNU Backgammon position and match IDs
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import "testing"
+
+func TestGnuBgPositionID(t *testing.T) {
+	if got := StartingPosition().GnuBgPositionID(); got != "4HPwATDgc/ABMA" {
+		t.Errorf("GnuBgPositionID() of the opening = %s, want 4HPwATDgc/ABMA", got)
+	}
+
+	// Player on roll with 15 checkers on its ace point, opponent with 15 on its
+	// 6 point, written first: 00000 1x15 0 0x19, then 1x15 0 0x24
+	var pos Position
+	pos.Checkers[1] = 15
+	pos.Checkers[19] = -15
+	if got := pos.GnuBgPositionID(); got != "4P8PAAD/fwAAAA" {
+		t.Errorf("GnuBgPositionID() = %s, want 4P8PAAD/fwAAAA", got)
+	}
+}
+
+func TestGnuBgMatchID(t *testing.T) {
+	// Example of the GNU Backgammon manual: 9 point match at 2-4, cube at 2
+	// owned by player 0, player 1 on roll with 52
+	pos := Position{Cube: 2, CubePos: -1, Score: [2]int32{4, 2}} // Seen from player 2, on roll
+	if got := pos.GnuBgMatchID(-1, [2]int32{5, 2}, 2, 9, false); got != "QYkqASAAIAAA" {
+		t.Errorf("GnuBgMatchID() = %s, want QYkqASAAIAAA", got)
+	}
+
+	// Money game, centered cube, player 1 of GNU Backgammon to roll
+	if got := (Position{}).GnuBgMatchID(-1, [2]int32{0, 0}, 1, 0, false); got != "cAkAAAAAAAAA" {
+		t.Errorf("GnuBgMatchID() = %s, want cAkAAAAAAAAA", got)
+	}
+
+	if pos.GnuBgMatchID(-1, [2]int32{5, 2}, 2, 9, true) == pos.GnuBgMatchID(-1, [2]int32{5, 2}, 2, 9, false) {
+		t.Errorf("GnuBgMatchID() ignores the Crawford flag")
+	}
+}
@@ -100,45 +100,10 @@ func ParseXGID(xgid string) (*XGIDComponents, error) {
 	return components, nil
 }
 
-// swapMove converts a move from one player's perspective to the other
-// This is needed when the XGID position is swapped but the move notation
-// is always from the active player's perspective
-func swapMove(move [8]int8) [8]int8 {
-	var swapped [8]int8
-	for i := 0; i < 8; i++ {
-		swapped[i] = -1 // Initialize to unused
-	}
-
-	for i := 0; i < 8; i += 2 {
-		if move[i] == -1 {
-			break // End of move
-		}
-
-		from := move[i]
-		to := move[i+1]
-
-		// Swap point numbers (1 becomes 24, 24 becomes 1, etc.)
-		// Special cases: 25 (bar) stays 25, -2 (bear off) stays -2
-		if from == 25 {
-			swapped[i] = 25 // Bar stays bar
-		} else if from >= 1 && from <= 24 {
-			swapped[i] = 25 - from
-		} else {
-			swapped[i] = from
-		}
-
-		if to == -2 {
-			swapped[i+1] = -2 // Bear off stays bear off
-		} else if to == 25 {
-			swapped[i+1] = 25 // Bar stays bar
-		} else if to >= 1 && to <= 24 {
-			swapped[i+1] = 25 - to
-		} else {
-			swapped[i+1] = to
-		}
-	}
-
-	return swapped
+// String formats the components as an XGID string, the inverse of ParseXGID
+func (c *XGIDComponents) String() string {
+	return fmt.Sprintf("XGID=%s:%d:%d:%d:%s:%d:%d:%d:%d:%d", c.PositionID, c.CubeOwner, c.CubeValue,
+		c.PlayerToMove, c.Dice, c.ScoreX, c.ScoreO, c.CrawfordFlag, c.MatchLength, c.MaxCube)
 }
 
 // ApplyMove applies a checker move to a position and returns the resulting position
@@ -160,7 +125,7 @@ func ApplyMove(pos Position, move [8]int8, activePlayer int32) Position {
 
 		// Remove checker from source position
 		if from >= 0 && from < 26 {
-			if activePlayer == 1 {
+			if activePlayer == Player1 {
 				// Player X (positive checkers)
 				if checkers[from] > 0 {
 					checkers[from]--
@@ -179,7 +144,7 @@ func ApplyMove(pos Position, move [8]int8, activePlayer int32) Position {
 			continue
 		}
 		if to >= 0 && to < 26 {
-			if activePlayer == 1 {
+			if activePlayer == Player1 {
 				// Player X (positive checkers)
 				// Check if opponent has a blot at destination
 				if checkers[to] == -1 {
@@ -225,14 +190,20 @@ func DetectXGIDFileType(filename string) (string, error) {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	return detectXGIDType(file)
+}
+
+// detectXGIDType returns the type of an XGID position text as DetectXGIDFileType
+func detectXGIDType(r io.Reader) (string, error) {
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := scanner.Text()
 		if strings.Contains(line, "cube action") {
 			return "cube", nil
 		}
 		if strings.Contains(line, "to play") || strings.Contains(line, "à jouer") ||
-			strings.Contains(line, "zu spielen") || strings.Contains(line, "para jugar") ||
+			strings.Contains(line, "zu spielen") || strings.Contains(line, "zum spielen") ||
+			strings.Contains(line, "para jugar") ||
 			strings.Contains(line, "をプレイ") {
 			return "checker", nil
 		}
@@ -245,95 +216,187 @@ func DetectXGIDFileType(filename string) (string, error) {
 	return "unknown", nil
 }
 
+// toPlayLanguages maps the keyword of the "X to play 21" line to the ISO 639-1
+// code of the language of the file
+var toPlayLanguages = map[string]string{
+	"to play":     "en",
+	"à jouer":     "fr",
+	"zu spielen":  "de",
+	"zum spielen": "de",
+	"para jugar":  "es",
+	"da giocare":  "it",
+	"gioca":       "it",
+	"heitti":      "fi",
+	"να παίξει":   "el",
+	"играть":      "ru",
+	"をプレイ":        "ja",
+}
+
+// xgidPatterns holds the compiled regular expressions used to parse XGID
+// position texts
+type xgidPatterns struct {
+	xgid         *regexp.Regexp
+	players      *regexp.Regexp
+	score        *regexp.Regexp
+	xoScore      *regexp.Regexp
+	cube         *regexp.Regexp
+	toPlay       *regexp.Regexp
+	analysisLine *regexp.Regexp
+	ply          *regexp.Regexp
+	stats        *regexp.Regexp
+	version      *regexp.Regexp
+	boardTop     *regexp.Regexp
+	boardBottom  *regexp.Regexp
+}
+
+// newXGIDPatterns compiles the patterns of XGID position texts
+func newXGIDPatterns() *xgidPatterns {
+	return &xgidPatterns{
+		xgid:    regexp.MustCompile(`^XGID=([^:]+(?::[^:]+)*)`),
+		players: regexp.MustCompile(`^X:(\S+)\s+O:(\S+)`),
+
+		// Multi-language patterns for score/match
+		// English: "Score is X:2 O:3 13 pt.(s) match"
+		// French: "Le score est X:0 O:0 match en 13 pt(s)"
+		// German: "Spielstand ist S:0 G:0 13 Punkte(e) Match"
+		// Spanish: "La puntuación es X:0 O:0 13 pt.(s) partida"
+		// Italian: "Il Punteggio è X:0 O:0. Partita ai 13 punto/i"
+		// Finnish: "Tulos on X:0 O:0 13 pt. ottelu"
+		// Greek: "Το σκορ είναι X:0 O:0 13 pt.(s) παρτίδα"
+		// Russian: "Показатель X: 0 O: 0 13 Pt (S) совпадают"
+		score: regexp.MustCompile(`(?:Score|score|Spielstand|puntuación|Punteggio|Tulos|σκορ|Показатель)[^X]*[XS]:\s*(\d+)\s+[OG]:\s*(\d+)\s+(\d+)\s+`),
+
+		// Match score context "X-O: scoreX-scoreO/matchLength", found at the end of the pip count line
+		// English: "Pip count  X: 139  O: 156 X-O: 2-4/9"
+		// French: "Course  X: 139  O: 156 X-O: 2-4/9"
+		// Only the "X-O:" part is matched, so it does not depend on the language of the label
+		xoScore: regexp.MustCompile(`X-O:\s*(\d+)-(\d+)(?:/(\d+))?`),
+
+		// Multi-language patterns for cube
+		// English: "Cube: 2"
+		// French: "Videau: 2" or "Cube: 2"
+		// German: "Dopplerwürfel: 2"
+		// Spanish: "Cubo: 2"
+		// Italian: "Cubo: 2"
+		// Finnish: "Kuutio: 2"
+		// Greek: "Βίδος: 2"
+		// Russian: "Куб: 2"
+		// Japanese: "キューブ: 2"
+		cube: regexp.MustCompile(`(?:Cube|Cubo|Videau|Doppler(?:würfel)?|Dado|Kuutio|Βίδος|Куб|キューブ):\s*(\d+)`),
+
+		// Multi-language patterns for player to move and dice
+		// English: "X to play 22"
+		// French: "X à jouer 51"
+		// German: "X zu spielen 51" or "X zum spielen 51"
+		// Spanish: "X para jugar 51"
+		// Italian: "X gioca 51" or "X da giocare 51"
+		// Finnish: "X heitti 51"
+		// Greek: "X να παίξει51" (note: may have no space before dice!)
+		// Russian: "X играть 51"
+		// Japanese: "X をプレイ 51"
+		// The keyword gives the language of the file, see toPlayLanguages
+		toPlay: regexp.MustCompile(`([XO])\s+(to play|à jouer|zum? spielen|para jugar|da giocare|gioca|heitti|να παίξει|играть|をプレイ)\s*(\d+)`),
+
+		// Analysis line pattern - using simpler positional approach
+		// Format: "    1. <depth>      <move notation>              eq:<value>"
+		// The rank number starts the line, and equity appears at the end with "eq:" or "éq:" or "экв:"
+		// We'll parse this more robustly by looking for these markers rather than specific depth keywords
+		analysisLine: regexp.MustCompile(`^\s*(\d+)\.\s+(.+?)\s+(?:eq|éq|экв):([+-]?\d+[.,]\d+)(?:\s+\(([+-]?\d+[.,]\d+)\))?`),
+
+		// Ply depth extraction patterns (to extract from the depth field if present)
+		ply: regexp.MustCompile(`^(\d+)[-\s](?:ply|plis|Züge|полухода|полухода)`),
+
+		// Win/gammon/bg rate patterns - LANGUAGE INDEPENDENT
+		// All statistics lines follow the format: "  <label>: <win%> (G:<gammon%> B:<bg%>)"
+		// The label can be in any language, so we match the structure instead of specific words
+		// Pattern: starts with whitespace, has a colon, followed by percentage and (G:...% B:...%)
+		// First stats line after analysis = Player, second = Opponent
+		stats: regexp.MustCompile(`^\s+.+?:\s+(\d+[.,]\d+)%\s+\(G:\s*(\d+[.,]\d+)%\s+B:\s*(\d+[.,]\d+)%\)`),
+
+		// Version and MET pattern
+		// "eXtreme Gammon Version: 2.19.211.pre-release, MET: Kazaross XG2"
+		// Spanish: "eXtreme Gammon Versión: ..."
+		// Finnish: "eXtreme Gammon Versio: ..."
+		version: regexp.MustCompile(`eXtreme Gammon (?:Version|Versión|Versione|Versio|versio|Έκδοση|Версия|バージョン):\s+([^,]+),\s+(?:MET|TEM):\s+(.+)`),
+
+		// Board boundary markers
+		boardTop:    regexp.MustCompile(`^\s+\+13-14-15-16-17-18------19-20-21-22-23-24-\+`),
+		boardBottom: regexp.MustCompile(`^\s+\+12-11-10--9--8--7-------6--5--4--3--2--1-\+`),
+	}
+}
+
 // ParseXGIDFromReader parses an XGID position from an io.Reader
 // Returns the unified CheckerMove structure and MatchMetadata
 func ParseXGIDFromReader(r io.Reader) (*CheckerMove, *MatchMetadata, error) {
-	scanner := bufio.NewScanner(r)
-
 	metadata := &MatchMetadata{}
 	move := &CheckerMove{
 		Analysis: make([]CheckerAnalysis, 0),
 	}
 
-	var boardLines []string
-	inBoard := false
-	inAnalysis := false
-	var xgidString string // Store the full XGID string for reference
+	if err := parseXGIDPosition(r, newXGIDPatterns(), move, metadata, nil); err != nil {
+		return nil, nil, err
+	}
+	return move, metadata, nil
+}
 
-	// Regex patterns (language-independent where possible)
-	xgidRegex := regexp.MustCompile(`^XGID=([^:]+(?::[^:]+)*)`)
-	playersRegex := regexp.MustCompile(`^X:(\S+)\s+O:(\S+)`)
+// XGIDParser parses XGID position texts as ParseXGIDFromReader, reusing its
+// compiled patterns and buffers from one call to the next. This saves most
+// allocations when parsing many positions.
+// The results of Parse are overwritten by the next call, and an XGIDParser
+// must not be used by several goroutines at once.
+type XGIDParser struct {
+	patterns *xgidPatterns
+	move     CheckerMove
+	metadata MatchMetadata
+	buf      []byte
+}
 
-	// Multi-language patterns for score/match
-	// English: "Score is X:2 O:3 13 pt.(s) match"
-	// French: "Le score est X:0 O:0 match en 13 pt(s)"
-	// German: "Spielstand ist S:0 G:0 13 Punkte(e) Match"
-	// Spanish: "La puntuación es X:0 O:0 13 pt.(s) partida"
-	// Italian: "Il Punteggio è X:0 O:0. Partita ai 13 punto/i"
-	// Finnish: "Tulos on X:0 O:0 13 pt. ottelu"
-	// Greek: "Το σκορ είναι X:0 O:0 13 pt.(s) παρτίδα"
-	// Russian: "Показатель X: 0 O: 0 13 Pt (S) совпадают"
-	scoreRegex := regexp.MustCompile(`(?:Score|score|Spielstand|puntuación|Punteggio|Tulos|σκορ|Показатель)[^X]*[XS]:\s*(\d+)\s+[OG]:\s*(\d+)\s+(\d+)\s+`)
+// NewXGIDParser creates a new XGIDParser
+func NewXGIDParser() *XGIDParser {
+	return &XGIDParser{
+		patterns: newXGIDPatterns(),
+		move:     CheckerMove{Analysis: make([]CheckerAnalysis, 0, 8)},
+		buf:      make([]byte, 4096),
+	}
+}
 
-	// Multi-language patterns for cube
-	// English: "Cube: 2"
-	// French: "Videau: 2" or "Cube: 2"
-	// German: "Doppler: 2"
-	// Spanish: "Cubo: 2"
-	// Italian: "Cubo: 2"
-	// Finnish: "Kuutio: 2"
-	// Greek: "Βίδος: 2"
-	// Russian: "Куб: 2"
-	// Japanese: "キューブ: 2"
-	cubeRegex := regexp.MustCompile(`(?:Cube|Cubo|Videau|Doppler|Dado|Kuutio|Βίδος|Куб|キューブ):\s*(\d+)`)
-
-	// Multi-language patterns for player to move and dice
-	// English: "X to play 22"
-	// French: "X à jouer 51"
-	// German: "X zu spielen 51"
-	// Spanish: "X para jugar 51"
-	// Italian: "X gioca 51" or "X da giocare 51"
-	// Finnish: "X heitti 51"
-	// Greek: "X να παίξει51" (note: may have no space before dice!)
-	// Russian: "X играть 51"
-	// Japanese: "X をプレイ 51"
-	toPlayRegex := regexp.MustCompile(`([XO])\s+(?:to play|à jouer|zu spielen|para jugar|da giocare|gioca|heitti|να παίξει|играть|をプレイ)\s*(\d+)`)
-
-	// Analysis line pattern - using simpler positional approach
-	// Format: "    1. <depth>      <move notation>              eq:<value>"
-	// The rank number starts the line, and equity appears at the end with "eq:" or "éq:" or "экв:"
-	// We'll parse this more robustly by looking for these markers rather than specific depth keywords
-	analysisLineRegex := regexp.MustCompile(`^\s+(\d+)\.\s+(.+?)\s+(?:eq|éq|экв):([+-]?\d+\.\d+)(?:\s+\(([+-]?\d+\.\d+)\))?`)
-
-	// Ply depth extraction patterns (to extract from the depth field if present)
-	plyRegex := regexp.MustCompile(`^(\d+)[-\s](?:ply|plis|Züge|полухода|полухода)`)
-
-	// Win/gammon/bg rate patterns - LANGUAGE INDEPENDENT
-	// All statistics lines follow the format: "  <label>: <win%> (G:<gammon%> B:<bg%>)"
-	// The label can be in any language, so we match the structure instead of specific words
-	// Pattern: starts with whitespace, has a colon, followed by percentage and (G:...% B:...%)
-	// First stats line after analysis = Player, second = Opponent
-	statsRegex := regexp.MustCompile(`^\s+.+?:\s+(\d+\.\d+)%\s+\(G:\s*(\d+\.\d+)%\s+B:\s*(\d+\.\d+)%\)`)
-
-	// Version and MET pattern
-	// "eXtreme Gammon Version: 2.19.211.pre-release, MET: Kazaross XG2"
-	// Spanish: "eXtreme Gammon Versión: ..."
-	// Finnish: "eXtreme Gammon Versio: ..."
-	versionRegex := regexp.MustCompile(`eXtreme Gammon (?:Version|Versión|Versione|Versio|versio|Έκδοση|Версия|バージョン):\s+([^,]+),\s+(?:MET|TEM):\s+(.+)`)
+// Parse parses an XGID position from an io.Reader as ParseXGIDFromReader.
+// The returned CheckerMove and MatchMetadata are only valid until the next
+// call: copy them, including the Analysis slice, to keep them longer.
+func (p *XGIDParser) Parse(r io.Reader) (*CheckerMove, *MatchMetadata, error) {
+	p.move = CheckerMove{Analysis: p.move.Analysis[:0]}
+	p.metadata = MatchMetadata{}
+
+	if err := parseXGIDPosition(r, p.patterns, &p.move, &p.metadata, p.buf); err != nil {
+		return nil, nil, err
+	}
+	return &p.move, &p.metadata, nil
+}
 
-	// Board boundary markers
-	boardTopRegex := regexp.MustCompile(`^\s+\+13-14-15-16-17-18------19-20-21-22-23-24-\+`)
-	boardBottomRegex := regexp.MustCompile(`^\s+\+12-11-10--9--8--7-------6--5--4--3--2--1-\+`)
+// parseXGIDPosition parses an XGID position text into move and metadata,
+// which start empty. buf is the initial buffer of the line scanner, nil for
+// the default one.
+func parseXGIDPosition(r io.Reader, re *xgidPatterns, move *CheckerMove, metadata *MatchMetadata, buf []byte) error {
+	scanner := bufio.NewScanner(r)
+	if buf != nil {
+		scanner.Buffer(buf, bufio.MaxScanTokenSize)
+	}
+
+	var boardLines []string
+	inBoard := false
+	inAnalysis := false
+	var xgidString string // Store the full XGID string for reference
 
 	// Temporary storage for current move being parsed
 	var currentAnalysis *CheckerAnalysis
 	var xgidComponents XGIDComponents // Store parsed XGID components
+	scoreFound := false
 
 	for scanner.Scan() {
 		line := scanner.Text()
 
 		// Parse XGID line
-		if matches := xgidRegex.FindStringSubmatch(line); matches != nil {
+		if matches := re.xgid.FindStringSubmatch(line); matches != nil {
 			xgidString = matches[1]
 			components, err := ParseXGID(xgidString)
 			if err == nil {
@@ -343,37 +406,51 @@ func ParseXGIDFromReader(r io.Reader) (*CheckerMove, *MatchMetadata, error) {
 		}
 
 		// Parse player names
-		if matches := playersRegex.FindStringSubmatch(line); matches != nil {
+		if matches := re.players.FindStringSubmatch(line); matches != nil {
 			metadata.Player1Name = matches[1]
 			metadata.Player2Name = matches[2]
 			continue
 		}
 
 		// Parse score and match length
-		if matches := scoreRegex.FindStringSubmatch(line); matches != nil {
+		if matches := re.score.FindStringSubmatch(line); matches != nil {
 			scoreX, _ := strconv.ParseInt(matches[1], 10, 32)
 			scoreO, _ := strconv.ParseInt(matches[2], 10, 32)
 			matchLength, _ := strconv.ParseInt(matches[3], 10, 32)
 			move.Position.Score = [2]int32{int32(scoreX), int32(scoreO)}
 			metadata.MatchLength = int32(matchLength)
+			scoreFound = true
+			continue
+		}
+
+		// Parse "X-O:" score context, only used when there is no score line
+		if matches := re.xoScore.FindStringSubmatch(line); matches != nil {
+			if !scoreFound {
+				scoreX, _ := strconv.ParseInt(matches[1], 10, 32)
+				scoreO, _ := strconv.ParseInt(matches[2], 10, 32)
+				matchLength, _ := strconv.ParseInt(matches[3], 10, 32)
+				move.Position.Score = [2]int32{int32(scoreX), int32(scoreO)}
+				metadata.MatchLength = int32(matchLength)
+			}
 			continue
 		}
 
 		// Parse cube value
-		if matches := cubeRegex.FindStringSubmatch(line); matches != nil {
+		if matches := re.cube.FindStringSubmatch(line); matches != nil {
 			cube, _ := strconv.ParseInt(matches[1], 10, 32)
 			move.Position.Cube = int32(cube)
 			continue
 		}
 
 		// Parse player to move and dice
-		if matches := toPlayRegex.FindStringSubmatch(line); matches != nil {
+		if matches := re.toPlay.FindStringSubmatch(line); matches != nil {
 			if matches[1] == "X" {
-				move.ActivePlayer = 1
+				move.ActivePlayer = Player1
 			} else {
-				move.ActivePlayer = -1
+				move.ActivePlayer = Player2
 			}
-			dice := matches[2]
+			metadata.Language = toPlayLanguages[matches[2]]
+			dice := matches[3]
 			if len(dice) == 2 {
 				d1, _ := strconv.ParseInt(string(dice[0]), 10, 32)
 				d2, _ := strconv.ParseInt(string(dice[1]), 10, 32)
@@ -384,14 +461,14 @@ func ParseXGIDFromReader(r io.Reader) (*CheckerMove, *MatchMetadata, error) {
 		}
 
 		// Track board diagram
-		if boardTopRegex.MatchString(line) {
+		if re.boardTop.MatchString(line) {
 			inBoard = true
 			boardLines = []string{line}
 			continue
 		}
 		if inBoard {
 			boardLines = append(boardLines, line)
-			if boardBottomRegex.MatchString(line) {
+			if re.boardBottom.MatchString(line) {
 				inBoard = false
 				// Board diagram is optional - we don't need to store it in metadata
 			}
@@ -400,7 +477,7 @@ func ParseXGIDFromReader(r io.Reader) (*CheckerMove, *MatchMetadata, error) {
 
 		// Parse analysis lines
 		if inAnalysis {
-			if matches := analysisLineRegex.FindStringSubmatch(line); matches != nil {
+			if matches := re.analysisLine.FindStringSubmatch(line); matches != nil {
 				// Save previous move if exists
 				if currentAnalysis != nil {
 					move.Analysis = append(move.Analysis, *currentAnalysis)
@@ -412,31 +489,13 @@ func ParseXGIDFromReader(r io.Reader) (*CheckerMove, *MatchMetadata, error) {
 				// matches[4] = equity difference (optional)
 
 				fullMiddle := matches[2]
-				equity, _ := strconv.ParseFloat(matches[3], 64)
+				equity := parseDecimal(matches[3])
 
-				// Split the middle part into depth field and move notation
-				// The depth field is roughly the first 13 characters (columns 7-20)
-				// But we need to be flexible. Strategy: split by multiple spaces
-				parts := strings.SplitN(fullMiddle, "  ", 2) // Split on 2+ spaces
-
-				var depthField, moveNotation string
-				if len(parts) >= 2 {
-					depthField = strings.TrimSpace(parts[0])
-					moveNotation = strings.TrimSpace(parts[1])
-				} else {
-					// Fallback: no clear separator, try to extract from position
-					if len(fullMiddle) > 13 {
-						depthField = strings.TrimSpace(fullMiddle[:13])
-						moveNotation = strings.TrimSpace(fullMiddle[13:])
-					} else {
-						depthField = strings.TrimSpace(fullMiddle)
-						moveNotation = ""
-					}
-				}
+				depthField, moveNotation := splitAnalysisMiddle(fullMiddle)
 
 				// Extract ply depth from depth field
 				ply := 0
-				if plyMatches := plyRegex.FindStringSubmatch(depthField); plyMatches != nil {
+				if plyMatches := re.ply.FindStringSubmatch(depthField); plyMatches != nil {
 					ply, _ = strconv.Atoi(plyMatches[1])
 				}
 				// If no ply found, it's either Book/Livre/XG Roller/etc (depth stays 0)
@@ -451,6 +510,9 @@ func ParseXGIDFromReader(r io.Reader) (*CheckerMove, *MatchMetadata, error) {
 					Equity:        float32(equity),
 					AnalysisDepth: int16(ply),
 				}
+				if matches[4] != "" {
+					currentAnalysis.EquityDiff = float32(parseDecimal(matches[4]))
+				}
 				continue
 			}
 
@@ -458,10 +520,10 @@ func ParseXGIDFromReader(r io.Reader) (*CheckerMove, *MatchMetadata, error) {
 			// Both follow the same format, we use order to distinguish:
 			// First stats line after analysis = Player, second = Opponent
 			if currentAnalysis != nil {
-				if matches := statsRegex.FindStringSubmatch(line); matches != nil {
-					winRate, _ := strconv.ParseFloat(matches[1], 64)
-					gammonRate, _ := strconv.ParseFloat(matches[2], 64)
-					bgRate, _ := strconv.ParseFloat(matches[3], 64)
+				if matches := re.stats.FindStringSubmatch(line); matches != nil {
+					winRate := parseDecimal(matches[1])
+					gammonRate := parseDecimal(matches[2])
+					bgRate := parseDecimal(matches[3])
 
 					// If Player1WinRate is still 0, this is the player line
 					if currentAnalysis.Player1WinRate == 0 {
@@ -479,7 +541,7 @@ func ParseXGIDFromReader(r io.Reader) (*CheckerMove, *MatchMetadata, error) {
 		}
 
 		// Parse version and MET
-		if matches := versionRegex.FindStringSubmatch(line); matches != nil {
+		if matches := re.version.FindStringSubmatch(line); matches != nil {
 			metadata.ProductVersion = matches[1]
 			metadata.MET = matches[2]
 
@@ -493,7 +555,7 @@ func ParseXGIDFromReader(r io.Reader) (*CheckerMove, *MatchMetadata, error) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, nil, err
+		return err
 	}
 
 	// Save any remaining analysis that wasn't saved yet
@@ -504,6 +566,9 @@ func ParseXGIDFromReader(r io.Reader) (*CheckerMove, *MatchMetadata, error) {
 	// Convert XGID position to checker array and populate position
 	if xgidComponents.PositionID != "" {
 		move.Position.Checkers = XGIDToPosition(xgidComponents.PositionID)
+		if player, opponent := checkerCount(move.Position.Checkers); player > 15 || opponent > 15 {
+			return fmt.Errorf("invalid XGID position %q: more than 15 checkers per side", xgidComponents.PositionID)
+		}
 
 		// Set cube position based on cube owner
 		if xgidComponents.CubeOwner == 1 {
@@ -514,6 +579,10 @@ func ParseXGIDFromReader(r io.Reader) (*CheckerMove, *MatchMetadata, error) {
 			move.Position.CubePos = 0 // centered
 		}
 
+		// In money games the Crawford field holds the Jacoby and beaver rules
+		metadata.IsMoney = xgidComponents.MatchLength == 0
+		move.Position.Crawford = !metadata.IsMoney && xgidComponents.CrawfordFlag == 1
+
 		// Calculate actual cube value (2^cubeValue) if not already set
 		if move.Position.Cube == 0 && xgidComponents.CubeValue >= 0 {
 			cubeValue := int32(1)
@@ -523,34 +592,44 @@ func ParseXGIDFromReader(r io.Reader) (*CheckerMove, *MatchMetadata, error) {
 			move.Position.Cube = cubeValue
 		}
 
-		// XGID positions are stored from the perspective of playerToMove in the XGID
-		// If the text file indicates a different active player, we need to swap
-		// Example: XGID says playerToMove=-1 (O's perspective) but text says "X to play"
-		// This happens because XG stores positions consistently but can export from either perspective
-		//
-		// IMPORTANT: Move notation in XGID text files is ALWAYS from the active player's perspective
-		// (the player indicated in "X to play" or "O on roll"), NOT the XGID playerToMove perspective.
-		// So when we swap the position, we must also swap the move coordinates.
-		needsSwap := xgidComponents.PlayerToMove != move.ActivePlayer
-		if needsSwap {
+		// The XGID board is seen by X, while positions are stored from the
+		// point of view of the player on roll: swap it when O is on roll.
+		// Move notation is written from the player on roll's point of view,
+		// so the moves apply to the swapped position as they are.
+		if move.ActivePlayer == Player2 {
 			move.Position = swapPosition(move.Position)
 		}
 
 		// Compute final positions by applying each move to the initial position
 		for i := range move.Analysis {
-			moveToApply := move.Analysis[i].Move
-			// If we swapped the position, we need to swap the move coordinates too
-			// because the move notation is always from the active player's perspective
-			if needsSwap {
-				moveToApply = swapMove(moveToApply)
-				// Also update the stored move to the swapped version
-				move.Analysis[i].Move = moveToApply
-			}
-			move.Analysis[i].Position = ApplyMove(move.Position, moveToApply, move.ActivePlayer)
+			move.Analysis[i].Position = ApplyMove(move.Position, move.Analysis[i].Move, Player1)
+			move.Analysis[i].AbsolutePosition = move.Analysis[i].Position.Absolute(move.ActivePlayer)
 		}
 	}
 
-	return move, metadata, nil
+	return nil
+}
+
+// parseDecimal parses a number written with a decimal point or, as in some
+// localized exports, a decimal comma ("-0,491"). Invalid numbers parse as 0.
+func parseDecimal(s string) float64 {
+	f, _ := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	return f
+}
+
+// analysisDepthRegex matches the depth field at the start of an analysis line:
+// "4-ply", "3-plis", "2 Züge" and the like, "XG Roller++", "Book¹", "Livre¹",
+// "Buch¹" or "Rollout"
+var analysisDepthRegex = regexp.MustCompile(`^(?:\d+[-\s]?\p{L}+|XG\s+Roller\+*|(?:Book|Livre|Buch)¹?|Rollout)(?:\s+|$)`)
+
+// splitAnalysisMiddle splits the text between the rank and the equity of an
+// analysis line into the depth field and the move notation, on the known depth
+// fields of analysisDepthRegex. Columns may be separated by any run of spaces
+// or tabs, and moves without a from/to pair such as "Cannot Move" are kept.
+func splitAnalysisMiddle(middle string) (depthField, moveNotation string) {
+	middle = strings.TrimSpace(middle)
+	depth := analysisDepthRegex.FindString(middle)
+	return strings.Join(strings.Fields(depth), " "), strings.Join(strings.Fields(middle[len(depth):]), " ")
 }
 
 // ParseMoveNotation converts human-ireadable move notation to Move array
@@ -574,10 +653,13 @@ func ParseMoveNotation(notation string) [8]int8 {
 	moveIndex := 0
 
 	for _, part := range parts {
+		// Hits are marked with "*", as in "13/7*"
+		part = strings.ReplaceAll(part, "*", "")
+
 		// Handle multiplier notation like "8/5(2)"
 		multiplier := 1
 		if idx := strings.Index(part, "("); idx != -1 {
-			multStr := part[idx+1 : len(part)-1]
+			multStr := strings.TrimSuffix(part[idx+1:], ")")
 			multiplier, _ = strconv.Atoi(multStr)
 			part = part[:idx]
 		}
@@ -629,57 +711,94 @@ func ParseMoveNotation(notation string) [8]int8 {
 	return move
 }
 
+// FormatPercent formats a rate between 0 and 1 as a percentage with two
+// decimals, as XG displays winning chances: 0.6138 gives "61.38%"
+func FormatPercent(rate float32) string {
+	return fmt.Sprintf("%.2f%%", rate*100)
+}
+
+// FormatEquity formats an equity for display with a leading sign and two
+// decimals: 0.58 gives "+0.58"
+func FormatEquity(eq float32) string {
+	return fmt.Sprintf("%+.2f", eq)
+}
+
+// FormatMove converts a Move array back to move notation, the inverse of ParseMoveNotation
+// Repeated from/to pairs are grouped, e.g. "8/5(2) 6/5(2)"
+func FormatMove(move [8]int8) string {
+	formatPoint := func(p int8) string {
+		switch p {
+		case 25:
+			return "Bar"
+		case -2:
+			return "Off"
+		}
+		return strconv.Itoa(int(p))
+	}
+
+	var parts []string
+	for i := 0; i < 8 && move[i] != -1; {
+		count := 1
+		for i+2*count < 8 && move[i+2*count] == move[i] && move[i+2*count+1] == move[i+1] {
+			count++
+		}
+
+		part := formatPoint(move[i]) + "/" + formatPoint(move[i+1])
+		if count > 1 {
+			part += fmt.Sprintf("(%d)", count)
+		}
+		parts = append(parts, part)
+		i += 2 * count
+	}
+
+	return strings.Join(parts, " ")
+}
+
 // XGIDToPosition converts an XGID position string to a checker array
 // XGID format uses base-64 encoding: '-' = 0, 'A'=1, 'B'=2, ..., 'Z'=26, 'a'=27, ..., 'o'=40
 // Lowercase letters represent checkers for player O (negative in our format)
+//
+// The XGID describes the board from X's point of view, in the same order as
+// our format: character 0 is O's bar (index 0), characters 1-24 are X's points
+// 1-24 and character 25 is X's bar (index 25). The array is therefore the
+// position seen by X; positions with O on roll are swapped by the parsers.
 func XGIDToPosition(positionID string) [26]int8 {
-	var position [26]int8
-
 	if len(positionID) != 26 {
-		return position // Invalid position
-	}
-
-	for i := 0; i < 26; i++ {
-		char := positionID[i]
-		var count int8
-
-		if char == '-' {
-			count = 0
-		} else if char >= 'A' && char <= 'Z' {
-			// X player checkers (positive)
-			count = int8(char - 'A' + 1)
-		} else if char >= 'a' && char <= 'o' {
-			// O player checkers (negative)
-			count = -int8(char - 'a' + 1)
-		}
-
-		// XGID positions are in reverse order: index 0 = point 24, index 23 = point 1
-		// XGID indices 24-25 are the bar positions
-		// Our internal format: index 0 = opponent bar, index 1-24 = points, index 25 = player bar
-		//
-		// IMPORTANT: XGID always encodes from X's absolute perspective:
-		// - XGID index 24 = O's checkers on the bar (lowercase letters)
-		// - XGID index 25 = X's checkers on the bar (uppercase letters)
-		//
-		// Our internal format is from the active player's perspective:
-		// - position[25] = active player's checkers on bar
-		// - position[0] = opponent's checkers on bar
-		//
-		// So when parsing an XGID where playerToMove=1 (X):
-		// - XGID[25] (X's bar) -> position[25] (player's bar)
-		// - XGID[24] (O's bar) -> position[0] (opponent's bar)
-		//
-		// When playerToMove=-1 (O), this will be swapped later by swapPosition()
-		if i < 24 {
-			position[24-i] = count // REVERSED: index 0 -> point 24, index 23 -> point 1
-		} else if i == 24 {
-			position[0] = count // XGID index 24 is the bar for O
-		} else if i == 25 {
-			position[25] = count // XGID index 25 is the bar for X
-		}
-	}
-
-	return position
+		return [26]int8{} // Invalid position
+	}
+	return xgidBoardPosition(positionID)
+}
+
+// PositionToXGID converts a checker array to an XGID position string, the inverse of XGIDToPosition
+func PositionToXGID(checkers [26]int8) string {
+	encode := func(count int8) byte {
+		switch {
+		case count > 0:
+			return 'A' + byte(count-1)
+		case count < 0:
+			return 'a' + byte(-count-1)
+		}
+		return '-'
+	}
+
+	var id [26]byte
+	for i, count := range checkers {
+		id[i] = encode(count)
+	}
+	return string(id[:])
+}
+
+// checkerCount returns the number of checkers on the board for the player
+// (positive values) and the opponent (negative values)
+func checkerCount(checkers [26]int8) (player, opponent int) {
+	for _, c := range checkers {
+		if c > 0 {
+			player += int(c)
+		} else {
+			opponent -= int(c)
+		}
+	}
+	return player, opponent
 }
 
 // ParseXGIDCubeFile parses an XGID cube decision file and returns a CubeMove with metadata
@@ -710,24 +829,31 @@ func ParseXGIDCubeFromReader(r io.Reader) (*CubeMove, *MatchMetadata, error) {
 	xgidRegex := regexp.MustCompile(`^XGID=([^:]+(?::[^:]+)*)`)
 	playersRegex := regexp.MustCompile(`^X:(\S+)\s+O:(\S+)`)
 	scoreRegex := regexp.MustCompile(`(?:Score|score|Spielstand|puntuación|Punteggio|Tulos|σκορ|Показатель)[^X]*[XS]:\s*(\d+)\s+[OG]:\s*(\d+)\s+(\d+)\s+`)
-	cubeRegex := regexp.MustCompile(`(?:Cube|Cubo|Videau|Doppler|Dado|Kuutio|Βίδος|Куб|キューブ):\s*(\d+)`)
+	xoScoreRegex := regexp.MustCompile(`X-O:\s*(\d+)-(\d+)(?:/(\d+))?`)
+	cubeRegex := regexp.MustCompile(`(?:Cube|Cubo|Videau|Doppler(?:würfel)?|Dado|Kuutio|Βίδος|Куб|キューブ):\s*(\d+)`)
 
 	// Cube action line: "X on roll, cube action" / "O on roll, cube action"
 	cubeActionRegex := regexp.MustCompile(`([XO])\s+on roll,\s+cube action`)
 
-	// Analysis depth: "Analyzed in 4-ply"
-	analyzedRegex := regexp.MustCompile(`Analyzed in (\d+)-ply`)
+	// Analysis method and depth, the label is language dependent:
+	// English: "Analyzed in 4-ply" / "Analyzed in XG Roller++" / "Analyzed in Rollout"
+	// French: "Analysé avec XG Roller++"
+	// German: "Analysiert in XG Roller++"
+	// Japanese: "XG Roller++で分析済み"
+	// The method is read with methodRegex; the rollout may be followed by its settings.
+	analyzedRegex := regexp.MustCompile(`^\s*(?:(?:Analyzed in|Analysé avec|Analysiert in)\s+(.+?)|(.+?)\s*で分析済み)\s*$`)
+	methodRegex := regexp.MustCompile(`^(?:(\d+)[-\s]?(?:ply|plis|Züge|полухода)|XG (Roller\+*)|((?i:roll-?out)|ロールアウト)(?:\s.*)?)$`)
 
 	// Player/Opponent winning chances: "Player Winning Chances:   61.89% (G:37.15% B:0.42%)"
-	winChancesRegex := regexp.MustCompile(`(Player|Opponent|Joueur|Adversaire|Spieler|Gegner|プレーヤー|対戦相手)\s+(?:Winning Chances|chances de gagner):\s+(\d+\.\d+)%\s+\(G:\s*(\d+\.\d+)%\s+B:\s*(\d+\.\d+)%\)`)
+	winChancesRegex := regexp.MustCompile(`(Player|Opponent|Joueur|Adversaire|Spieler|Gegner|プレーヤー|対戦相手)\s+(?:Winning Chances|chances de gagner):\s+(\d+[.,]\d+)%\s+\(G:\s*(\d+[.,]\d+)%\s+B:\s*(\d+[.,]\d+)%\)`)
 
 	// Cubeless equities: "Cubeless Equities: No Double=+0.513, Double=+1.048"
-	cubelessRegex := regexp.MustCompile(`Cubeless.*?No\s+(?:Double|double|Doublet|redouble)=([+-]?\d+\.\d+).*?(?:Double|double|Doublet|redouble)=([+-]?\d+\.\d+)`)
+	cubelessRegex := regexp.MustCompile(`Cubeless.*?No\s+(?:Double|double|Doublet|redouble)=([+-]?\d+[.,]\d+).*?(?:Double|double|Doublet|redouble)=([+-]?\d+[.,]\d+)`)
 
 	// Cubeful equities lines: "       No double:     +0.637 (-0.109)"
-	cubefulNoDoubleRegex := regexp.MustCompile(`No\s+(?:double|redouble|Doublet):\s+([+-]?\d+\.\d+)`)
-	cubefulDoubleTakeRegex := regexp.MustCompile(`(?:Double|Redouble|Doublet)/(?:Take|Prendre|prendre):\s+([+-]?\d+\.\d+)`)
-	cubefulDoublePassRegex := regexp.MustCompile(`(?:Double|Redouble|Doublet)/(?:Pass|Passer|passer):\s+([+-]?\d+\.\d+)`)
+	cubefulNoDoubleRegex := regexp.MustCompile(`No\s+(?:double|redouble|Doublet):\s+([+-]?\d+[.,]\d+)`)
+	cubefulDoubleTakeRegex := regexp.MustCompile(`(?:Double|Redouble|Doublet)/(?:Take|Prendre|prendre):\s+([+-]?\d+[.,]\d+)`)
+	cubefulDoublePassRegex := regexp.MustCompile(`(?:Double|Redouble|Doublet)/(?:Pass|Passer|passer):\s+([+-]?\d+[.,]\d+)`)
 
 	// Best cube action: "Best Cube action: Double / Take"
 	bestActionRegex := regexp.MustCompile(`Best.*?:\s*(.*?)$`)
@@ -736,6 +862,8 @@ func ParseXGIDCubeFromReader(r io.Reader) (*CubeMove, *MatchMetadata, error) {
 
 	var xgidComponents XGIDComponents
 	playerStatsCollected := false
+	scoreFound := false
+	noDoubleFound, doubleTakeFound, doublePassFound := false, false, false
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -764,6 +892,19 @@ func ParseXGIDCubeFromReader(r io.Reader) (*CubeMove, *MatchMetadata, error) {
 			matchLength, _ := strconv.ParseInt(matches[3], 10, 32)
 			cubeMove.Position.Score = [2]int32{int32(scoreX), int32(scoreO)}
 			metadata.MatchLength = int32(matchLength)
+			scoreFound = true
+			continue
+		}
+
+		// Parse "X-O:" score context, only used when there is no score line
+		if matches := xoScoreRegex.FindStringSubmatch(line); matches != nil {
+			if !scoreFound {
+				scoreX, _ := strconv.ParseInt(matches[1], 10, 32)
+				scoreO, _ := strconv.ParseInt(matches[2], 10, 32)
+				matchLength, _ := strconv.ParseInt(matches[3], 10, 32)
+				cubeMove.Position.Score = [2]int32{int32(scoreX), int32(scoreO)}
+				metadata.MatchLength = int32(matchLength)
+			}
 			continue
 		}
 
@@ -777,25 +918,36 @@ func ParseXGIDCubeFromReader(r io.Reader) (*CubeMove, *MatchMetadata, error) {
 		// Parse player on roll and cube action
 		if matches := cubeActionRegex.FindStringSubmatch(line); matches != nil {
 			if matches[1] == "X" {
-				cubeMove.ActivePlayer = 1
+				cubeMove.ActivePlayer = Player1
 			} else {
-				cubeMove.ActivePlayer = -1
+				cubeMove.ActivePlayer = Player2
 			}
 			continue
 		}
 
-		// Parse analysis depth
+		// Parse analysis method and depth
 		if matches := analyzedRegex.FindStringSubmatch(line); matches != nil {
-			depth, _ := strconv.ParseInt(matches[1], 10, 32)
-			cubeMove.Analysis.AnalysisDepth = int32(depth)
+			matches = methodRegex.FindStringSubmatch(matches[1] + matches[2])
+			switch {
+			case matches == nil:
+				// Unknown method, e.g. from a newer XG version
+			case matches[1] != "":
+				depth, _ := strconv.ParseInt(matches[1], 10, 32)
+				cubeMove.Analysis.AnalysisDepth = int32(depth)
+				cubeMove.Analysis.Method = AnalysisMethodPly
+			case matches[2] != "":
+				cubeMove.Analysis.Method = AnalysisMethodRoller
+			default:
+				cubeMove.Analysis.Method = AnalysisMethodRollout
+			}
 			continue
 		}
 
 		// Parse winning chances
 		if matches := winChancesRegex.FindStringSubmatch(line); matches != nil {
-			winRate, _ := strconv.ParseFloat(matches[2], 64)
-			gammonRate, _ := strconv.ParseFloat(matches[3], 64)
-			bgRate, _ := strconv.ParseFloat(matches[4], 64)
+			winRate := parseDecimal(matches[2])
+			gammonRate := parseDecimal(matches[3])
+			bgRate := parseDecimal(matches[4])
 
 			// First match is Player, second is Opponent
 			if !playerStatsCollected {
@@ -812,8 +964,8 @@ func ParseXGIDCubeFromReader(r io.Reader) (*CubeMove, *MatchMetadata, error) {
 
 		// Parse cubeless equities
 		if matches := cubelessRegex.FindStringSubmatch(line); matches != nil {
-			noDouble, _ := strconv.ParseFloat(matches[1], 64)
-			double, _ := strconv.ParseFloat(matches[2], 64)
+			noDouble := parseDecimal(matches[1])
+			double := parseDecimal(matches[2])
 			cubeMove.Analysis.CubelessNoDouble = float32(noDouble)
 			cubeMove.Analysis.CubelessDouble = float32(double)
 			continue
@@ -821,18 +973,21 @@ func ParseXGIDCubeFromReader(r io.Reader) (*CubeMove, *MatchMetadata, error) {
 
 		// Parse cubeful equities
 		if matches := cubefulNoDoubleRegex.FindStringSubmatch(line); matches != nil {
-			eq, _ := strconv.ParseFloat(matches[1], 64)
+			eq := parseDecimal(matches[1])
 			cubeMove.Analysis.CubefulNoDouble = float32(eq)
+			noDoubleFound = true
 			continue
 		}
 		if matches := cubefulDoubleTakeRegex.FindStringSubmatch(line); matches != nil {
-			eq, _ := strconv.ParseFloat(matches[1], 64)
+			eq := parseDecimal(matches[1])
 			cubeMove.Analysis.CubefulDoubleTake = float32(eq)
+			doubleTakeFound = true
 			continue
 		}
 		if matches := cubefulDoublePassRegex.FindStringSubmatch(line); matches != nil {
-			eq, _ := strconv.ParseFloat(matches[1], 64)
+			eq := parseDecimal(matches[1])
 			cubeMove.Analysis.CubefulDoublePass = float32(eq)
+			doublePassFound = true
 			continue
 		}
 
@@ -840,21 +995,27 @@ func ParseXGIDCubeFromReader(r io.Reader) (*CubeMove, *MatchMetadata, error) {
 		if matches := bestActionRegex.FindStringSubmatch(line); matches != nil {
 			if strings.Contains(line, "Best") {
 				action := strings.ToLower(matches[1])
-				if strings.Contains(action, "double") && strings.Contains(action, "take") {
-					cubeMove.CubeAction = 2 // Take
+				// "No double / Beaver" only says what the opponent would do
+				doubling := !strings.HasPrefix(action, "no ")
+				if doubling && strings.Contains(action, "raccoon") {
+					cubeMove.CubeAction = CubeRaccoon
+				} else if doubling && strings.Contains(action, "beaver") {
+					cubeMove.CubeAction = CubeBeaver
+				} else if strings.Contains(action, "double") && strings.Contains(action, "take") {
+					cubeMove.CubeAction = CubeTake
 				} else if strings.Contains(action, "double") && strings.Contains(action, "pass") {
-					cubeMove.CubeAction = 3 // Pass
+					cubeMove.CubeAction = CubePass
 				} else if strings.Contains(action, "double") || strings.Contains(action, "redouble") {
-					cubeMove.CubeAction = 1 // Double
+					cubeMove.CubeAction = CubeDouble
 				} else if strings.Contains(action, "take") || strings.Contains(action, "pass") {
 					// Response to opponent's double
 					if strings.Contains(action, "take") {
-						cubeMove.CubeAction = 2
+						cubeMove.CubeAction = CubeTake
 					} else {
-						cubeMove.CubeAction = 3
+						cubeMove.CubeAction = CubePass
 					}
 				} else {
-					cubeMove.CubeAction = 0 // No double
+					cubeMove.CubeAction = CubeNoDouble
 				}
 			}
 			continue
@@ -884,12 +1045,137 @@ func ParseXGIDCubeFromReader(r io.Reader) (*CubeMove, *MatchMetadata, error) {
 		} else {
 			cubeMove.Position.CubePos = 0 // centered
 		}
+
+		// In money games the Crawford field holds the Jacoby and beaver rules
+		metadata.IsMoney = xgidComponents.MatchLength == 0
+		cubeMove.Position.Crawford = !metadata.IsMoney && xgidComponents.CrawfordFlag == 1
+
+		// The XGID board is seen by X, swap it when O is on roll
+		if cubeMove.ActivePlayer == Player2 {
+			cubeMove.Position = swapPosition(cubeMove.Position)
+		}
 	}
 
-	// Calculate wrong pass/take percentage if we have the data
-	if cubeMove.Analysis.CubefulDoubleTake != 0 && cubeMove.Analysis.CubefulDoublePass != 0 {
+	// Calculate wrong pass/take percentage only when all cubeful equities were given;
+	// a zero equity is a valid value, so presence is tracked separately
+	if noDoubleFound && doubleTakeFound && doublePassFound {
 		cubeMove.Analysis.WrongPassTakePercent = (cubeMove.Analysis.CubefulDoubleTake - cubeMove.Analysis.CubefulDoublePass) * 100
 	}
+	if noDoubleFound && doublePassFound {
+		cubeMove.Analysis.TooGood = cubeMove.Analysis.CubefulNoDouble > cubeMove.Analysis.CubefulDoublePass
+	}
 
 	return cubeMove, metadata, nil
 }
+
+// newXGIDComponents returns the XGID of a position seen from activePlayer, as
+// written by FormatXGIDText. dice is {0, 0} for a cube decision.
+func newXGIDComponents(pos Position, activePlayer int32, dice [2]int32, matchLength int32) XGIDComponents {
+	pos = pos.Absolute(activePlayer)
+	cubeValue := int32(0)
+	for v := pos.Cube; v > 1; v /= 2 {
+		cubeValue++
+	}
+
+	components := XGIDComponents{
+		PositionID:   PositionToXGID(pos.Checkers),
+		CubeOwner:    pos.CubePos,
+		CubeValue:    cubeValue,
+		PlayerToMove: activePlayer,
+		Dice:         fmt.Sprintf("%d%d", dice[0], dice[1]),
+		ScoreX:       pos.Score[0],
+		ScoreO:       pos.Score[1],
+		MatchLength:  matchLength,
+		MaxCube:      10,
+	}
+	if pos.Crawford {
+		components.CrawfordFlag = 1
+	}
+	return components
+}
+
+// XGIDs returns the XGID of the position before each move of the match, in
+// the order of the games and moves, keeping only the first occurrence of each.
+// Cube decisions have the dice "00".
+func (m *Match) XGIDs() []string {
+	var xgids []string
+	seen := make(map[string]bool)
+	for _, game := range m.Games {
+		for _, move := range game.Moves {
+			var components XGIDComponents
+			switch {
+			case move.CheckerMove != nil:
+				cm := move.CheckerMove
+				components = newXGIDComponents(cm.Position, cm.ActivePlayer, cm.Dice, m.Metadata.MatchLength)
+			case move.CubeMove != nil:
+				cm := move.CubeMove
+				components = newXGIDComponents(cm.Position, cm.ActivePlayer, [2]int32{}, m.Metadata.MatchLength)
+			default:
+				continue
+			}
+
+			xgid := components.String()
+			if !seen[xgid] {
+				seen[xgid] = true
+				xgids = append(xgids, xgid)
+			}
+		}
+	}
+	return xgids
+}
+
+// FormatXGIDText formats a checker move as an XG position text export, the
+// format read by ParseXGIDFromReader: XGID line, players, score, board diagram,
+// cube, player to play and the analysed moves, in English.
+// The XGID and board diagram are written from X's point of view, as XG does,
+// so that parsing the text back gives the same CheckerMove.
+func FormatXGIDText(move *CheckerMove, meta *MatchMetadata) string {
+	if meta == nil {
+		meta = &MatchMetadata{}
+	}
+
+	cube := move.Position.Cube
+	if cube < 1 {
+		cube = 1
+	}
+	components := newXGIDComponents(move.Position, move.ActivePlayer, move.Dice, meta.MatchLength)
+
+	player := "X"
+	if move.ActivePlayer == Player2 {
+		player = "O"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(components.String() + "\n\n")
+	fmt.Fprintf(&sb, "X:%s   O:%s\n", meta.Player1Name, meta.Player2Name)
+	if meta.MatchLength > 0 {
+		fmt.Fprintf(&sb, "Score is X:%d O:%d %d pt.(s) match.\n", components.ScoreX, components.ScoreO, meta.MatchLength)
+	}
+	sb.WriteString(RenderASCIIBoard(Position{Checkers: xgidBoardPosition(components.PositionID)}))
+	fmt.Fprintf(&sb, "Cube: %d\n", cube)
+	fmt.Fprintf(&sb, "%s to play %s\n\n", player, components.Dice)
+
+	for i, analysis := range move.Analysis {
+		depth := fmt.Sprintf("%d-ply", analysis.AnalysisDepth)
+		// Equities have three decimals as in XG's export, unlike FormatEquity
+		fmt.Fprintf(&sb, "    %d. %-11s %-28s eq:%+.3f", i+1, depth, FormatMove(analysis.Move), analysis.Equity)
+		if i > 0 {
+			diff := analysis.EquityDiff
+			if diff == 0 {
+				diff = analysis.Equity - move.Analysis[0].Equity
+			}
+			fmt.Fprintf(&sb, " (%+.3f)", diff)
+		}
+		sb.WriteString("\n")
+		fmt.Fprintf(&sb, "      Player:   %s (G:%s B:%s)\n",
+			FormatPercent(analysis.Player1WinRate), FormatPercent(analysis.Player1GammonRate), FormatPercent(analysis.Player1BgRate))
+		fmt.Fprintf(&sb, "      Opponent: %s (G:%s B:%s)\n\n",
+			FormatPercent(1-analysis.Player1WinRate), FormatPercent(analysis.Player2GammonRate), FormatPercent(analysis.Player2BgRate))
+	}
+
+	if meta.ProductVersion != "" {
+		fmt.Fprintf(&sb, "\neXtreme Gammon Version: %s, MET: %s\n", meta.ProductVersion, meta.MET)
+	}
+
+	return sb.String()
+}
@@ -6,6 +6,9 @@
 package xgparser
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -279,21 +282,120 @@ eXtreme Gammon Version: 2.19.211.pre-release, MET: Kazaross XG2
 	}
 }
 
+func TestXGIDParser(t *testing.T) {
+	parser := NewXGIDParser()
+	for _, file := range []string{"01_checkerPosition_EN.txt", "01_checkerPosition_FR.txt", "01_checkerPosition_EN.txt"} {
+		data, err := os.ReadFile(filepath.Join("../test/2025-11-04", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantMove, wantMeta, err := ParseXGIDFromReader(strings.NewReader(string(data)))
+		if err != nil {
+			t.Fatalf("ParseXGIDFromReader(%s) error = %v", file, err)
+		}
+
+		move, meta, err := parser.Parse(strings.NewReader(string(data)))
+		if err != nil {
+			t.Fatalf("Parse(%s) error = %v", file, err)
+		}
+		if !reflect.DeepEqual(move, wantMove) || *meta != *wantMeta {
+			t.Errorf("Parse(%s) = %+v, %+v, want %+v, %+v", file, move, meta, wantMove, wantMeta)
+		}
+	}
+
+	// Nothing is left over from the previous call
+	move, meta, err := parser.Parse(strings.NewReader("XGID=-b----E-C---eE---c-e----B-:0:0:1:52:0:0:0:13:10\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(move.Analysis) != 0 || move.Dice != [2]int32{} || meta.MET != "" || meta.Language != "" {
+		t.Errorf("Parse() kept data from the previous call: %+v, %+v", move, meta)
+	}
+}
+
+// benchmarkXGIDText returns the content of an XGID position fixture
+func benchmarkXGIDText(b *testing.B) string {
+	data, err := os.ReadFile("../test/2025-11-04/01_checkerPosition_EN.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return string(data)
+}
+
+func BenchmarkParseXGIDFromReader(b *testing.B) {
+	text := benchmarkXGIDText(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := ParseXGIDFromReader(strings.NewReader(text)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkXGIDParser(b *testing.B) {
+	text := benchmarkXGIDText(b)
+	parser := NewXGIDParser()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := parser.Parse(strings.NewReader(text)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestXGIDToPosition(t *testing.T) {
-	// Test starting position encoding
-	// This is a simplified test - actual XGID encoding is complex
-	positionID := "-b----E-C---eE---c-e----B-"
-	position := XGIDToPosition(positionID)
+	// X on roll with 2 checkers on the bar and O with 2 checkers on X's 24 point
+	position := XGIDToPosition("----BaC-B---aD--aa-bcbbBbB")
+	if position[25] != 2 {
+		t.Errorf("Position[25] = %d, want 2 X checkers on the bar", position[25])
+	}
+	if position[0] != 0 || position[24] != -2 || position[23] != 2 {
+		t.Errorf("Position[0], [23], [24] = %d, %d, %d, want 0, 2, -2", position[0], position[23], position[24])
+	}
+	if position[4] != 2 || position[5] != -1 || position[6] != 3 {
+		t.Errorf("Position[4..6] = %v, want [2 -1 3]", position[4:7])
+	}
 
-	// Position should be a valid [26]int8 array
-	if len(position) != 26 {
-		t.Errorf("Position length = %v, want 26", len(position))
+	files, _ := filepath.Glob("../test/2025-11-04/*.txt")
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+		components, err := ParseXGID(lines[0])
+		if err != nil {
+			t.Fatalf("%s: ParseXGID() error = %v", file, err)
+		}
+		board, err := ParseASCIIBoard(lines)
+		if err != nil {
+			t.Fatalf("%s: ParseASCIIBoard() error = %v", file, err)
+		}
+		if got := XGIDToPosition(components.PositionID); got != board.Checkers {
+			t.Errorf("%s: XGIDToPosition() = %v, board diagram %v", filepath.Base(file), got, board.Checkers)
+		}
 	}
+}
 
-	// Basic sanity check - starting position shouldn't have extreme values
-	for i, count := range position {
-		if count > 15 || count < -15 {
-			t.Errorf("Position[%d] = %v, invalid checker count", i, count)
+func TestParseXGIDFromReader_LegalAnalysis(t *testing.T) {
+	files, _ := filepath.Glob("../test/2025-11-04/01_checkerPosition_*.txt")
+	for _, file := range files {
+		move, _, err := ParseXGIDFile(file)
+		if err != nil {
+			t.Fatalf("ParseXGIDFile(%s) error = %v", file, err)
+		}
+		// Positions are seen from the player on roll, whose checkers move with activePlayer 1
+		plays := move.Position.LegalMoves(move.Dice, 1)
+		for i, analysis := range move.Analysis {
+			legal := false
+			for _, play := range plays {
+				legal = legal || ApplyMove(move.Position, play, 1) == analysis.Position
+			}
+			if !legal {
+				t.Errorf("%s: Analysis[%d] %s is not a legal play", filepath.Base(file), i, FormatMove(analysis.Move))
+			}
 		}
 	}
 }
@@ -356,3 +458,645 @@ eXtreme Gammon Version: 2.19.211.pre-release, MET: Kazaross XG2
 		}
 	}
 }
+
+func TestParseXGIDFromReader_TabSeparatedAnalysis(t *testing.T) {
+	input := "XGID=-a-B--E-B-a-dDB--b-bcB---:1:1:1:21:3:6:0:7:10\n" +
+		"\n" +
+		"X to play 21\n" +
+		"\n" +
+		"\t1.\t4-ply\t19/18 14/12\teq:-0.491\n" +
+		"\t2. XG Roller++ 19/18 3/1 eq:-0.556 (-0.065)\n" +
+		"3.\tBook\t\t19/17 18/17\t\teq:-0.576 (-0.085)\n"
+
+	move, _, err := ParseXGIDFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseXGIDFromReader() error = %v", err)
+	}
+	if len(move.Analysis) != 3 {
+		t.Fatalf("len(Analysis) = %d, want 3", len(move.Analysis))
+	}
+
+	tests := []struct {
+		depth int16
+		move  string
+	}{
+		{4, "19/18 14/12"},
+		{0, "19/18 3/1"},
+		{0, "19/17 18/17"},
+	}
+	for i, tt := range tests {
+		analysis := move.Analysis[i]
+		if analysis.AnalysisDepth != tt.depth {
+			t.Errorf("Analysis[%d].AnalysisDepth = %d, want %d", i, analysis.AnalysisDepth, tt.depth)
+		}
+		if got := FormatMove(analysis.Move); got != tt.move {
+			t.Errorf("Analysis[%d].Move = %q, want %q", i, got, tt.move)
+		}
+	}
+}
+
+func TestSplitAnalysisMiddle(t *testing.T) {
+	tests := []struct {
+		middle, depth, move string
+	}{
+		{"4-ply       19/18 14/12", "4-ply", "19/18 14/12"},
+		{"3-plis\t14/11", "3-plis", "14/11"},
+		{"2 Züge  13/7 13/9", "2 Züge", "13/7 13/9"},
+		{"XG Roller++\tBar/22 24/22", "XG Roller++", "Bar/22 24/22"},
+		{"Livre¹      24/23 13/8", "Livre¹", "24/23 13/8"},
+		{"Rollout 6/off(2)", "Rollout", "6/off(2)"},
+		// Moves without a from/to pair stay in the move column
+		{"2-ply       Cannot Move", "2-ply", "Cannot Move"},
+		{"19/18 14/12", "", "19/18 14/12"},
+	}
+	for _, tt := range tests {
+		depth, move := splitAnalysisMiddle(tt.middle)
+		if depth != tt.depth || move != tt.move {
+			t.Errorf("splitAnalysisMiddle(%q) = %q, %q, want %q, %q", tt.middle, depth, move, tt.depth, tt.move)
+		}
+	}
+}
+
+func TestParseXGIDFromReader_Language(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"01_checkerPosition_EN.txt", "en"},
+		{"01_checkerPosition_FR.txt", "fr"},
+		{"01_checkerPosition_DE.txt", "de"},
+	}
+
+	for _, tt := range tests {
+		move, metadata, err := ParseXGIDFile(filepath.Join("../test/2025-11-04", tt.file))
+		if err != nil {
+			t.Fatalf("ParseXGIDFile(%s) error = %v", tt.file, err)
+		}
+		if metadata.Language != tt.want {
+			t.Errorf("%s: Language = %q, want %q", tt.file, metadata.Language, tt.want)
+		}
+		if move.Dice != [2]int32{2, 1} || len(move.Analysis) == 0 {
+			t.Errorf("%s: dice %v with %d analysed moves", tt.file, move.Dice, len(move.Analysis))
+		}
+	}
+}
+
+func TestParseXGIDFromReader_DecimalComma(t *testing.T) {
+	input := `XGID=-a-B--E-B-a-dDB--b-bcB---:1:1:1:21:3:6:0:7:10
+
+X to play 21
+
+    1. 4-ply       19/18 14/12                  eq:-0,491
+    2. 4-ply       19/18 3/1                    eq:-0,556 (-0,065)
+`
+
+	move, _, err := ParseXGIDFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseXGIDFromReader() error = %v", err)
+	}
+	if len(move.Analysis) != 2 {
+		t.Fatalf("len(Analysis) = %d, want 2", len(move.Analysis))
+	}
+	if move.Analysis[0].Equity != -0.491 || move.Analysis[1].Equity != -0.556 {
+		t.Errorf("Equity = %v, %v, want -0.491, -0.556", move.Analysis[0].Equity, move.Analysis[1].Equity)
+	}
+	if got := FormatMove(move.Analysis[1].Move); got != "19/18 3/1" {
+		t.Errorf("Analysis[1].Move = %q, want %q", got, "19/18 3/1")
+	}
+
+	cubeInput := `XGID=-aAaBaDaB---bD-b-A-cA-b-b-:0:0:1:00:2:4:0:9:10
+
+X on roll, cube action
+
+Cubeful Equities:
+       No double:     +0,580 (-0,109)
+       Double/Take:   +0,689
+       Double/Pass:   +1,000 (+0,311)
+`
+	cubeMove, _, err := ParseXGIDCubeFromReader(strings.NewReader(cubeInput))
+	if err != nil {
+		t.Fatalf("ParseXGIDCubeFromReader() error = %v", err)
+	}
+	a := cubeMove.Analysis
+	if a.CubefulNoDouble != 0.58 || a.CubefulDoubleTake != 0.689 || a.CubefulDoublePass != 1 {
+		t.Errorf("cubeful equities = %v, %v, %v, want 0.580, 0.689, 1.000", a.CubefulNoDouble, a.CubefulDoubleTake, a.CubefulDoublePass)
+	}
+}
+
+func TestParseXGIDCubeFile_DecimalComma(t *testing.T) {
+	// The double/take fixture as exported with a decimal-comma locale
+	want, _, err := ParseXGIDCubeFile("../test/2025-11-04/03_DT_EN.txt")
+	if err != nil {
+		t.Fatalf("ParseXGIDCubeFile() error = %v", err)
+	}
+	cubeMove, _, err := ParseXGIDCubeFile("../test/decimal_comma/03_DT_EN.txt")
+	if err != nil {
+		t.Fatalf("ParseXGIDCubeFile() error = %v", err)
+	}
+	if *cubeMove.Analysis != *want.Analysis {
+		t.Errorf("Analysis = %+v, want %+v", *cubeMove.Analysis, *want.Analysis)
+	}
+	if cubeMove.Analysis.Player1WinRate == 0 || cubeMove.Analysis.CubelessDouble == 0 {
+		t.Errorf("Analysis = %+v, want the winning chances and cubeless equities", *cubeMove.Analysis)
+	}
+}
+
+func TestParseXGIDFromReader_EquityDiff(t *testing.T) {
+	move, _, err := ParseXGIDFile("../test/2025-11-04/01_checkerPosition_EN.txt")
+	if err != nil {
+		t.Fatalf("ParseXGIDFile() error = %v", err)
+	}
+	if len(move.Analysis) < 4 {
+		t.Fatalf("len(Analysis) = %d, want at least 4", len(move.Analysis))
+	}
+
+	// The best move has no difference; 14/11 shows -0.076 where subtracting
+	// the rounded equities gives -0.077
+	want := []float32{0, -0.065, -0.085, -0.076}
+	for i, w := range want {
+		if got := move.Analysis[i].EquityDiff; got != w {
+			t.Errorf("Analysis[%d].EquityDiff = %v, want %v", i, got, w)
+		}
+	}
+}
+
+func TestParseXGIDFromReader_AbsolutePosition(t *testing.T) {
+	// The board of the XGID -B-CBBB---a---A---ABcbbbd- and of the diagram: X
+	// (player 1) positive on its points 1 to 24, O negative
+	var board [26]int8
+	for point, n := range map[int]int8{1: 2, 3: 3, 4: 2, 5: 2, 6: 2, 10: -1, 14: 1, 18: 1, 19: 2, 20: -3, 21: -2, 22: -2, 23: -2, 24: -4} {
+		board[point] = n
+	}
+	// Checkers moved by the first candidates, from X's points
+	moves := [][][2]int{
+		{{19, 18}, {14, 12}},
+		{{19, 18}, {3, 1}},
+		{{19, 17}, {18, 17}},
+		{{14, 11}},
+	}
+
+	for _, file := range []string{"01_checkerPosition_EN.txt", "01_checkerPosition_FR.txt", "01_checkerPosition_DE.txt"} {
+		move, _, err := ParseXGIDFile(filepath.Join("../test/2025-11-04", file))
+		if err != nil {
+			t.Fatalf("ParseXGIDFile(%s) error = %v", file, err)
+		}
+		if len(move.Analysis) < len(moves) {
+			t.Fatalf("%s: len(Analysis) = %d, want at least %d", file, len(move.Analysis), len(moves))
+		}
+
+		for i, played := range moves {
+			want := board
+			for _, m := range played {
+				want[m[0]]--
+				want[m[1]]++
+			}
+			got := move.Analysis[i].AbsolutePosition
+			if got.Checkers != want {
+				t.Errorf("%s: Analysis[%d].AbsolutePosition.Checkers = %v, want %v", file, i, got.Checkers, want)
+			}
+			// "Cube: 2" at X 3, O 6
+			if got.Cube != 2 || got.Score != [2]int32{3, 6} {
+				t.Errorf("%s: Analysis[%d] cube %d at %v, want 2 at [3 6]", file, i, got.Cube, got.Score)
+			}
+		}
+	}
+
+	// With O on roll the move is read from O's points: its 15 and 5 points are
+	// X's 10 and 20 points
+	input := `XGID=-B-CBBB---a---A---ABcbbbd-:1:-1:-1:21:3:6:0:7:10
+
+O to play 21
+
+    1. 4-ply       15/13 5/4                    eq:+0.491
+`
+	move, _, err := ParseXGIDFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseXGIDFromReader() error = %v", err)
+	}
+	if len(move.Analysis) != 1 {
+		t.Fatalf("len(Analysis) = %d, want 1", len(move.Analysis))
+	}
+	want := board
+	want[10], want[12] = 0, -1
+	want[20], want[21] = -2, -3
+	if got := move.Analysis[0].AbsolutePosition.Checkers; got != want {
+		t.Errorf("O on roll AbsolutePosition.Checkers = %v, want %v", got, want)
+	}
+}
+
+func TestParseXGIDCubeFromReader_PipCountScore(t *testing.T) {
+	input := `XGID=-aAaBaDaB---bD-b-A-cA-b-b-:0:0:1:00:2:4:0:9:10
+
+X:Player1   O:Player2
+ +13-14-15-16-17-18------19-20-21-22-23-24-+
+ | X     O     X    |   | O  X     O     O |
+ +12-11-10--9--8--7-------6--5--4--3--2--1-+
+Pip count  X: 139  O: 156 X-O: 2-4/9
+Cube: 1
+X on roll, cube action
+`
+
+	cubeMove, metadata, err := ParseXGIDCubeFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseXGIDCubeFromReader() error = %v", err)
+	}
+
+	if cubeMove.Position.Score != [2]int32{2, 4} {
+		t.Errorf("Score = %v, want [2, 4]", cubeMove.Position.Score)
+	}
+	if metadata.MatchLength != 9 {
+		t.Errorf("MatchLength = %v, want 9", metadata.MatchLength)
+	}
+}
+
+func TestParseXGIDFromReader_PipCountScore(t *testing.T) {
+	input := `XGID=-b----E-C---eE---c-e----B-:0:0:1:51:3:6:0:13:10
+
+X:marcow777   O:postmanpat
+ +13-14-15-16-17-18------19-20-21-22-23-24-+
+ | X           O    |   | O              X |
+ +12-11-10--9--8--7-------6--5--4--3--2--1-+
+Course  X: 167  O: 167 X-O: 3-6/13
+Videau: 1
+X à jouer 51
+`
+
+	move, metadata, err := ParseXGIDFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseXGIDFromReader() error = %v", err)
+	}
+
+	if move.Position.Score != [2]int32{3, 6} {
+		t.Errorf("Score = %v, want [3, 6]", move.Position.Score)
+	}
+	if metadata.MatchLength != 13 {
+		t.Errorf("MatchLength = %v, want 13", metadata.MatchLength)
+	}
+}
+
+func TestParseXGIDFromReader_XOScore(t *testing.T) {
+	lines := []string{
+		"Pip count  X: 167  O: 167 X-O: 3-6/13",
+		"Cuenta de pips  X: 167  O: 167 X-O: 3-6/13",
+		"X-O: 3-6/13",
+	}
+
+	for _, line := range lines {
+		input := "XGID=-b----E-C---eE---c-e----B-:0:0:1:51:0:0:0:13:10\n" + line + "\nX to play 51\n"
+		move, metadata, err := ParseXGIDFromReader(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("ParseXGIDFromReader() error = %v", err)
+		}
+		if move.Position.Score != [2]int32{3, 6} || metadata.MatchLength != 13 {
+			t.Errorf("%q: Score = %v, MatchLength = %d, want [3 6], 13", line, move.Position.Score, metadata.MatchLength)
+		}
+	}
+}
+
+func TestParseXGIDFromReader_Crawford(t *testing.T) {
+	tests := []struct {
+		name     string
+		xgid     string
+		toPlay   string
+		crawford bool
+	}{
+		{"crawford", "XGID=-b----E-C---eE---c-e----B-:0:0:1:51:6:3:1:7:10", "X to play 51", true},
+		{"crawford O to play", "XGID=-b----E-C---eE---c-e----B-:0:0:1:51:6:3:1:7:10", "O to play 51", true},
+		{"post crawford", "XGID=-b----E-C---eE---c-e----B-:0:0:1:51:6:4:0:7:10", "X to play 51", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			move, _, err := ParseXGIDFromReader(strings.NewReader(tt.xgid + "\n" + tt.toPlay + "\n"))
+			if err != nil {
+				t.Fatalf("ParseXGIDFromReader() error = %v", err)
+			}
+			if move.Position.Crawford != tt.crawford {
+				t.Errorf("Crawford = %v, want %v", move.Position.Crawford, tt.crawford)
+			}
+		})
+	}
+
+	cubeMove, _, err := ParseXGIDCubeFromReader(strings.NewReader(tests[0].xgid + "\nX on roll, cube action\n"))
+	if err != nil {
+		t.Fatalf("ParseXGIDCubeFromReader() error = %v", err)
+	}
+	if !cubeMove.Position.Crawford {
+		t.Errorf("cube Crawford = false, want true")
+	}
+}
+
+func TestParseXGIDFromReader_Money(t *testing.T) {
+	// Jacoby rule in the Crawford field of a money XGID
+	const xgid = "XGID=-b----E-C---eE---c-e----B-:0:0:1:52:0:0:1:0:10"
+
+	move, metadata, err := ParseXGIDFromReader(strings.NewReader(xgid + "\nX to play 52\n"))
+	if err != nil {
+		t.Fatalf("ParseXGIDFromReader() error = %v", err)
+	}
+	if !metadata.IsMoney || metadata.MatchLength != 0 {
+		t.Errorf("IsMoney = %v, MatchLength = %d, want true, 0", metadata.IsMoney, metadata.MatchLength)
+	}
+	if move.Position.Crawford {
+		t.Errorf("Crawford = true in a money game")
+	}
+
+	cubeMove, metadata, err := ParseXGIDCubeFromReader(strings.NewReader(xgid + "\nX on roll, cube action\n"))
+	if err != nil {
+		t.Fatalf("ParseXGIDCubeFromReader() error = %v", err)
+	}
+	if !metadata.IsMoney || cubeMove.Position.Crawford {
+		t.Errorf("cube IsMoney = %v, Crawford = %v, want true, false", metadata.IsMoney, cubeMove.Position.Crawford)
+	}
+
+	_, metadata, err = ParseXGIDFromReader(strings.NewReader("XGID=-b----E-C---eE---c-e----B-:0:0:1:52:0:0:0:7:10\nX to play 52\n"))
+	if err != nil {
+		t.Fatalf("ParseXGIDFromReader() error = %v", err)
+	}
+	if metadata.IsMoney {
+		t.Errorf("IsMoney = true in a 7 point match")
+	}
+}
+
+func TestMatchXGIDs(t *testing.T) {
+	noMove := [8]int32{-1, -1, -1, -1, -1, -1, -1, -1}
+	opening := func(dice [2]int32) []byte {
+		rec := testMoveRecord(1, dice, noMove)
+		putPosition(rec, offMEPositionI, StartingPosition().Checkers)
+		return rec
+	}
+
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		opening([2]int32{3, 1}),
+		testCubeRecord(1, 0, 0, 1),
+		opening([2]int32{3, 1}), // Same position and roll as the first move
+		opening([2]int32{4, 2}),
+		testGameFooter(1, 1),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+
+	xgids := match.XGIDs()
+	if len(xgids) != 3 {
+		t.Fatalf("len(XGIDs()) = %d, want 3: %v", len(xgids), xgids)
+	}
+	want := "XGID=" + PositionToXGID(StartingPosition().Checkers) + ":0:0:1:31:0:0:0:5:10"
+	if xgids[0] != want {
+		t.Errorf("XGIDs()[0] = %s, want %s", xgids[0], want)
+	}
+	for i, dice := range []string{"31", "00", "42"} {
+		components, err := ParseXGID(xgids[i])
+		if err != nil {
+			t.Fatalf("ParseXGID(%s) error = %v", xgids[i], err)
+		}
+		if components.Dice != dice || components.MatchLength != 5 {
+			t.Errorf("XGIDs()[%d] = %s, want dice %s in a 5 point match", i, xgids[i], dice)
+		}
+	}
+}
+
+func FuzzParseXGID(f *testing.F) {
+	files, _ := filepath.Glob("../test/2025-11-04/*.txt")
+	for _, file := range files {
+		if data, err := os.ReadFile(file); err == nil {
+			f.Add(string(data))
+		}
+	}
+	f.Add("XGID=ZZZZZZZZZZZZZZZZZZZZZZZZZZ:0:0:1:51:0:0:0:13:10\nX to play 51\n")
+	f.Add("XGID=-b----E-C---eE---c-e----B-:0:0:1:51:0:0:0:13:10\nX à jouer 5(\n    1. Livre¹      24/23 13/8(                   éq:+0.007\n")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		move, _, err := ParseXGIDFromReader(strings.NewReader(input))
+		if err != nil {
+			return
+		}
+		if move == nil {
+			t.Fatal("ParseXGIDFromReader() returned nil move without error")
+		}
+		player, opponent := checkerCount(move.Position.Checkers)
+		if player > 15 || opponent > 15 {
+			t.Errorf("position has %d/%d checkers: %v", player, opponent, move.Position.Checkers)
+		}
+	})
+}
+
+func TestParseXGIDCubeFromReader_AnalysisMethod(t *testing.T) {
+	for _, file := range []string{"03_DT_EN.txt", "03_DT_FR.txt", "03_DT_DE.txt"} {
+		cubeMove, _, err := ParseXGIDCubeFile(filepath.Join("../test/2025-11-04", file))
+		if err != nil {
+			t.Fatalf("ParseXGIDCubeFile(%s) error = %v", file, err)
+		}
+		if cubeMove.Analysis.Method != AnalysisMethodRoller || cubeMove.Analysis.AnalysisDepth != 0 {
+			t.Errorf("%s: Method = %q, depth = %d, want %q, 0", file, cubeMove.Analysis.Method, cubeMove.Analysis.AnalysisDepth, AnalysisMethodRoller)
+		}
+	}
+
+	input := `XGID=-aAaBaDaB---bD-b-A-cA-b-b-:0:0:1:00:2:4:0:9:10
+
+X:Player1   O:Player2
+Score is X:2 O:4 9 pt.(s) match.
+Cube: 1
+X on roll, cube action
+
+Analyzed in 4-ply
+Player Winning Chances:   61.38% (G:24.55% B:1.88%)
+Opponent Winning Chances: 38.62% (G:13.14% B:0.46%)
+`
+	cubeMove, _, err := ParseXGIDCubeFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseXGIDCubeFromReader() error = %v", err)
+	}
+	if cubeMove.Analysis.Method != AnalysisMethodPly || cubeMove.Analysis.AnalysisDepth != 4 {
+		t.Errorf("Method = %q, depth = %d, want %q, 4", cubeMove.Analysis.Method, cubeMove.Analysis.AnalysisDepth, AnalysisMethodPly)
+	}
+
+	input = strings.Replace(input, "Analyzed in 4-ply", "Analyzed in Rollout", 1)
+	cubeMove, _, err = ParseXGIDCubeFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseXGIDCubeFromReader() error = %v", err)
+	}
+	if cubeMove.Analysis.Method != AnalysisMethodRollout {
+		t.Errorf("Method = %q, want %q", cubeMove.Analysis.Method, AnalysisMethodRollout)
+	}
+
+	tests := []struct {
+		line   string
+		method string
+		depth  int32
+	}{
+		{"Analysé avec 3-plis", AnalysisMethodPly, 3},
+		{"Analysiert in 2 Züge", AnalysisMethodPly, 2},
+		{"Analysé avec Rollout", AnalysisMethodRollout, 0},
+		{"Analysiert in Rollout", AnalysisMethodRollout, 0},
+		{"ロールアウトで分析済み", AnalysisMethodRollout, 0},
+		{"XG Roller+で分析済み", AnalysisMethodRoller, 0},
+		{"Analyzed in Rollout 1296 games", AnalysisMethodRollout, 0},
+		// Comments mentioning a method are not the analysis line
+		{"Worth a Rollout in 4-ply", "", 0},
+		{"Analyzed in a hurry", "", 0},
+	}
+	for _, tt := range tests {
+		input := strings.Replace(input, "Analyzed in Rollout", tt.line, 1)
+		cubeMove, _, err := ParseXGIDCubeFromReader(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("%q: ParseXGIDCubeFromReader() error = %v", tt.line, err)
+		}
+		if cubeMove.Analysis.Method != tt.method || cubeMove.Analysis.AnalysisDepth != tt.depth {
+			t.Errorf("%q: Method = %q, depth = %d, want %q, %d", tt.line, cubeMove.Analysis.Method, cubeMove.Analysis.AnalysisDepth, tt.method, tt.depth)
+		}
+	}
+}
+
+func TestParseXGIDCubeFromReader_CubelessOnly(t *testing.T) {
+	input := `XGID=-aAaBaDaB---bD-b-A-cA-b-b-:0:0:1:00:2:4:0:9:10
+
+X:Player1   O:Player2
+Score is X:2 O:4 9 pt.(s) match.
+Cube: 1
+X on roll, cube action
+
+Analyzed in 2-ply
+Player Winning Chances:   61.38% (G:24.55% B:1.88%)
+Opponent Winning Chances: 38.62% (G:13.14% B:0.46%)
+
+Cubeless Equities: No Double=+0.365, Double=+0.791
+
+Best Cube action: Double / Take
+`
+
+	cubeMove, _, err := ParseXGIDCubeFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseXGIDCubeFromReader() error = %v", err)
+	}
+	if cubeMove.Analysis.CubelessDouble != 0.791 {
+		t.Errorf("CubelessDouble = %v, want 0.791", cubeMove.Analysis.CubelessDouble)
+	}
+	if cubeMove.Analysis.WrongPassTakePercent != 0 {
+		t.Errorf("WrongPassTakePercent = %v, want 0 without cubeful equities", cubeMove.Analysis.WrongPassTakePercent)
+	}
+
+	// A partial cubeful block is not enough either
+	partial := strings.Replace(input, "\nBest", "\nCubeful Equities:\n       Double/Take:   +0.689\n       Double/Pass:   +1.000 (+0.311)\n\nBest", 1)
+	cubeMove, _, err = ParseXGIDCubeFromReader(strings.NewReader(partial))
+	if err != nil {
+		t.Fatalf("ParseXGIDCubeFromReader() error = %v", err)
+	}
+	if cubeMove.Analysis.CubefulDoubleTake != 0.689 {
+		t.Errorf("CubefulDoubleTake = %v, want 0.689", cubeMove.Analysis.CubefulDoubleTake)
+	}
+	if cubeMove.Analysis.WrongPassTakePercent != 0 {
+		t.Errorf("WrongPassTakePercent = %v, want 0 without the no double equity", cubeMove.Analysis.WrongPassTakePercent)
+	}
+}
+
+func TestParseXGIDCubeFromReader_Beaver(t *testing.T) {
+	input := `XGID=-aAaBaDaB---bD-b-A-cA-b-b-:0:0:1:00:0:0:3:0:10
+
+X:Player1   O:Player2
+Money session
+Cube: 1
+X on roll, cube action
+
+Best Cube action: Double / Beaver
+`
+
+	tests := []struct {
+		best string
+		want int32
+	}{
+		{"Double / Beaver", CubeBeaver},
+		{"Redouble / Beaver", CubeBeaver},
+		{"Double / Raccoon", CubeRaccoon},
+		{"Double / Take", CubeTake},
+	}
+	for _, tt := range tests {
+		text := strings.Replace(input, "Double / Beaver", tt.best, 1)
+		cubeMove, _, err := ParseXGIDCubeFromReader(strings.NewReader(text))
+		if err != nil {
+			t.Fatalf("ParseXGIDCubeFromReader() error = %v", err)
+		}
+		if cubeMove.CubeAction != tt.want {
+			t.Errorf("%q: CubeAction = %d, want %d", tt.best, cubeMove.CubeAction, tt.want)
+		}
+	}
+}
+
+func TestFormatPercentEquity(t *testing.T) {
+	percents := map[float32]string{0.6138: "61.38%", 0: "0.00%", 1: "100.00%", 0.0009: "0.09%"}
+	for rate, want := range percents {
+		if got := FormatPercent(rate); got != want {
+			t.Errorf("FormatPercent(%v) = %s, want %s", rate, got, want)
+		}
+	}
+
+	equities := map[float32]string{0.58: "+0.58", -0.491: "-0.49", 0: "+0.00", 1: "+1.00", -1.5: "-1.50"}
+	for eq, want := range equities {
+		if got := FormatEquity(eq); got != want {
+			t.Errorf("FormatEquity(%v) = %s, want %s", eq, got, want)
+		}
+	}
+}
+
+func TestFormatMove(t *testing.T) {
+	tests := []string{
+		"19/18 14/12",
+		"Bar/21 16/10",
+		"8/5(2) 6/5(2)",
+		"Bar/23(2) 13/11(2)",
+		"6/Off 5/Off",
+		"24/23",
+		"",
+	}
+
+	for _, notation := range tests {
+		if got := FormatMove(ParseMoveNotation(notation)); got != notation {
+			t.Errorf("FormatMove(ParseMoveNotation(%q)) = %q", notation, got)
+		}
+	}
+}
+
+func TestPositionToXGID(t *testing.T) {
+	for _, id := range []string{"-B-CBBB---a---A---ABcbbbd-", "----BaC-B---aD--aa-bcbbBbB"} {
+		if got := PositionToXGID(XGIDToPosition(id)); got != id {
+			t.Errorf("PositionToXGID(XGIDToPosition(%q)) = %q", id, got)
+		}
+	}
+}
+
+func TestFormatXGIDText_RoundTrip(t *testing.T) {
+	files, err := filepath.Glob("../test/2025-11-04/01_checkerPosition_*.txt")
+	if err != nil || len(files) == 0 {
+		t.Skip("no fixtures found")
+	}
+
+	for _, file := range files {
+		if fileType, _ := DetectXGIDFileType(file); fileType != "checker" {
+			continue
+		}
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			move, meta, err := ParseXGIDFile(file)
+			if err != nil {
+				t.Fatalf("ParseXGIDFile() error = %v", err)
+			}
+
+			text := FormatXGIDText(move, meta)
+			got, gotMeta, err := ParseXGIDFromReader(strings.NewReader(text))
+			if err != nil {
+				t.Fatalf("ParseXGIDFromReader() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, move) {
+				t.Errorf("round trip = %+v, want %+v\n%s", got, move, text)
+			}
+			// The text is written in English whatever the language of the fixture
+			wantMeta := *meta
+			wantMeta.Language = "en"
+			if *gotMeta != wantMeta {
+				t.Errorf("metadata round trip = %+v, want %+v", *gotMeta, wantMeta)
+			}
+		})
+	}
+}
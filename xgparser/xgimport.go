@@ -26,9 +26,11 @@ package xgparser
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 )
 
 const (
@@ -44,6 +46,10 @@ const (
 
 const XGGameHdrLen = 556
 
+// MinSupportedVersion is the oldest game file version whose record layout is known.
+// Older XG1 files are rejected rather than risk misreading their records.
+const MinSupportedVersion = 8
+
 var SegmentExtensions = []string{
 	"_gdh.bin",
 	".jpg",
@@ -62,6 +68,15 @@ var XGFileMap = map[string]int{
 	"temp.xg":  SegmentXGGameFile,
 }
 
+// segmentTypeForFile returns the segment type of an archived file name.
+// Names are matched case-insensitively, unknown names map to SegmentXGUnknown.
+func segmentTypeForFile(name string) int {
+	if segmentType, ok := XGFileMap[strings.ToLower(name)]; ok {
+		return segmentType
+	}
+	return SegmentXGUnknown
+}
+
 // Segment represents a file segment
 type Segment struct {
 	Type     int
@@ -87,33 +102,105 @@ func (imp *Import) GetFileSegments() ([]*Segment, error) {
 	}
 	defer file.Close()
 
+	return readSegments(file, 0)
+}
+
+// Segment returns the data of the first segment of type t (e.g. SegmentXGRollouts)
+func (imp *Import) Segment(t int) ([]byte, error) {
+	segments, err := imp.GetFileSegments()
+	if err != nil {
+		return nil, err
+	}
+	for _, segment := range segments {
+		if segment.Type == t {
+			return segment.Data, nil
+		}
+	}
+	return nil, fmt.Errorf("no segment of type %d in %s", t, imp.Filename)
+}
+
+// seekableReader returns a reader that can be seeked freely while reading an
+// XG file, positioned at its start: offsets in the file are offsets from the
+// start of r. Files and in-memory readers are returned as-is; any other
+// reader, whose Seek may not be reliable, is read once from its start into
+// memory. maxBytes limits the bytes read into memory, 0 for no limit; a
+// longer stream fails with ErrTooLarge.
+func seekableReader(r io.ReadSeeker, maxBytes int64) (io.ReadSeeker, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	switch r.(type) {
+	case *os.File, *bytes.Reader, *strings.Reader, *io.SectionReader:
+		return r, nil
+	}
+
+	var src io.Reader = r
+	if maxBytes > 0 {
+		src = io.LimitReader(r, maxBytes+1)
+	}
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	if maxBytes > 0 && int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, maxBytes)
+	}
+	return bytes.NewReader(data), nil
+}
+
+// openBareArchive opens the archive of a stripped file that starts directly
+// with it, after reading the GDF header failed with headerErr. The archive is
+// located from the end of the stream so it needs no header. headerErr is
+// returned when the stream is not an archive either.
+func openBareArchive(r io.ReadSeeker, maxBytes int64, headerErr error) (*ZlibArchive, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	archiveObj, err := newLimitedZlibArchive(r, maxBytes)
+	if err != nil && !errors.Is(err, ErrTooLarge) {
+		return nil, fmt.Errorf("not a game data format file: %w", headerErr)
+	}
+	return archiveObj, err
+}
+
+// readSegments extracts all segments from an XG file stream.
+// It is shared by GetFileSegments and ParseXGFromReader so both read files the same way.
+// Files without a GDF header give no SegmentGDFHdr segment.
+// maxBytes limits the total bytes extracted from the archive, 0 for no limit.
+func readSegments(r io.ReadSeeker, maxBytes int64) ([]*Segment, error) {
+	r, err := seekableReader(r, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+
 	var segments []*Segment
 
 	// Read and extract the Game Data Format Header
 	gdfHeader := &GameDataFormatHdrRecord{}
-	err = gdfHeader.FromStream(file)
+	err = gdfHeader.FromStream(r)
 	if err != nil {
-		return nil, fmt.Errorf("not a game data format file: %v", err)
+		archiveObj, err := openBareArchive(r, maxBytes, err)
+		if err != nil {
+			return nil, err
+		}
+		return archiveSegments(archiveObj, segments, nil)
 	}
 
-	// Read the full GDF header segment
-	file.Seek(0, io.SeekStart)
-	gdfData := make([]byte, gdfHeader.HeaderSize)
-	_, err = io.ReadFull(file, gdfData)
+	gdfSegment, err := readGDFHeaderSegment(r, gdfHeader)
 	if err != nil {
 		return nil, err
 	}
+	segments = append(segments, gdfSegment)
 
-	segments = append(segments, &Segment{
-		Type: SegmentGDFHdr,
-		Data: gdfData,
-	})
-
-	// Extract thumbnail if present
+	// Extract thumbnail if present. As in xgdatatools, ThumbnailOffset is
+	// relative to the end of the GDF header (XG writes 0: the image follows it).
 	if gdfHeader.ThumbnailSize > 0 {
-		file.Seek(gdfHeader.ThumbnailOffset, io.SeekCurrent)
+		_, err = r.Seek(int64(gdfHeader.HeaderSize)+gdfHeader.ThumbnailOffset, io.SeekStart)
+		if err != nil {
+			return nil, err
+		}
 		imgData := make([]byte, gdfHeader.ThumbnailSize)
-		_, err = io.ReadFull(file, imgData)
+		_, err = io.ReadFull(r, imgData)
 		if err != nil {
 			return nil, err
 		}
@@ -125,27 +212,94 @@ func (imp *Import) GetFileSegments() ([]*Segment, error) {
 	}
 
 	// Get archive object
-	archiveObj, err := NewZlibArchive(file)
+	archiveObj, err := newLimitedZlibArchive(r, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return archiveSegments(archiveObj, segments, nil)
+}
+
+// readGDFHeaderSegment checks a GDF header read from the start of r against
+// the file size and returns the raw header as a segment
+func readGDFHeaderSegment(r io.ReadSeeker, gdfHeader *GameDataFormatHdrRecord) (*Segment, error) {
+	fileSize, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if err := gdfHeader.Validate(fileSize); err != nil {
+		return nil, err
+	}
+
+	// Read the full GDF header segment
+	r.Seek(0, io.SeekStart)
+	gdfData := make([]byte, gdfHeader.HeaderSize)
+	_, err = io.ReadFull(r, gdfData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Segment{
+		Type: SegmentGDFHdr,
+		Data: gdfData,
+	}, nil
+}
+
+// readGameSegments extracts only the segments used by ParseXG from an XG file
+// stream: the GDF header, the game file and the comments. Thumbnails, rollouts
+// and other archived files are never read. Neither is the rest of the archive
+// for its CRC, which is not checked; each extracted file is still checked
+// against its own CRC.
+func readGameSegments(r io.ReadSeeker) ([]*Segment, error) {
+	var segments []*Segment
+
+	gdfHeader := &GameDataFormatHdrRecord{}
+	headerErr := gdfHeader.FromStream(r)
+	if headerErr == nil {
+		gdfSegment, err := readGDFHeaderSegment(r, gdfHeader)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, gdfSegment)
+	} else if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	archiveObj, err := openZlibArchive(&ZlibArchive{stream: r, skipArchiveCRC: true})
 	if err != nil {
+		if headerErr != nil {
+			// Not a bare archive either
+			return nil, fmt.Errorf("not a game data format file: %w", headerErr)
+		}
 		return nil, err
 	}
 
-	// Process all files in the archive
+	return archiveSegments(archiveObj, segments, func(segmentType int) bool {
+		return segmentType == SegmentXGGameFile || segmentType == SegmentXGComment
+	})
+}
+
+// archiveSegments appends a segment for each file of the archive to segments.
+// When keep is not nil, only the files whose segment type it accepts are read.
+func archiveSegments(archiveObj *ZlibArchive, segments []*Segment, keep func(segmentType int) bool) ([]*Segment, error) {
 	for _, fileRec := range archiveObj.ArcRegistry {
+		segmentType := segmentTypeForFile(fileRec.Name)
+		if keep != nil && !keep(segmentType) {
+			continue
+		}
+
 		data, err := archiveObj.GetArchiveFile(&fileRec)
 		if err != nil {
 			return nil, err
 		}
 
-		segmentType := XGFileMap[fileRec.Name]
-
 		// Verify magic number for game file
 		if segmentType == SegmentXGGameFile {
 			if len(data) > XGGameHdrLen+4 {
 				magicBytes := data[XGGameHdrLen : XGGameHdrLen+4]
 				magic := string(magicBytes)
 				if magic != "DMLI" {
-					return nil, fmt.Errorf("not a valid XG gamefile")
+					return nil, fmt.Errorf("%w: not a valid XG gamefile", ErrNotXGFile)
 				}
 			}
 		}
@@ -160,34 +314,79 @@ func (imp *Import) GetFileSegments() ([]*Segment, error) {
 	return segments, nil
 }
 
+// ParseRolloutFile parses the rollout file segment (temp.xgr) and returns its
+// records, indexed by MoveEntry.RolloutIndexM and CubeEntry.RolloutIndexD
+func ParseRolloutFile(data []byte) ([]*RolloutContextEntry, error) {
+	if len(data)%rolloutContextSize != 0 {
+		return nil, fmt.Errorf("rollout file size %d is not a multiple of %d", len(data), rolloutContextSize)
+	}
+
+	reader := bytes.NewReader(data)
+	rollouts := make([]*RolloutContextEntry, 0, len(data)/rolloutContextSize)
+	for reader.Len() > 0 {
+		rec := &RolloutContextEntry{}
+		if err := rec.FromStream(reader); err != nil {
+			return nil, err
+		}
+		rollouts = append(rollouts, rec)
+	}
+	return rollouts, nil
+}
+
 // ParseGameFile parses the game file segment and returns records
 func ParseGameFile(data []byte, version int32) ([]interface{}, error) {
+	return parseGameFile(data, version, false)
+}
+
+// ParseGameFileAnalysed parses the game file segment as ParseGameFile, reading
+// only the analysed candidates of the moves with MoveEntry.FromStreamAnalysed.
+// It is faster when the rows after NMoves are not needed.
+func ParseGameFileAnalysed(data []byte, version int32) ([]interface{}, error) {
+	return parseGameFile(data, version, true)
+}
+
+func parseGameFile(data []byte, version int32, analysedOnly bool) ([]interface{}, error) {
 	reader := bytes.NewReader(data)
 	var records []interface{}
 
 	for {
-		rec := &GameFileRecord{}
-		err := rec.FromStream(reader, version)
+		record, err := nextGameFileRecord(reader, &version, analysedOnly)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// nextGameFileRecord reads the next record of a game file segment, io.EOF at
+// the end of the segment. version is updated when a HeaderMatchEntry is read.
+func nextGameFileRecord(reader *bytes.Reader, version *int32, analysedOnly bool) (interface{}, error) {
+	for {
+		rec := &GameFileRecord{AnalysedOnly: analysedOnly}
+		err := rec.FromStream(reader, *version)
 		if err != nil {
-			if err == io.EOF {
-				break
-			}
 			// Check if we're at the end
-			if reader.Len() == 0 {
-				break
+			if err == io.EOF || reader.Len() == 0 {
+				return nil, io.EOF
 			}
 			return nil, err
 		}
+		if rec.Record == nil {
+			continue
+		}
 
-		if rec.Record != nil {
-			records = append(records, rec.Record)
-
-			// Update version if this is a HeaderMatchEntry
-			if hme, ok := rec.Record.(*HeaderMatchEntry); ok {
-				version = hme.Version
+		// Update version if this is a HeaderMatchEntry
+		if hme, ok := rec.Record.(*HeaderMatchEntry); ok {
+			if hme.Version < MinSupportedVersion {
+				return nil, fmt.Errorf("unsupported game file version %d, minimum is %d", hme.Version, MinSupportedVersion)
 			}
+			*version = hme.Version
 		}
+		return rec.Record, nil
 	}
-
-	return records, nil
 }
@@ -0,0 +1,279 @@
+//
+//   xgimport_test.go - Unit tests for XG file import
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+// testGDFHeaderSize is the size of a GDF header without thumbnail
+const testGDFHeaderSize = 8232
+
+// buildTestGDFHeader builds a Game Data Format header with the given game name
+func buildTestGDFHeader(gameName string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RGMH") // "HMGR" stored reversed
+	binary.Write(&buf, binary.LittleEndian, int32(1))
+	binary.Write(&buf, binary.LittleEndian, int32(testGDFHeaderSize))
+	binary.Write(&buf, binary.LittleEndian, int64(0))  // ThumbnailOffset
+	binary.Write(&buf, binary.LittleEndian, uint32(0)) // ThumbnailSize
+	buf.Write(make([]byte, 16))                        // GameGUID
+
+	strs := [4][1024]uint16{}
+	copy(strs[0][:], utf16.Encode([]rune(gameName)))
+	binary.Write(&buf, binary.LittleEndian, &strs)
+
+	return buf.Bytes()
+}
+
+// buildTestXGFile builds a complete .xg file from a game file and optional extra archive files
+func buildTestXGFile(gameFile []byte, extra ...testArchiveFile) []byte {
+	files := append([]testArchiveFile{{name: "temp.xg", data: gameFile}}, extra...)
+	return append(buildTestGDFHeader("eXtreme Gammon 2.19"), buildTestArchive(files...)...)
+}
+
+// writeTestXGFile writes data to a file in a temporary directory and returns its path
+func writeTestXGFile(tb testing.TB, data []byte) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "test.xg")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+// testGameFile joins records into the content of a temp.xg game file
+func testGameFile(records ...[]byte) []byte {
+	return bytes.Join(records, nil)
+}
+
+func TestGetFileSegments(t *testing.T) {
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0), testGameFooter(-1, 1))
+	path := writeTestXGFile(t, buildTestXGFile(gameFile))
+
+	segments, err := NewImport(path).GetFileSegments()
+	if err != nil {
+		t.Fatalf("GetFileSegments() error = %v", err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("segment count = %d, want 2", len(segments))
+	}
+	if segments[0].Type != SegmentGDFHdr || len(segments[0].Data) != testGDFHeaderSize {
+		t.Errorf("segment 0 = type %d, %d bytes", segments[0].Type, len(segments[0].Data))
+	}
+	if segments[1].Type != SegmentXGGameFile || !bytes.Equal(segments[1].Data, gameFile) {
+		t.Errorf("segment 1 = type %d, %d bytes", segments[1].Type, len(segments[1].Data))
+	}
+}
+
+func TestParseXGFromReader_Headerless(t *testing.T) {
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0), testGameFooter(-1, 1))
+	data := buildTestArchive(testArchiveFile{name: "temp.xg", data: gameFile})
+
+	match, err := ParseXGFromReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseXGFromReader() error = %v", err)
+	}
+	if match.Metadata.Player1Name != "Alice" || len(match.Games) != 1 || match.Games[0].Winner != -1 {
+		t.Errorf("match = %+v", match)
+	}
+
+	segments, err := NewImport(writeTestXGFile(t, data)).GetFileSegments()
+	if err != nil {
+		t.Fatalf("GetFileSegments() error = %v", err)
+	}
+	if len(segments) != 1 || segments[0].Type != SegmentXGGameFile {
+		t.Errorf("segments = %d, want only the game file", len(segments))
+	}
+
+	if _, err := Parse(bytes.NewReader(data), WithMetadataOnly()); err != nil {
+		t.Errorf("Parse(WithMetadataOnly()) error = %v", err)
+	}
+}
+
+func TestGetFileSegments_FileNames(t *testing.T) {
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5))
+	path := writeTestXGFile(t, buildTestXGFile(gameFile,
+		testArchiveFile{name: "TEMP.XGC", data: []byte("comments")},
+		testArchiveFile{name: "notes.txt", data: []byte("stray file")},
+	))
+
+	segments, err := NewImport(path).GetFileSegments()
+	if err != nil {
+		t.Fatalf("GetFileSegments() error = %v", err)
+	}
+
+	types := make(map[string]int)
+	for _, segment := range segments[1:] {
+		types[segment.Filename] = segment.Type
+	}
+	if types["TEMP.XGC"] != SegmentXGComment {
+		t.Errorf("TEMP.XGC type = %d, want SegmentXGComment", types["TEMP.XGC"])
+	}
+	if types["notes.txt"] != SegmentXGUnknown {
+		t.Errorf("notes.txt type = %d, want SegmentXGUnknown", types["notes.txt"])
+	}
+}
+
+func TestGetFileSegments_StrayFile(t *testing.T) {
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0), testGameFooter(1, 1))
+	path := writeTestXGFile(t, buildTestXGFile(gameFile,
+		testArchiveFile{name: "desktop.ini", data: []byte("stray file")},
+	))
+
+	segments, err := NewImport(path).GetFileSegments()
+	if err != nil {
+		t.Fatalf("GetFileSegments() error = %v", err)
+	}
+	gdfHeaders := 0
+	for _, segment := range segments {
+		if segment.Type == SegmentGDFHdr {
+			gdfHeaders++
+		}
+	}
+	if gdfHeaders != 1 {
+		t.Errorf("GDF header segments = %d, want 1", gdfHeaders)
+	}
+
+	match, err := ParseXGFromFile(path)
+	if err != nil {
+		t.Fatalf("ParseXGFromFile() error = %v", err)
+	}
+	if match.Metadata.Player1Name != "Alice" || len(match.Games) != 1 {
+		t.Errorf("ParseXGFromFile() = %+v", match)
+	}
+}
+
+func TestImportSegment(t *testing.T) {
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0), testGameFooter(1, 1))
+	imp := NewImport(writeTestXGFile(t, buildTestXGFile(gameFile)))
+
+	data, err := imp.Segment(SegmentXGGameFile)
+	if err != nil {
+		t.Fatalf("Segment(SegmentXGGameFile) error = %v", err)
+	}
+	if len(data) < XGGameHdrLen+4 || string(data[XGGameHdrLen:XGGameHdrLen+4]) != "DMLI" {
+		t.Errorf("game file segment does not have the DMLI magic at XGGameHdrLen")
+	}
+
+	if _, err := imp.Segment(SegmentXGRollouts); err == nil {
+		t.Errorf("Segment(SegmentXGRollouts) error = nil for a file without rollouts")
+	}
+}
+
+func TestGetFileSegments_Thumbnail(t *testing.T) {
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0), testGameFooter(-1, 1))
+	image := []byte("\xff\xd8\xff\xe0 thumbnail \xff\xd9")
+	padding := []byte("pad!")
+
+	// The thumbnail offset counts from the end of the GDF header
+	header := buildTestGDFHeader("eXtreme Gammon 2.19")
+	binary.LittleEndian.PutUint64(header[12:], uint64(len(padding)))
+	binary.LittleEndian.PutUint32(header[20:], uint32(len(image)))
+	data := bytes.Join([][]byte{header, padding, image, buildTestArchive(testArchiveFile{name: "temp.xg", data: gameFile})}, nil)
+
+	fromFile, err := NewImport(writeTestXGFile(t, data)).GetFileSegments()
+	if err != nil {
+		t.Fatalf("GetFileSegments() error = %v", err)
+	}
+	// ParseXGFromReader reads its segments with readSegments
+	fromReader, err := readSegments(bytes.NewReader(data), 0)
+	if err != nil {
+		t.Fatalf("readSegments() error = %v", err)
+	}
+
+	for name, segments := range map[string][]*Segment{"GetFileSegments": fromFile, "ParseXGFromReader": fromReader} {
+		if len(segments) != 3 || segments[1].Type != SegmentGDFImage {
+			t.Fatalf("%s: segments = %d, want header, image and game file", name, len(segments))
+		}
+		if !bytes.Equal(segments[1].Data, image) {
+			t.Errorf("%s: image = %q, want %q", name, segments[1].Data, image)
+		}
+	}
+
+	if _, err := ParseXGFromReader(bytes.NewReader(data)); err != nil {
+		t.Errorf("ParseXGFromReader() error = %v", err)
+	}
+}
+
+func TestGetFileSegments_InvalidThumbnail(t *testing.T) {
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0), testGameFooter(-1, 1))
+
+	tests := []struct {
+		name   string
+		offset int64
+		size   uint32
+	}{
+		{"huge size", 0, 0xFFFFFFF0},
+		{"past end", 1 << 20, 16},
+		{"negative offset", -testGDFHeaderSize, 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildTestXGFile(gameFile)
+			binary.LittleEndian.PutUint64(data[12:], uint64(tt.offset))
+			binary.LittleEndian.PutUint32(data[20:], tt.size)
+
+			if _, err := NewImport(writeTestXGFile(t, data)).GetFileSegments(); err == nil {
+				t.Errorf("GetFileSegments() error = nil, want invalid thumbnail")
+			}
+			if _, err := ParseXGFromReader(bytes.NewReader(data)); err == nil {
+				t.Errorf("ParseXGFromReader() error = nil, want invalid thumbnail")
+			}
+		})
+	}
+}
+
+func TestParseGameFile_MinSupportedVersion(t *testing.T) {
+	for _, tt := range []struct {
+		version int32
+		wantErr bool
+	}{
+		{5, true},
+		{MinSupportedVersion - 1, true},
+		{MinSupportedVersion, false},
+		{30, false},
+	} {
+		header := testMatchHeader("Alice", "Bob", 5)
+		putInt32(header, offHMVersion, tt.version)
+		records, err := ParseGameFile(testGameFile(header, testGameHeader(1, 0, 0)), -1)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("version %d: ParseGameFile() error = %v, wantErr %v", tt.version, err, tt.wantErr)
+		}
+		if err == nil && len(records) != 2 {
+			t.Errorf("version %d: record count = %d, want 2", tt.version, len(records))
+		}
+	}
+}
+
+func TestNotXGFile(t *testing.T) {
+	path := writeTestXGFile(t, []byte("XGID=-b----E-C---eE---c-e----B-:0:0:1:52:0:0:0:13:10\n"))
+
+	if _, err := NewImport(path).GetFileSegments(); !errors.Is(err, ErrNotXGFile) {
+		t.Errorf("GetFileSegments() error = %v, want ErrNotXGFile", err)
+	}
+	if _, err := ParseXGFromFile(path); !errors.Is(err, ErrNotXGFile) {
+		t.Errorf("ParseXGFromFile() error = %v, want ErrNotXGFile", err)
+	}
+	if _, err := PeekMatchHeader(path); !errors.Is(err, ErrNotXGFile) {
+		t.Errorf("PeekMatchHeader() error = %v, want ErrNotXGFile", err)
+	}
+
+	// A valid archive whose game file lacks the magic number
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5))
+	copy(gameFile[offHMMagic:], "XXXX")
+	path = writeTestXGFile(t, buildTestXGFile(gameFile))
+	if _, err := NewImport(path).GetFileSegments(); !errors.Is(err, ErrNotXGFile) {
+		t.Errorf("GetFileSegments() bad magic error = %v, want ErrNotXGFile", err)
+	}
+}
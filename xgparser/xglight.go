@@ -22,85 +22,236 @@ package xgparser
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
 )
 
 // MatchMetadata contains essential match information
 // This structure is used for both XG binary files and XGID position text files
 type MatchMetadata struct {
-	Player1Name    string `json:"player1_name"`
-	Player2Name    string `json:"player2_name"`
-	Location       string `json:"location"`
-	Event          string `json:"event"`
-	Round          string `json:"round"`
-	DateTime       string `json:"date_time"`
-	MatchLength    int32  `json:"match_length"`
-	EngineVersion  int32  `json:"engine_version"`  // File format version (e.g., 30) - XG binary only
-	ProductVersion string `json:"product_version"` // XG product version (e.g., "eXtreme Gammon 2.19.1")
-	MET            string `json:"met"`             // Match equity table (e.g., "Kazaross XG2") - XGID only
+	Player1Name    string     `json:"player1_name"`
+	Player2Name    string     `json:"player2_name"`
+	Location       string     `json:"location"`
+	Event          string     `json:"event"`
+	Round          string     `json:"round"`
+	DateTime       string     `json:"date_time"`
+	MatchLength    int32      `json:"match_length"`
+	IsMoney        bool       `json:"is_money,omitempty"`       // Money game or session, the match length is 0 in XGID
+	CrawfordRule   bool       `json:"crawford_rule,omitempty"`  // Match played with the Crawford rule - XG binary only
+	EngineVersion  int32      `json:"engine_version"`           // File format version (e.g., 30) - XG binary only
+	Magic          uint32     `json:"magic,omitempty"`          // Magic number of the match header ("DMLI" little-endian) - XG binary only
+	ProductVersion string     `json:"product_version"`          // XG product version (e.g., "eXtreme Gammon 2.19.1")
+	MET            string     `json:"met"`                      // Match equity table (e.g., "Kazaross XG2") - XGID only
+	Language       string     `json:"language,omitempty"`       // ISO 639-1 code detected from the "to play" line (e.g., "fr") - XGID only
+	HeaderComment  string     `json:"header_comment,omitempty"` // Comment before the match - XG binary only
+	FooterComment  string     `json:"footer_comment,omitempty"` // Comment after the match - XG binary only
+	GameID         int32      `json:"game_id,omitempty"`        // Identifier of the match in XG, for linking and deduplication - XG binary only
+	SiteID         int32      `json:"site_id,omitempty"`        // Online site the match was played on - XG binary only
+	Currency       int32      `json:"currency,omitempty"`       // Currency of the money fields - XG binary only
+	WinMoney       float32    `json:"win_money,omitempty"`      // Amount won for a win - XG binary only
+	LoseMoney      float32    `json:"lose_money,omitempty"`     // Amount lost for a loss - XG binary only
+	FeeMoney       float32    `json:"fee_money,omitempty"`      // Fee charged by the site - XG binary only
+	TableStake     int32      `json:"table_stake,omitempty"`    // Table stake - XG binary only
+	FinalScore     [2]int32   `json:"final_score"`              // Score at the end of the match, from the last match footer - XG binary only
+	FinalElo       [2]float64 `json:"final_elo"`                // Ratings of the players after the match, from the last match footer - XG binary only
 }
 
+// Players are identified by a sign, as in the XG records (ActiveP, Winner,
+// WinnerM): Player1 for the first player of the metadata, Player2 for the
+// second. ActivePlayer, Winner and the cube owner all use these values.
+const (
+	Player1 int32 = 1
+	Player2 int32 = -1
+)
+
 // Position represents a backgammon position
 type Position struct {
 	Checkers [26]int8 `json:"checkers"` // Position of checkers
 	Cube     int32    `json:"cube"`     // Cube value
 	CubePos  int32    `json:"cube_pos"` // Cube position (0=center, 1=player1, -1=player2)
 	Score    [2]int32 `json:"score"`    // Match score [player1, player2]
+	Crawford bool     `json:"crawford"` // Crawford game (XGID only)
 }
 
 // CheckerAnalysis contains analysis for a single checker move
 // Note: player1/player2 in analysis refer to the player on roll (active_player) and their opponent,
 // not the players in player1_name/player2_name metadata
 type CheckerAnalysis struct {
-	Position          Position `json:"position"`            // Resulting position
-	Move              [8]int8  `json:"move"`                // The move itself (25=bar, 1-24=points, -2=bear off, -1=unused)
-	Player1WinRate    float32  `json:"player1_win_rate"`    // Win rate for player on roll (1 - eval[2])
-	Player1GammonRate float32  `json:"player1_gammon_rate"` // Gammon rate for player on roll (eval[4])
-	Player1BgRate     float32  `json:"player1_bg_rate"`     // Backgammon rate for player on roll (eval[5])
-	Player2GammonRate float32  `json:"player2_gammon_rate"` // Gammon rate for opponent (eval[1])
-	Player2BgRate     float32  `json:"player2_bg_rate"`     // Backgammon rate for opponent (eval[0])
-	Equity            float32  `json:"equity"`              // eval[6] - normalized equity
-	AnalysisDepth     int16    `json:"analysis_depth"`      // EvalLevel.Level
+	Position          Position         `json:"position"`              // Resulting position
+	AbsolutePosition  Position         `json:"absolute_position"`     // Resulting position in the absolute frame of Position.Absolute
+	Move              [8]int8          `json:"move"`                  // The move itself (25=bar, 1-24=points, -2=bear off, -1=unused)
+	Player1WinRate    float32          `json:"player1_win_rate"`      // Win rate for player on roll (1 - eval[2])
+	Player1GammonRate float32          `json:"player1_gammon_rate"`   // Gammon rate for player on roll (eval[4])
+	Player1BgRate     float32          `json:"player1_bg_rate"`       // Backgammon rate for player on roll (eval[5])
+	Player2GammonRate float32          `json:"player2_gammon_rate"`   // Gammon rate for opponent (eval[1])
+	Player2BgRate     float32          `json:"player2_bg_rate"`       // Backgammon rate for opponent (eval[0])
+	Equity            float32          `json:"equity"`                // eval[6] - normalized equity
+	EquityDiff        float32          `json:"equity_diff,omitempty"` // Equity difference with the best candidate as XG displays it (XGID only)
+	AnalysisDepth     int16            `json:"analysis_depth"`        // EvalLevel.Level
+	IsDouble          bool             `json:"is_double,omitempty"`   // EvalLevel.IsDouble: evaluated as a double
+	Rollout           *RolloutSettings `json:"rollout,omitempty"`     // Settings of the rollout of this candidate - XG binary only
+}
+
+// FromOpponentPerspective returns the analysis as seen by the opponent of the
+// player on roll: the win, gammon and backgammon rates of both sides are
+// swapped and the equity is negated. Position and Move are left unchanged.
+func (a CheckerAnalysis) FromOpponentPerspective() CheckerAnalysis {
+	a.Player1WinRate = 1 - a.Player1WinRate
+	a.Player1GammonRate, a.Player2GammonRate = a.Player2GammonRate, a.Player1GammonRate
+	a.Player1BgRate, a.Player2BgRate = a.Player2BgRate, a.Player1BgRate
+	a.Equity = -a.Equity
+	return a
+}
+
+// EquityFor returns the equity of the analysis from the point of view of a
+// player of the match metadata, Player1 or Player2. Equity is that
+// of the player on roll, activePlayer as in CheckerMove.ActivePlayer, so it
+// is negated for the other player.
+func (a CheckerAnalysis) EquityFor(player int32, activePlayer int32) float32 {
+	if player == activePlayer {
+		return a.Equity
+	}
+	return -a.Equity
+}
+
+// MovePairs returns the from/to pairs of Move without the unused slots, with
+// 25 for the bar and 0 for bearing off
+func (a CheckerAnalysis) MovePairs() [][2]int {
+	var pairs [][2]int
+	for i := 0; i < 8 && a.Move[i] != -1; i += 2 {
+		to := int(a.Move[i+1])
+		if to == -2 {
+			to = 0
+		}
+		pairs = append(pairs, [2]int{int(a.Move[i]), to})
+	}
+	return pairs
+}
+
+// PositionPlausible reports whether the resulting position follows from
+// before, the position the move is played from, to catch analyses whose Move
+// was applied in the wrong frame. Both positions are seen from the player on
+// roll. Each side keeps its checkers: the opponent's all stay on the board,
+// hit ones on its bar, and the player's are on the board or borne off by
+// Move. Each bar only holds the checkers of its owner.
+func (a CheckerAnalysis) PositionPlausible(before Position) bool {
+	if a.Position.Checkers[25] < 0 || a.Position.Checkers[0] > 0 {
+		return false
+	}
+
+	off := 0
+	for i := 1; i < 8; i += 2 {
+		if a.Move[i-1] != -1 && a.Move[i] == -2 {
+			off++
+		}
+	}
+	player, opponent := checkerCount(a.Position.Checkers)
+	wasPlayer, wasOpponent := checkerCount(before.Checkers)
+	return player+off == wasPlayer && opponent == wasOpponent
 }
 
 // CubeAnalysis contains analysis for a cube decision
 // Note: For cube decisions, eval is always from active player's perspective
 // player1 in analysis = player on roll, player2 = opponent (no swap needed)
 type CubeAnalysis struct {
-	Player1WinRate       float32 `json:"player1_win_rate"`        // Win rate for player on roll - eval[2]
-	Player1GammonRate    float32 `json:"player1_gammon_rate"`     // Gammon rate for player on roll - eval[1]
-	Player1BgRate        float32 `json:"player1_bg_rate"`         // Backgammon rate for player on roll - eval[0]
-	Player2GammonRate    float32 `json:"player2_gammon_rate"`     // Gammon rate for opponent - eval[4]
-	Player2BgRate        float32 `json:"player2_bg_rate"`         // Backgammon rate for opponent - eval[5]
-	CubelessNoDouble     float32 `json:"cubeless_no_double"`      // eval[6]
-	CubelessDouble       float32 `json:"cubeless_double"`         // eval[7] (if available)
-	CubefulNoDouble      float32 `json:"cubeful_no_double"`       // equB
-	CubefulDoubleTake    float32 `json:"cubeful_double_take"`     // equDouble
-	CubefulDoublePass    float32 `json:"cubeful_double_pass"`     // equDrop
-	WrongPassTakePercent float32 `json:"wrong_pass_take_percent"` // Calculated metric
-	AnalysisDepth        int32   `json:"analysis_depth"`          // Level
+	Player1WinRate       float32          `json:"player1_win_rate"`        // Win rate for player on roll (1 - eval[2])
+	Player1GammonRate    float32          `json:"player1_gammon_rate"`     // Gammon rate for player on roll (eval[4])
+	Player1BgRate        float32          `json:"player1_bg_rate"`         // Backgammon rate for player on roll (eval[5])
+	Player2GammonRate    float32          `json:"player2_gammon_rate"`     // Gammon rate for opponent (eval[1])
+	Player2BgRate        float32          `json:"player2_bg_rate"`         // Backgammon rate for opponent (eval[0])
+	CubelessNoDouble     float32          `json:"cubeless_no_double"`      // eval[6]
+	CubelessDouble       float32          `json:"cubeless_double"`         // 2 * eval[6], not stored by XG
+	CubefulNoDouble      float32          `json:"cubeful_no_double"`       // equB
+	CubefulDoubleTake    float32          `json:"cubeful_double_take"`     // equDouble
+	CubefulDoublePass    float32          `json:"cubeful_double_pass"`     // equDrop
+	WrongPassTakePercent float32          `json:"wrong_pass_take_percent"` // Calculated metric
+	TooGood              bool             `json:"too_good,omitempty"`      // Too good to double: No Double beats Double/Pass
+	AnalysisDepth        int32            `json:"analysis_depth"`          // Level
+	Method               string           `json:"method,omitempty"`        // AnalysisMethodPly, AnalysisMethodRoller or AnalysisMethodRollout (XGID text only)
+	Rollout              *RolloutSettings `json:"rollout,omitempty"`       // Settings of the rollout of this decision - XG binary only
 }
 
+// RolloutSettings describes how an analysis was rolled out, to judge how
+// trustworthy it is. It is read from the rollout file of XG binary files.
+type RolloutSettings struct {
+	Trials            int32 `json:"trials"`                   // Trials played
+	MinTrials         int32 `json:"min_trials"`               // Minimum number of trials
+	MaxTrials         int32 `json:"max_trials"`               // Maximum number of trials
+	Truncated         bool  `json:"truncated"`                // Trials are truncated
+	TruncationPly     int32 `json:"truncation_ply,omitempty"` // Ply the trials are truncated at, when Truncated
+	VarianceReduction bool  `json:"variance_reduction"`       // Variance reduction was used
+	Cubeless          bool  `json:"cubeless"`                 // Cubeless rollout
+	CheckerLevel      int32 `json:"checker_level"`            // Level of the checker plays (RolloutContextEntry.Level1)
+	CubeLevel         int32 `json:"cube_level"`               // Level of the cube decisions (RolloutContextEntry.Level1C)
+	Seed              int32 `json:"seed"`                     // Random seed
+}
+
+// Analysis methods reported for cube decisions in XGID text files
+const (
+	AnalysisMethodPly     = "ply"     // N-ply evaluation, depth in AnalysisDepth
+	AnalysisMethodRoller  = "roller"  // XG Roller, Roller+ or Roller++
+	AnalysisMethodRollout = "rollout" // Full rollout
+)
+
 // CheckerMove represents a checker play decision
 type CheckerMove struct {
-	Position     Position          `json:"position"`      // Position before the move
-	ActivePlayer int32             `json:"active_player"` // Player making the move
-	Dice         [2]int32          `json:"dice"`          // Dice rolled
-	PlayedMove   [8]int32          `json:"played_move"`   // The move that was played (25=bar, 1-24=points, -2=bear off, -1=unused)
-	Analysis     []CheckerAnalysis `json:"analysis"`      // Analysis of possible moves
+	Position       Position          `json:"position"`        // Position before the move
+	ActivePlayer   int32             `json:"active_player"`   // Player making the move
+	Dice           [2]int32          `json:"dice"`            // Dice rolled
+	PlayedMove     [8]int32          `json:"played_move"`     // The move that was played (25=bar, 1-24=points, -2=bear off, -1=unused)
+	Invalid        bool              `json:"invalid"`         // XG flagged the move as invalid (e.g. no legal play)
+	AnalysisLevel  int32             `json:"analysis_level"`  // Level XG analyzed the move at (MoveEntry.AnalyzeM) - XG binary only
+	ComputerChoice int32             `json:"computer_choice"` // Index of XG's choice in the analysis (MoveEntry.CompChoice) - XG binary only
+	InitialEquity  float32           `json:"initial_equity"`  // Equity before the roll (MoveEntry.InitEq) - XG binary only
+	EquityLost     float32           `json:"equity_lost"`     // Equity lost by the played move (MoveEntry.ErrMove) - XG binary only
+	Luck           float32           `json:"luck"`            // Equity gained by the roll, negative for a bad roll (MoveEntry.ErrLuck) - XG binary only
+	Analysis       []CheckerAnalysis `json:"analysis"`        // Analysis of possible moves
+}
+
+// SortedDice returns the dice with the higher die first, as they are usually
+// written. Dice is left in the order rolled.
+func (m *CheckerMove) SortedDice() [2]int32 {
+	if m.Dice[1] > m.Dice[0] {
+		return [2]int32{m.Dice[1], m.Dice[0]}
+	}
+	return m.Dice
 }
 
 // CubeMove represents a cube decision
 type CubeMove struct {
-	Position     Position      `json:"position"`      // Position when cube decision was made
-	ActivePlayer int32         `json:"active_player"` // Player making the decision
-	CubeAction   int32         `json:"cube_action"`   // 0=no double, 1=double, 2=take, 3=pass
-	Analysis     *CubeAnalysis `json:"analysis"`      // Analysis of cube decision
+	Position       Position      `json:"position"`        // Position when cube decision was made
+	ActivePlayer   int32         `json:"active_player"`   // Player making the decision
+	CubeAction     int32         `json:"cube_action"`     // CubeNoDouble, CubeDouble, CubeTake, CubePass, CubeBeaver or CubeRaccoon
+	AnalysisLevel  int32         `json:"analysis_level"`  // Level XG analyzed the decision at (CubeEntry.AnalyzeC) - XG binary only
+	ComputerChoice int32         `json:"computer_choice"` // XG's cube choice (CubeEntry.CompChoiceD) - XG binary only
+	Analysis       *CubeAnalysis `json:"analysis"`        // Analysis of cube decision
 }
 
+// CubeAction is a cube decision, one of the Cube constants
+type CubeAction = int32
+
+// Cube actions stored in CubeMove.CubeAction
+const (
+	CubeNoDouble CubeAction = iota // No double
+	CubeDouble                     // Double, response unknown
+	CubeTake                       // Double/Take
+	CubePass                       // Double/Pass
+	CubeBeaver                     // Double/Beaver: take and immediately redouble
+	CubeRaccoon                    // Double/Beaver/Raccoon
+)
+
 // Move represents either a checker or cube move
 type Move struct {
 	MoveType    string       `json:"move_type"` // "checker" or "cube"
@@ -111,17 +262,240 @@ type Move struct {
 
 // Game represents a single game within a match
 type Game struct {
-	GameNumber   int32    `json:"game_number"`
-	InitialScore [2]int32 `json:"initial_score"` // Score at start of game
-	Moves        []Move   `json:"moves"`
-	Winner       int32    `json:"winner"` // -1=player1, 1=player2, 0=not completed
-	PointsWon    int32    `json:"points_won"`
+	GameNumber      int32    `json:"game_number"`
+	InitialScore    [2]int32 `json:"initial_score"`    // Score at start of game
+	InitialPosition [26]int8 `json:"initial_position"` // Starting layout (PosInit), not the standard one in variants such as Nackgammon
+	Moves           []Move   `json:"moves"`
+	Winner          int32    `json:"winner"` // Player1 (1), Player2 (-1), 0=not completed
+	PointsWon       int32    `json:"points_won"`
+	FinalCube       int32    `json:"final_cube"`                  // Cube value at the end of the game
+	CubeTurns       int32    `json:"cube_turns"`                  // Number of accepted doubles
+	InProgress      bool     `json:"in_progress,omitempty"`       // Game saved unfinished (HeaderGameEntry.InProgress)
+	ResignError     float32  `json:"resign_error,omitempty"`      // Equity lost by the resignation offer (FooterGameEntry.ErrResign)
+	TakeResignError float32  `json:"take_resign_error,omitempty"` // Equity lost by the response to the resignation (FooterGameEntry.ErrTakeResign)
+	HeaderComment   string   `json:"header_comment,omitempty"`    // Comment before the game
+	FooterComment   string   `json:"footer_comment,omitempty"`    // Comment after the game
+}
+
+// ScoreAfter returns the match score at the end of the game: InitialScore with
+// PointsWon added to the winner's side. The score is unchanged when the game
+// was not completed.
+func (g *Game) ScoreAfter() [2]int32 {
+	score := g.InitialScore
+	switch g.Winner {
+	case Player1:
+		score[0] += g.PointsWon
+	case Player2:
+		score[1] += g.PointsWon
+	}
+	return score
+}
+
+// LuckByPlayer returns the total luck of the rolls of player 1 and player 2
+// in the game, the sum of CheckerMove.Luck over the checker moves of each
+// player. A positive total means the player rolled better than average.
+func (g *Game) LuckByPlayer() (p1, p2 float32) {
+	for _, move := range g.Moves {
+		if move.CheckerMove == nil {
+			continue
+		}
+		switch move.CheckerMove.ActivePlayer {
+		case Player1:
+			p1 += move.CheckerMove.Luck
+		case Player2:
+			p2 += move.CheckerMove.Luck
+		}
+	}
+	return p1, p2
 }
 
 // Match represents the complete match structure
 type Match struct {
 	Metadata MatchMetadata `json:"metadata"`
 	Games    []Game        `json:"games"`
+	Winner   int32         `json:"winner"` // Match winner from the match footer: Player1 (1), Player2 (-1), 0=not completed
+
+	ErrorSummaries []ErrorSummary `json:"error_summaries,omitempty"` // Error rates of player 1 and player 2 - match text report only
+}
+
+// Result returns the name of the match winner and the final score. The score
+// is the one after the last game; the winner comes from the match footer, or
+// from the score reaching the match length. winner is empty while the match
+// is not completed.
+func (m *Match) Result() (winner string, score [2]int32) {
+	side, score := m.winnerSide()
+	switch side {
+	case Player1:
+		winner = m.Metadata.Player1Name
+	case Player2:
+		winner = m.Metadata.Player2Name
+	}
+	return winner, score
+}
+
+// winnerSide returns the match winner, Player1 or Player2, 0 while the match
+// is not completed, and the score after the last game
+func (m *Match) winnerSide() (side int32, score [2]int32) {
+	if len(m.Games) > 0 {
+		score = m.Games[len(m.Games)-1].ScoreAfter()
+	}
+
+	side = m.Winner
+	if side == 0 && m.Metadata.MatchLength > 0 {
+		if score[0] >= m.Metadata.MatchLength {
+			side = Player1
+		} else if score[1] >= m.Metadata.MatchLength {
+			side = Player2
+		}
+	}
+	return side, score
+}
+
+// IsComplete reports whether the match was played to the end: a player
+// reached MatchLength points or the file has a match footer with a winner.
+// Matches saved while a game is in progress are not complete, and neither are
+// money sessions without a match footer.
+func (m *Match) IsComplete() bool {
+	side, _ := m.winnerSide()
+	return side != 0
+}
+
+// Clone returns a deep copy of the match: games, moves and analyses are
+// copied, so the copy can be modified without changing m. Nil slices stay nil
+// and empty ones empty, so both marshal to the same JSON.
+func (m *Match) Clone() *Match {
+	clone := *m
+	if m.Games != nil {
+		clone.Games = make([]Game, len(m.Games))
+	}
+	for i, g := range m.Games {
+		if g.Moves != nil {
+			g.Moves = append(make([]Move, 0, len(g.Moves)), g.Moves...)
+		}
+		for j, move := range g.Moves {
+			if move.CheckerMove != nil {
+				checkerMove := *move.CheckerMove
+				if checkerMove.Analysis != nil {
+					checkerMove.Analysis = append(make([]CheckerAnalysis, 0, len(checkerMove.Analysis)), checkerMove.Analysis...)
+				}
+				for k, a := range checkerMove.Analysis {
+					if a.Rollout != nil {
+						rollout := *a.Rollout
+						checkerMove.Analysis[k].Rollout = &rollout
+					}
+				}
+				g.Moves[j].CheckerMove = &checkerMove
+			}
+			if move.CubeMove != nil {
+				cubeMove := *move.CubeMove
+				if cubeMove.Analysis != nil {
+					analysis := *cubeMove.Analysis
+					if analysis.Rollout != nil {
+						rollout := *analysis.Rollout
+						analysis.Rollout = &rollout
+					}
+					cubeMove.Analysis = &analysis
+				}
+				g.Moves[j].CubeMove = &cubeMove
+			}
+		}
+		clone.Games[i] = g
+	}
+	if m.ErrorSummaries != nil {
+		clone.ErrorSummaries = append([]ErrorSummary(nil), m.ErrorSummaries...)
+	}
+	return &clone
+}
+
+// IsPostCrawford reports whether the game at index gameIdx of Games is played
+// after the Crawford game, when the trailer doubles freely and the leader
+// should drop. The Crawford game is the first one starting with a player one
+// point away from winning, so every later game is post-Crawford. It is false
+// for money games and matches played without the Crawford rule.
+func (m *Match) IsPostCrawford(gameIdx int) bool {
+	length := m.Metadata.MatchLength
+	if !m.Metadata.CrawfordRule || length <= 1 || gameIdx < 0 || gameIdx >= len(m.Games) {
+		return false
+	}
+	for _, g := range m.Games[:gameIdx] {
+		if g.InitialScore[0] == length-1 || g.InitialScore[1] == length-1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the games of the match follow each other: every game
+// starts at the score the previous one ended with, no game starts after the
+// match was won, and winners and points are in range.
+func (m *Match) Validate() error {
+	for i := range m.Games {
+		g := &m.Games[i]
+		if g.Winner != Player1 && g.Winner != Player2 && g.Winner != 0 {
+			return fmt.Errorf("game %d: invalid winner %d", g.GameNumber, g.Winner)
+		}
+		if g.PointsWon < 0 {
+			return fmt.Errorf("game %d: invalid points won %d", g.GameNumber, g.PointsWon)
+		}
+		if i > 0 {
+			if want := m.Games[i-1].ScoreAfter(); g.InitialScore != want {
+				return fmt.Errorf("game %d: starts at score %v, previous game ended at %v", g.GameNumber, g.InitialScore, want)
+			}
+		}
+		length := m.Metadata.MatchLength
+		if length > 0 && (g.InitialScore[0] >= length || g.InitialScore[1] >= length) {
+			return fmt.Errorf("game %d: starts at score %v in a %d point match", g.GameNumber, g.InitialScore, length)
+		}
+	}
+	return nil
+}
+
+// sameDecision reports whether two moves record the same played decision,
+// ignoring their analysis and comments
+func sameDecision(a, b *Move) bool {
+	if a.MoveType != b.MoveType {
+		return false
+	}
+	switch {
+	case a.CheckerMove != nil && b.CheckerMove != nil:
+		return a.CheckerMove.ActivePlayer == b.CheckerMove.ActivePlayer &&
+			a.CheckerMove.Dice == b.CheckerMove.Dice &&
+			a.CheckerMove.PlayedMove == b.CheckerMove.PlayedMove
+	case a.CubeMove != nil && b.CubeMove != nil:
+		return a.CubeMove.ActivePlayer == b.CubeMove.ActivePlayer &&
+			a.CubeMove.CubeAction == b.CubeMove.CubeAction
+	}
+	return a.CheckerMove == nil && b.CheckerMove == nil && a.CubeMove == nil && b.CubeMove == nil
+}
+
+// sameGame reports whether two non-empty games have the same initial score and moves
+func sameGame(a, b *Game) bool {
+	if len(a.Moves) == 0 || len(a.Moves) != len(b.Moves) || a.InitialScore != b.InitialScore {
+		return false
+	}
+	for i := range a.Moves {
+		if !sameDecision(&a.Moves[i], &b.Moves[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindDuplicateGames returns the indexes in Games of the games that repeat an
+// earlier game: same initial score and same sequence of rolls, plays and cube
+// actions. The first occurrence of each game is not reported. Games without
+// moves are never reported.
+func (m *Match) FindDuplicateGames() []int {
+	var duplicates []int
+	for i := range m.Games {
+		for j := 0; j < i; j++ {
+			if sameGame(&m.Games[i], &m.Games[j]) {
+				duplicates = append(duplicates, i)
+				break
+			}
+		}
+	}
+	return duplicates
 }
 
 // ToJSON serializes the Match to JSON
@@ -129,38 +503,130 @@ func (m *Match) ToJSON() ([]byte, error) {
 	return json.MarshalIndent(m, "", "  ")
 }
 
+// gameSummary is a Game without its moves, as written by Match.ToSummaryJSON
+type gameSummary struct {
+	GameNumber   int32    `json:"game_number"`
+	InitialScore [2]int32 `json:"initial_score"`
+	Winner       int32    `json:"winner"`
+	PointsWon    int32    `json:"points_won"`
+	FinalCube    int32    `json:"final_cube"`
+	CubeTurns    int32    `json:"cube_turns"`
+	CheckerMoves int      `json:"checker_moves"` // Number of checker moves
+	CubeMoves    int      `json:"cube_moves"`    // Number of cube decisions
+}
+
+// matchSummary is the JSON document of Match.ToSummaryJSON
+type matchSummary struct {
+	Metadata MatchMetadata `json:"metadata"`
+	Games    []gameSummary `json:"games"`
+	Winner   int32         `json:"winner"`
+}
+
+// ToSummaryJSON serializes the Match to a compact JSON listing: the metadata,
+// the result and move counts of each game, and the match winner. Moves and
+// their analysis are left out.
+func (m *Match) ToSummaryJSON() ([]byte, error) {
+	summary := matchSummary{
+		Metadata: m.Metadata,
+		Games:    make([]gameSummary, len(m.Games)),
+		Winner:   m.Winner,
+	}
+	for i, game := range m.Games {
+		g := gameSummary{
+			GameNumber:   game.GameNumber,
+			InitialScore: game.InitialScore,
+			Winner:       game.Winner,
+			PointsWon:    game.PointsWon,
+			FinalCube:    game.FinalCube,
+			CubeTurns:    game.CubeTurns,
+		}
+		for _, move := range game.Moves {
+			if move.CheckerMove != nil {
+				g.CheckerMoves++
+			} else if move.CubeMove != nil {
+				g.CubeMoves++
+			}
+		}
+		summary.Games[i] = g
+	}
+	return json.MarshalIndent(summary, "", "  ")
+}
+
 // ParseXG parses XG file segments and returns a lightweight match structure
 // This function accepts already extracted segments, allowing the caller to
 // provide data from memory, network, or any other source.
-func ParseXG(segments []*Segment) (*Match, error) {
-	var match Match
-	var currentGame *Game
-	fileVersion := int32(-1)
+// The options apply as in Parse, except WithMetadataOnly and WithMaxBytes
+// which need the file stream and are rejected.
+func ParseXG(segments []*Segment, opts ...Option) (*Match, error) {
+	o, err := segmentOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return parseXG(segments, o)
+}
 
-	// Extract product version from GDF header if present
-	for _, segment := range segments {
-		if segment.Type == SegmentGDFHdr {
-			gdfHeader := &GameDataFormatHdrRecord{}
-			reader := bytes.NewReader(segment.Data)
-			if err := gdfHeader.FromStream(reader); err == nil {
-				match.Metadata.ProductVersion = gdfHeader.GameName
-			}
-			break
+// parseXG parses XG file segments into one match with the options o
+func parseXG(segments []*Segment, o parseOptions) (*Match, error) {
+	matches, err := parseMatches(segments, false, o.nameEncoding)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.finish(matches[0]); err != nil {
+		return nil, err
+	}
+	return matches[0], nil
+}
+
+// ParseXGMulti parses XG file segments whose game file may hold several
+// matches, each starting with its own match header, and returns one Match per
+// match in file order. ParseXG would merge their games into a single Match.
+// The options apply to each match as in ParseXG.
+func ParseXGMulti(segments []*Segment, opts ...Option) ([]*Match, error) {
+	o, err := segmentOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := parseMatches(segments, true, o.nameEncoding)
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range matches {
+		if err := o.finish(match); err != nil {
+			return nil, err
 		}
 	}
+	return matches, nil
+}
 
-	// Parse comment segment if present
-	var comments []string
-	for _, segment := range segments {
-		if segment.Type == SegmentXGComment && len(segment.Data) > 0 {
-			comments = parseCommentSegment(segment.Data)
-			break
+// parseMatches parses XG file segments into matches. With split, each match
+// header after the first starts a new Match; otherwise a single Match is
+// returned with all games and the metadata of the last header.
+// nameEncoding decodes the legacy names of the match header, see newMatchMetadata.
+func parseMatches(segments []*Segment, split bool, nameEncoding encoding.Encoding) ([]*Match, error) {
+	match := &Match{}
+	matches := []*Match{match}
+	var currentGame *Game
+	cubeOwner := int32(0) // Cube owner in the current game, see cubeOwnerAfter
+	headerSeen := false
+	fileVersion := int32(-1)
+
+	// closeGame appends a game that was started but never closed, as in xgp
+	// position files which have no FooterGameEntry
+	closeGame := func() {
+		if currentGame != nil && len(currentGame.Moves) > 0 {
+			match.Games = append(match.Games, *currentGame)
 		}
+		currentGame = nil
 	}
 
+	productVersion := segmentsProductVersion(segments)
+	match.Metadata.ProductVersion = productVersion
+	comments := segmentsComments(segments)
+	rollouts := segmentsRollouts(segments)
+
 	for _, segment := range segments {
 		if segment.Type == SegmentXGGameFile {
-			records, err := ParseGameFile(segment.Data, fileVersion)
+			records, err := ParseGameFileAnalysed(segment.Data, fileVersion)
 			if err != nil {
 				return nil, err
 			}
@@ -169,38 +635,42 @@ func ParseXG(segments []*Segment) (*Match, error) {
 				switch r := rec.(type) {
 				case *HeaderMatchEntry:
 					fileVersion = r.Version
-					// Extract match metadata
-					match.Metadata = MatchMetadata{
-						Player1Name:   getPreferredString(r.Player1, r.SPlayer1),
-						Player2Name:   getPreferredString(r.Player2, r.SPlayer2),
-						Location:      getPreferredString(r.Location, r.SLocation),
-						Event:         getPreferredString(r.Event, r.SEvent),
-						Round:         getPreferredString(r.Round, r.SRound),
-						DateTime:      r.Date,
-						MatchLength:   r.MatchLength,
-						EngineVersion: r.Version,
+					if split && headerSeen {
+						closeGame()
+						match = &Match{}
+						matches = append(matches, match)
 					}
+					headerSeen = true
+
+					// Extract match metadata, keeping the product version read from the GDF header
+					match.Metadata = newMatchMetadata(r, nameEncoding)
+					match.Metadata.ProductVersion = productVersion
+					match.Metadata.HeaderComment = commentAt(comments, r.CommentHeaderMatch)
+					match.Metadata.FooterComment = commentAt(comments, r.CommentFooterMatch)
 
 				case *HeaderGameEntry:
 					// Start a new game
-					currentGame = &Game{
-						GameNumber:   r.GameNumber,
-						InitialScore: [2]int32{r.Score1, r.Score2},
-						Moves:        make([]Move, 0),
-					}
+					currentGame = newGame(r, comments)
+					currentGame.Moves = make([]Move, 0)
+					cubeOwner = 0
 
 				case *CubeEntry:
 					if currentGame != nil {
 						// Skip initial position cube entries (Double == -2) which don't represent actual cube decisions
 						if r.Double != -2 {
+							currentGame.turnCube(r)
+
 							cubeMove := convertCubeEntry(r)
+							setCubeRollout(cubeMove, r, rollouts)
+							if !r.hasCubePos() {
+								cubeMove.Position.CubePos = cubeOwner * r.ActiveP // Seen from the player on roll
+							}
+							cubeOwner = cubeOwnerAfter(cubeOwner, r)
 							move := Move{
 								MoveType: "cube",
 								CubeMove: cubeMove,
 							}
-							if r.CommentCube >= 0 && int(r.CommentCube) < len(comments) {
-								move.Comment = comments[r.CommentCube]
-							}
+							move.Comment = commentAt(comments, r.CommentCube)
 							currentGame.Moves = append(currentGame.Moves, move)
 						}
 					}
@@ -208,35 +678,208 @@ func ParseXG(segments []*Segment) (*Match, error) {
 				case *MoveEntry:
 					if currentGame != nil {
 						checkerMove := convertMoveEntry(r)
+						if !r.hasCubePos() {
+							checkerMove.Position.CubePos = cubeOwner * r.ActiveP
+						}
+						setMoveRollouts(checkerMove, r, rollouts)
 						move := Move{
 							MoveType:    "checker",
 							CheckerMove: checkerMove,
 						}
-						if r.CommentMove >= 0 && int(r.CommentMove) < len(comments) {
-							move.Comment = comments[r.CommentMove]
-						}
+						move.Comment = commentAt(comments, r.CommentMove)
 						currentGame.Moves = append(currentGame.Moves, move)
 					}
 
 				case *FooterGameEntry:
 					if currentGame != nil {
-						currentGame.Winner = r.Winner
-						currentGame.PointsWon = r.PointsWon
+						currentGame.setResult(r)
 						match.Games = append(match.Games, *currentGame)
 						currentGame = nil
 					}
+
+				case *FooterMatchEntry:
+					// Files saved again after a new analysis may keep stale
+					// footers: the last one wins
+					match.Winner = r.WinnerM
+					match.Metadata.FinalScore = [2]int32{r.Score1m, r.Score2m}
+					match.Metadata.FinalElo = [2]float64{r.Elo1m, r.Elo2m}
 				}
 			}
 		}
 	}
 
-	// Handle xgp position files which have no FooterGameEntry:
-	// if a game was started but never closed, append it as-is.
-	if currentGame != nil && len(currentGame.Moves) > 0 {
-		match.Games = append(match.Games, *currentGame)
+	closeGame()
+
+	return matches, nil
+}
+
+// segmentsProductVersion returns the XG product version of the GDF header
+// segment, "" when there is none
+func segmentsProductVersion(segments []*Segment) string {
+	for _, segment := range segments {
+		if segment.Type == SegmentGDFHdr {
+			gdfHeader := &GameDataFormatHdrRecord{}
+			if err := gdfHeader.FromStream(bytes.NewReader(segment.Data)); err == nil {
+				return gdfHeader.GameName
+			}
+			return ""
+		}
 	}
+	return ""
+}
 
-	return &match, nil
+// segmentsComments returns the comments of the comment segment, if present
+func segmentsComments(segments []*Segment) []string {
+	for _, segment := range segments {
+		if segment.Type == SegmentXGComment && len(segment.Data) > 0 {
+			return parseCommentSegment(segment.Data)
+		}
+	}
+	return nil
+}
+
+// segmentsRollouts returns the rollouts of the rollout segment, if present.
+// A rollout segment that cannot be read is ignored like a missing one: the
+// rollout settings only complete the analyses.
+func segmentsRollouts(segments []*Segment) []*RolloutContextEntry {
+	for _, segment := range segments {
+		if segment.Type == SegmentXGRollouts && len(segment.Data) > 0 {
+			rollouts, err := ParseRolloutFile(segment.Data)
+			if err != nil {
+				return nil
+			}
+			return rollouts
+		}
+	}
+	return nil
+}
+
+// rolloutAt returns the settings of the rollout at index, nil when index is
+// out of range (-1 for analyses that were not rolled out)
+func rolloutAt(rollouts []*RolloutContextEntry, index int32) *RolloutSettings {
+	if index < 0 || int(index) >= len(rollouts) {
+		return nil
+	}
+	r := rollouts[index]
+	settings := &RolloutSettings{
+		Trials:            r.Rolled,
+		MinTrials:         r.MinRoll,
+		MaxTrials:         r.MaxRoll,
+		Truncated:         r.Truncated,
+		VarianceReduction: r.Variance,
+		Cubeless:          r.Cubeless,
+		CheckerLevel:      r.Level1,
+		CubeLevel:         r.Level1C,
+		Seed:              r.RandomSeed,
+	}
+	if r.Truncated {
+		settings.TruncationPly = r.Truncate
+	}
+	return settings
+}
+
+// setMoveRollouts attaches the rollout settings of each candidate of m
+func setMoveRollouts(move *CheckerMove, m *MoveEntry, rollouts []*RolloutContextEntry) {
+	for i := range move.Analysis {
+		move.Analysis[i].Rollout = rolloutAt(rollouts, m.RolloutIndexM[i])
+	}
+}
+
+// setCubeRollout attaches the rollout settings of the cube decision of c
+func setCubeRollout(move *CubeMove, c *CubeEntry, rollouts []*RolloutContextEntry) {
+	if move.Analysis != nil {
+		move.Analysis.Rollout = rolloutAt(rollouts, c.RolloutIndexD)
+	}
+}
+
+// newGame starts a game from its HeaderGameEntry, without moves
+func newGame(r *HeaderGameEntry, comments []string) *Game {
+	return &Game{
+		GameNumber:      r.GameNumber,
+		InitialScore:    [2]int32{r.Score1, r.Score2},
+		InitialPosition: r.PosInit,
+		FinalCube:       1,
+		InProgress:      r.InProgress,
+		HeaderComment:   commentAt(comments, r.CommentHeaderGame),
+		FooterComment:   commentAt(comments, r.CommentFooterGame),
+	}
+}
+
+// turnCube tracks the cube value after a cube decision: a take turns the
+// cube, a beaver turns it twice
+func (g *Game) turnCube(r *CubeEntry) {
+	if r.Double == 1 && r.Take >= 1 {
+		g.FinalCube *= 2
+		g.CubeTurns++
+		if r.Take == 2 {
+			g.FinalCube *= 2
+			g.CubeTurns++
+		}
+	}
+}
+
+// cubeAction returns the CubeMove.CubeAction of a cube decision: the response
+// to the double from Take (0 pass, 1 take, 2 beaver), CubeNoDouble when the
+// player on roll did not double
+func cubeAction(r *CubeEntry) int32 {
+	if r.Double != 1 {
+		return CubeNoDouble
+	}
+	switch r.Take {
+	case 0:
+		return CubePass
+	case 1:
+		return CubeTake
+	case 2:
+		return CubeBeaver
+	}
+	return CubeDouble
+}
+
+// cubeOwnerAfter returns the cube owner after the cube decision r from the
+// owner before it: Player1, Player2 or 0 for a centered cube.
+// The taker owns the cube after a take, and the doubler again after a beaver.
+func cubeOwnerAfter(owner int32, r *CubeEntry) int32 {
+	if r.Double != 1 {
+		return owner
+	}
+	switch r.Take {
+	case 1:
+		return -r.ActiveP
+	case 2:
+		return r.ActiveP
+	}
+	return owner
+}
+
+// hasCubePos reports whether the analysis of the cube decision holds the cube
+// owner. XG leaves the analysis of a decision it did not analyse zeroed, cube
+// value included; the owner is then tracked with cubeOwnerAfter.
+func (c *CubeEntry) hasCubePos() bool {
+	return c.Doubled != nil && c.Doubled.Cube != 0
+}
+
+// hasCubePos reports whether the analysis of the checker move holds the cube
+// owner, see CubeEntry.hasCubePos
+func (m *MoveEntry) hasCubePos() bool {
+	return m.DataMoves != nil && m.DataMoves.Cube != 0
+}
+
+// setResult records the result of the game from its FooterGameEntry
+func (g *Game) setResult(r *FooterGameEntry) {
+	g.Winner = r.Winner
+	g.PointsWon = r.PointsWon
+	g.ResignError = float32(r.ErrResign)
+	g.TakeResignError = float32(r.ErrTakeResign)
+}
+
+// commentAt returns the comment at index in the comment segment, or "" when
+// index is -1 (no comment) or out of range
+func commentAt(comments []string, index int32) string {
+	if index < 0 || int(index) >= len(comments) {
+		return ""
+	}
+	return comments[index]
 }
 
 // parseCommentSegment parses the XG comment segment (temp.xgc) into a slice of plain text strings.
@@ -371,78 +1014,310 @@ func ParseXGFromFile(filename string) (*Match, error) {
 // ParseXGFromReader parses an XG file from an io.Reader and returns a lightweight match structure
 // This allows parsing XG files from network streams, memory buffers, or any io.Reader source.
 func ParseXGFromReader(r io.ReadSeeker) (*Match, error) {
-	// Read and extract the Game Data Format Header
-	gdfHeader := &GameDataFormatHdrRecord{}
-	err := gdfHeader.FromStream(r)
+	segments, err := readSegments(r, 0)
 	if err != nil {
 		return nil, err
 	}
+	return ParseXG(segments)
+}
 
-	// Get segments using the same logic as Import.GetFileSegments
-	r.Seek(0, io.SeekStart)
-	var segments []*Segment
+// ParseXGFromReaderAt parses an XG file of size bytes through ReadAt, such as
+// ranged HTTP requests. Only the GDF header, the archive index at the end of
+// the file, the game file and the comments are read: thumbnails, rollouts and
+// the check of the whole archive CRC, which needs all of it, are skipped.
+func ParseXGFromReaderAt(ra io.ReaderAt, size int64) (*Match, error) {
+	segments, err := readGameSegments(io.NewSectionReader(ra, 0, size))
+	if err != nil {
+		return nil, err
+	}
+	return ParseXG(segments)
+}
 
-	// Read GDF header
-	gdfData := make([]byte, gdfHeader.HeaderSize)
-	_, err = io.ReadFull(r, gdfData)
+// ParseXGFromReaderLimited is ParseXGFromReader for untrusted input: it fails
+// with ErrTooLarge as soon as the archived files decompress to more than
+// maxBytes in total, instead of inflating them all into memory.
+func ParseXGFromReaderLimited(r io.ReadSeeker, maxBytes int64) (*Match, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("invalid size limit %d", maxBytes)
+	}
+	segments, err := readSegments(r, maxBytes)
 	if err != nil {
 		return nil, err
 	}
+	return ParseXG(segments)
+}
 
-	segments = append(segments, &Segment{
-		Type: SegmentGDFHdr,
-		Data: gdfData,
+// ParseXGFromArchive parses the match of an archive already opened with
+// NewZlibArchive. Only the game file, the comments and the rollouts are read
+// from it. The GDF header is not part of the archive, so
+// Metadata.ProductVersion is empty.
+func ParseXGFromArchive(za *ZlibArchive) (*Match, error) {
+	segments, err := archiveSegments(za, nil, func(segmentType int) bool {
+		return segmentType == SegmentXGGameFile || segmentType == SegmentXGComment || segmentType == SegmentXGRollouts
 	})
+	if err != nil {
+		return nil, err
+	}
+	return ParseXG(segments)
+}
+
+// ParseXGAuto parses an XG file that may be wrapped in gzip or base64 encoding.
+// The input is read fully into memory, unwrapped and passed to ParseXGFromReader.
+func ParseXGAuto(r io.Reader) (*Match, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = unwrapXGData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseXGFromReader(bytes.NewReader(data))
+}
+
+// unwrapXGData removes gzip and base64 layers around XG file data
+func unwrapXGData(data []byte) ([]byte, error) {
+	// A base64 payload may itself hold a gzipped file, so unwrap up to two layers
+	for i := 0; i < 2; i++ {
+		switch {
+		case len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b:
+			zr, err := gzip.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("error reading gzip data: %v", err)
+			}
+			data, err = io.ReadAll(zr)
+			zr.Close()
+			if err != nil {
+				return nil, fmt.Errorf("error reading gzip data: %v", err)
+			}
+		case isBase64Text(data):
+			text := strings.Join(strings.Fields(string(data)), "")
+			decoded, err := base64.StdEncoding.DecodeString(text)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding base64 data: %v", err)
+			}
+			data = decoded
+		default:
+			return data, nil
+		}
+	}
+	return data, nil
+}
 
-	// Extract thumbnail if present
-	if gdfHeader.ThumbnailSize > 0 {
-		r.Seek(gdfHeader.ThumbnailOffset, io.SeekCurrent)
-		imgData := make([]byte, gdfHeader.ThumbnailSize)
-		_, err = io.ReadFull(r, imgData)
+// isBase64Text reports whether data only holds base64 characters and whitespace
+func isBase64Text(data []byte) bool {
+	n := 0
+	for _, c := range data {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '+', c == '/', c == '=':
+			n++
+		case c == ' ', c == '\t', c == '\r', c == '\n':
+		default:
+			return false
+		}
+	}
+	return n > 0
+}
+
+// ParseXGTopN parses an XG file like ParseXGFromReader, keeping only the n best
+// analysed candidates of each checker move. A non-positive n keeps them all.
+func ParseXGTopN(r io.ReadSeeker, n int) (*Match, error) {
+	match, err := ParseXGFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if n > 0 {
+		match.keepTopAnalysis(n)
+	}
+
+	return match, nil
+}
+
+// keepTopAnalysis keeps the n best candidates of each checker move
+func (m *Match) keepTopAnalysis(n int) {
+	for g := range m.Games {
+		for _, move := range m.Games[g].Moves {
+			if move.CheckerMove != nil {
+				move.CheckerMove.Analysis = topAnalysis(move.CheckerMove.Analysis, n)
+			}
+		}
+	}
+}
+
+// topAnalysis sorts candidates by decreasing equity and keeps the first n.
+// The result gets its own backing array so the dropped candidates can be freed.
+func topAnalysis(analysis []CheckerAnalysis, n int) []CheckerAnalysis {
+	sort.SliceStable(analysis, func(i, j int) bool {
+		return analysis[i].Equity > analysis[j].Equity
+	})
+	if len(analysis) <= n {
+		return analysis
+	}
+	return append([]CheckerAnalysis(nil), analysis[:n]...)
+}
+
+// ParseXGLight is deprecated. Use ParseXGFromFile instead.
+func ParseXGLight(filename string) (*Match, error) {
+	return ParseXGFromFile(filename)
+}
+
+// newMatchMetadata builds the match metadata from a HeaderMatchEntry.
+// The Unicode names are preferred; the legacy shortstrings used in their place
+// are decoded with nameEncoding, or kept as they are when it is nil.
+func newMatchMetadata(r *HeaderMatchEntry, nameEncoding encoding.Encoding) MatchMetadata {
+	legacy := func(s string) string {
+		if nameEncoding == nil {
+			return s
+		}
+		decoded, err := nameEncoding.NewDecoder().String(s)
 		if err != nil {
-			return nil, err
+			return s
 		}
-		segments = append(segments, &Segment{
-			Type: SegmentGDFImage,
-			Data: imgData,
-		})
+		return decoded
+	}
+
+	return MatchMetadata{
+		Player1Name:   getPreferredString(r.Player1, legacy(r.SPlayer1)),
+		Player2Name:   getPreferredString(r.Player2, legacy(r.SPlayer2)),
+		Location:      getPreferredString(r.Location, legacy(r.SLocation)),
+		Event:         getPreferredString(r.Event, legacy(r.SEvent)),
+		Round:         getPreferredString(r.Round, legacy(r.SRound)),
+		DateTime:      r.Date,
+		MatchLength:   r.MatchLength,
+		IsMoney:       r.IsMoneyMatch,
+		CrawfordRule:  r.Crawford,
+		EngineVersion: r.Version,
+		Magic:         r.Magic,
+		GameID:        r.GameId,
+		SiteID:        r.SiteId,
+		Currency:      r.Currency,
+		WinMoney:      r.WinMoney,
+		LoseMoney:     r.LoseMoney,
+		FeeMoney:      r.FeeMoney,
+		TableStake:    r.TableStake,
+	}
+}
+
+// PeekMatchHeader reads only the match metadata of an XG file.
+// Only the GDF header, the archive index and the first record of the game file
+// are read; the rest of the archive is never decompressed. This is much faster
+// than ParseXGFromFile when listing large collections.
+func PeekMatchHeader(filename string) (*MatchMetadata, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return peekMatchHeader(file, 0, nil)
+}
+
+// peekMatchHeader reads the match metadata of an XG file stream, see PeekMatchHeader.
+// maxBytes limits the bytes buffered and extracted from the archive, 0 for no limit.
+// nameEncoding is used as in newMatchMetadata.
+func peekMatchHeader(r io.ReadSeeker, maxBytes int64, nameEncoding encoding.Encoding) (*MatchMetadata, error) {
+	r, err := seekableReader(r, maxBytes)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get archive object
-	archiveObj, err := NewZlibArchive(r)
+	var archiveObj *ZlibArchive
+	gdfHeader := &GameDataFormatHdrRecord{}
+	err = gdfHeader.FromStream(r)
+	if err != nil {
+		archiveObj, err = openBareArchive(r, maxBytes, err)
+	} else {
+		archiveObj, err = newLimitedZlibArchive(r, maxBytes)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// Process all files in the archive
 	for _, fileRec := range archiveObj.ArcRegistry {
-		data, err := archiveObj.GetArchiveFile(&fileRec)
+		if segmentTypeForFile(fileRec.Name) != SegmentXGGameFile {
+			continue
+		}
+
+		data, err := archiveObj.getArchiveFilePrefix(&fileRec, recordSize(-1))
 		if err != nil {
 			return nil, err
 		}
 
-		segmentType := XGFileMap[fileRec.Name]
-		segments = append(segments, &Segment{
-			Type:     segmentType,
-			Data:     data,
-			Filename: fileRec.Name,
-		})
+		rec := &GameFileRecord{}
+		err = rec.FromStream(bytes.NewReader(data), -1)
+		if err != nil {
+			return nil, err
+		}
+		header, ok := rec.Record.(*HeaderMatchEntry)
+		if !ok {
+			return nil, fmt.Errorf("game file does not start with a match header")
+		}
+
+		metadata := newMatchMetadata(header, nameEncoding)
+		metadata.ProductVersion = gdfHeader.GameName
+		return &metadata, nil
 	}
 
-	return ParseXG(segments)
+	return nil, fmt.Errorf("no game file found in archive")
 }
 
-// ParseXGLight is deprecated. Use ParseXGFromFile instead.
-func ParseXGLight(filename string) (*Match, error) {
-	return ParseXGFromFile(filename)
+// matchDateLayout is the layout of MatchMetadata.DateTime for XG binary files
+const matchDateLayout = "2006-01-02 15:04:05"
+
+// ParseXGDirSince parses the .xg files of a directory whose match date is on
+// or after since. The date is read with PeekMatchHeader, so older files are
+// skipped without being decompressed. Match dates are compared in UTC.
+// Files that cannot be read or have an invalid date are skipped: the matches
+// of the other files are returned with the errors of these files joined, each
+// prefixed with its file name.
+func ParseXGDirSince(dir string, since time.Time) ([]*Match, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*Match
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".xg") {
+			continue
+		}
+		filename := filepath.Join(dir, entry.Name())
+
+		metadata, err := PeekMatchHeader(filename)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filename, err))
+			continue
+		}
+		date, err := time.Parse(matchDateLayout, metadata.DateTime)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid match date %q", filename, metadata.DateTime))
+			continue
+		}
+		if date.Before(since) {
+			continue
+		}
+
+		match, err := ParseXGFromFile(filename)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filename, err))
+			continue
+		}
+		matches = append(matches, match)
+	}
+
+	return matches, errors.Join(errs...)
 }
 
-// getPreferredString returns the first non-empty string
+// getPreferredString returns the first non-empty string after sanitizing both
 func getPreferredString(preferred, fallback string) string {
-	if preferred != "" {
+	if preferred = SanitizeName(preferred); preferred != "" {
 		return preferred
 	}
-	return fallback
+	return SanitizeName(fallback)
 }
 
 // swapPositionCheckers flips the board checkers from one player's perspective to the other
@@ -468,9 +1343,26 @@ func swapPosition(pos Position) Position {
 		Cube:     pos.Cube,
 		CubePos:  -pos.CubePos,                         // Swap cube position: 1 becomes -1, -1 becomes 1, 0 stays 0
 		Score:    [2]int32{pos.Score[1], pos.Score[0]}, // Swap score array
+		Crawford: pos.Crawford,
 	}
 }
 
+// XG evaluation arrays (CubeEntry.Doubled.Eval and MoveEntry.DataMoves.Eval)
+// hold the outcome probabilities seen from the player on roll:
+//
+//	Eval[0] = opponent wins a backgammon (B of the opponent line)
+//	Eval[1] = opponent wins a gammon (G of the opponent line)
+//	Eval[2] = opponent wins (opponent winning chances)
+//	Eval[3] = not used
+//	Eval[4] = player on roll wins a gammon (G of the player line)
+//	Eval[5] = player on roll wins a backgammon (B of the player line)
+//	Eval[6] = equity
+//
+// evalRates returns the rates of CheckerAnalysis and CubeAnalysis from such an array.
+func evalRates(eval [7]float32) (p1Win, p1Gammon, p1Bg, p2Gammon, p2Bg float32) {
+	return 1 - eval[2], eval[4], eval[5], eval[1], eval[0]
+}
+
 // convertCubeEntry converts a full CubeEntry to CubeMove
 func convertCubeEntry(c *CubeEntry) *CubeMove {
 	// Build initial position
@@ -482,36 +1374,24 @@ func convertCubeEntry(c *CubeEntry) *CubeMove {
 	}
 
 	// Swap position to player on roll's perspective only when active_player == -1
-	if c.ActiveP == -1 {
+	if c.ActiveP == Player2 {
 		position = swapPosition(position)
 	}
 
 	move := &CubeMove{
-		Position:     position,
-		ActivePlayer: c.ActiveP,
-		CubeAction:   c.Double, // Simplified - may need more logic
+		Position:       position,
+		ActivePlayer:   c.ActiveP,
+		CubeAction:     cubeAction(c),
+		AnalysisLevel:  c.AnalyzeC,
+		ComputerChoice: c.CompChoiceD,
 	}
 
 	// Add cube analysis if available
 	if c.Doubled != nil {
 		// For cube decisions, Eval is ALWAYS from the active player's perspective (player on roll)
-		// Eval[0] = opponent's backgammon rate
-		// Eval[1] = opponent's gammon rate
-		// Eval[2] = opponent's win rate
-		// Eval[4] = player on roll's gammon rate
-		// Eval[5] = player on roll's backgammon rate
-		// Eval[6] = cubeless equity
-		//
 		// player1 in our output = player on roll (active_player)
 		// player2 in our output = opponent
-		var p1Win, p1Gammon, p1Bg, p2Gammon, p2Bg float32
-
-		// XG's Eval[2] is opponent's win rate, so player on roll's win rate is 1 - Eval[2]
-		p1Win = 1.0 - c.Doubled.Eval[2] // Player on roll's win rate
-		p1Gammon = c.Doubled.Eval[4]    // Player on roll's gammon rate
-		p1Bg = c.Doubled.Eval[5]        // Player on roll's backgammon rate
-		p2Gammon = c.Doubled.Eval[1]    // Opponent's gammon rate
-		p2Bg = c.Doubled.Eval[0]        // Opponent's backgammon rate
+		p1Win, p1Gammon, p1Bg, p2Gammon, p2Bg := evalRates(c.Doubled.Eval)
 
 		// Wrong pass/take percentage: the probability threshold where making the wrong
 		// cube decision (take vs pass after double) results in the same equity as no double.
@@ -572,6 +1452,7 @@ func convertCubeEntry(c *CubeEntry) *CubeMove {
 			CubefulDoubleTake:    c.Doubled.EquDouble,
 			CubefulDoublePass:    c.Doubled.EquDrop,
 			WrongPassTakePercent: wrongPassTakePercent,
+			TooGood:              c.Doubled.EquB > c.Doubled.EquDrop,
 			AnalysisDepth:        c.Doubled.Level,
 		}
 		move.Analysis = analysis
@@ -594,7 +1475,7 @@ func convertMoveEntry(m *MoveEntry) *CheckerMove {
 	}
 
 	// Swap position to player on roll's perspective only when active_player == -1
-	if m.ActiveP == -1 {
+	if m.ActiveP == Player2 {
 		position = swapPosition(position)
 	}
 
@@ -615,11 +1496,17 @@ func convertMoveEntry(m *MoveEntry) *CheckerMove {
 	}
 
 	move := &CheckerMove{
-		Position:     position,
-		ActivePlayer: m.ActiveP,
-		Dice:         m.Dice,
-		PlayedMove:   playedMove,
-		Analysis:     make([]CheckerAnalysis, 0),
+		Position:       position,
+		ActivePlayer:   m.ActiveP,
+		Dice:           m.Dice,
+		PlayedMove:     playedMove,
+		Invalid:        m.InvalidM != 0,
+		AnalysisLevel:  m.AnalyzeM,
+		ComputerChoice: m.CompChoice,
+		InitialEquity:  float32(m.InitEq),
+		EquityLost:     float32(m.ErrMove),
+		Luck:           float32(m.ErrLuck),
+		Analysis:       make([]CheckerAnalysis, 0),
 	}
 
 	// Extract analysis from DataMoves if available
@@ -655,6 +1542,7 @@ func convertMoveEntry(m *MoveEntry) *CheckerMove {
 
 			analysisPosition := m.DataMoves.PosPlayed[i]
 
+			p1Win, p1Gammon, p1Bg, p2Gammon, p2Bg := evalRates(m.DataMoves.Eval[i])
 			analysis := CheckerAnalysis{
 				Position: Position{
 					Checkers: analysisPosition,
@@ -663,14 +1551,16 @@ func convertMoveEntry(m *MoveEntry) *CheckerMove {
 					Score:    m.DataMoves.Score,
 				},
 				Move:              moveArray,
-				Player1WinRate:    1.0 - m.DataMoves.Eval[i][2], // 1 - opponent win rate
-				Player1GammonRate: m.DataMoves.Eval[i][4],
-				Player1BgRate:     m.DataMoves.Eval[i][5],
-				Player2GammonRate: m.DataMoves.Eval[i][1],
-				Player2BgRate:     m.DataMoves.Eval[i][0],
+				Player1WinRate:    p1Win,
+				Player1GammonRate: p1Gammon,
+				Player1BgRate:     p1Bg,
+				Player2GammonRate: p2Gammon,
+				Player2BgRate:     p2Bg,
 				Equity:            m.DataMoves.Eval[i][6],
 				AnalysisDepth:     m.DataMoves.EvalLevel[i].Level,
+				IsDouble:          m.DataMoves.EvalLevel[i].IsDouble,
 			}
+			analysis.AbsolutePosition = analysis.Position.Absolute(move.ActivePlayer)
 			move.Analysis = append(move.Analysis, analysis)
 		}
 	}
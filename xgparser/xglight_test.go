@@ -1,7 +1,20 @@
 package xgparser
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestStripRTF(t *testing.T) {
@@ -82,3 +95,1387 @@ func TestParseCommentSegment(t *testing.T) {
 		}
 	}
 }
+
+func TestParseXG_InvalidMove(t *testing.T) {
+	noMove := [8]int32{-1, -1, -1, -1, -1, -1, -1, -1}
+	forced := testMoveRecord(1, [2]int32{6, 6}, noMove)
+	putInt32(forced, offMEInvalidM, 1)
+
+	segments := testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		testMoveRecord(-1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1}),
+		forced,
+		testGameFooter(-1, 1),
+	)
+
+	match, err := ParseXG(segments)
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	if len(match.Games) != 1 || len(match.Games[0].Moves) != 2 {
+		t.Fatalf("unexpected match structure: %+v", match.Games)
+	}
+
+	moves := match.Games[0].Moves
+	if moves[0].CheckerMove.Invalid {
+		t.Errorf("move 0 Invalid = true, want false")
+	}
+	if !moves[1].CheckerMove.Invalid {
+		t.Errorf("move 1 Invalid = false, want true")
+	}
+}
+
+func TestParseXG_AnalysisLevel(t *testing.T) {
+	analyzed := testMoveRecord(1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1})
+	putInt32(analyzed, offMEAnalyzeM, 4)
+	putInt32(analyzed, offMECompChoice, 2)
+	putFloat64(analyzed, offMEInitEq, 0.25)
+	putFloat64(analyzed, offMEErrMove, -0.125)
+	putMoveCandidate(analyzed, 0, [8]int8{8, 5, 6, 5, -1, -1, -1, -1}, [7]float32{}, 4)
+	putMoveCandidate(analyzed, 1, [8]int8{24, 21, 24, 23, -1, -1, -1, -1}, [7]float32{}, 4)
+	analyzed[offMEDEvalLevel+4*1+2] = 1 // Candidate 1 evaluated as a double
+	cube := testCubeRecord(-1, 1, 1, 1)
+	putInt32(cube, offCEAnalyzeC, 3)
+	putInt32(cube, offCECompChoiceD, 1)
+
+	segments := testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		analyzed,
+		cube,
+		testGameFooter(1, 2),
+	)
+
+	match, err := ParseXG(segments)
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	if len(match.Games) != 1 || len(match.Games[0].Moves) != 2 {
+		t.Fatalf("unexpected match structure: %+v", match.Games)
+	}
+
+	moves := match.Games[0].Moves
+	if m := moves[0].CheckerMove; m.AnalysisLevel != 4 || m.ComputerChoice != 2 {
+		t.Errorf("checker move AnalysisLevel = %d, ComputerChoice = %d, want 4, 2", m.AnalysisLevel, m.ComputerChoice)
+	}
+	if m := moves[0].CheckerMove; m.InitialEquity != 0.25 || m.EquityLost != -0.125 {
+		t.Errorf("checker move InitialEquity = %v, EquityLost = %v, want 0.25, -0.125", m.InitialEquity, m.EquityLost)
+	}
+	if a := moves[0].CheckerMove.Analysis; len(a) != 2 || a[0].IsDouble || !a[1].IsDouble {
+		t.Errorf("checker move analysis IsDouble = %+v, want only candidate 1", a)
+	}
+	if m := moves[1].CubeMove; m.AnalysisLevel != 3 || m.ComputerChoice != 1 {
+		t.Errorf("cube move AnalysisLevel = %d, ComputerChoice = %d, want 3, 1", m.AnalysisLevel, m.ComputerChoice)
+	}
+}
+
+func TestParseXG_ResignErrors(t *testing.T) {
+	footer := testGameFooter(-1, 2)
+	putInt32(footer, offFGTermination, 1) // Resigned
+	putFloat64(footer, offFGErrResign, -0.25)
+	putFloat64(footer, offFGErrTakeRes, 0.5)
+
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		footer,
+		testGameHeader(2, 2, 0),
+		testGameFooter(1, 1),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	if len(match.Games) != 2 {
+		t.Fatalf("len(Games) = %d, want 2", len(match.Games))
+	}
+	if g := match.Games[0]; g.ResignError != -0.25 || g.TakeResignError != 0.5 {
+		t.Errorf("ResignError = %v, TakeResignError = %v, want -0.25, 0.5", g.ResignError, g.TakeResignError)
+	}
+	if g := match.Games[1]; g.ResignError != 0 || g.TakeResignError != 0 {
+		t.Errorf("game 2 ResignError = %v, TakeResignError = %v, want 0, 0", g.ResignError, g.TakeResignError)
+	}
+}
+
+func TestPeekMatchHeader(t *testing.T) {
+	header := testMatchHeader("Alice", "Bob", 7)
+	putShortStr(header, offHMEvent, "Club Night")
+	putFloat64(header, offHMDate, 45000.5)
+	gameFile := testGameFile(header, testGameHeader(1, 0, 0), testGameFooter(1, 2))
+	path := writeTestXGFile(t, buildTestXGFile(gameFile))
+
+	metadata, err := PeekMatchHeader(path)
+	if err != nil {
+		t.Fatalf("PeekMatchHeader() error = %v", err)
+	}
+
+	match, err := ParseXGFromFile(path)
+	if err != nil {
+		t.Fatalf("ParseXGFromFile() error = %v", err)
+	}
+	if *metadata != match.Metadata {
+		t.Errorf("PeekMatchHeader() = %+v, want %+v", *metadata, match.Metadata)
+	}
+	if metadata.Player1Name != "Alice" || metadata.Event != "Club Night" || metadata.DateTime != "2023-03-15 12:00:00" {
+		t.Errorf("PeekMatchHeader() = %+v", *metadata)
+	}
+	if metadata.ProductVersion != "eXtreme Gammon 2.19" {
+		t.Errorf("ProductVersion = %q, want %q", metadata.ProductVersion, "eXtreme Gammon 2.19")
+	}
+}
+
+// benchmarkXGFile builds a match file with many analysed moves
+func benchmarkXGFile(b *testing.B) string {
+	records := [][]byte{testMatchHeader("Alice", "Bob", 25)}
+	for g := int32(1); g <= 20; g++ {
+		records = append(records, testGameHeader(g, 0, 0))
+		for i := 0; i < 50; i++ {
+			rec := testMoveRecord(1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1})
+			for c := 0; c < 20; c++ {
+				putMoveCandidate(rec, c, [8]int8{7, 4, 5, 4, -1, -1, -1, -1}, [7]float32{0, 0.1, 0.4, 0, 0.2, 0, 0.1}, 2)
+			}
+			records = append(records, rec)
+		}
+		records = append(records, testGameFooter(1, 1))
+	}
+	return writeTestXGFile(b, buildTestXGFile(testGameFile(records...)))
+}
+
+func BenchmarkPeekMatchHeader(b *testing.B) {
+	path := benchmarkXGFile(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := PeekMatchHeader(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseXGFromFile(b *testing.B) {
+	path := benchmarkXGFile(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseXGFromFile(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestParseXGDirSince(t *testing.T) {
+	dir := t.TempDir()
+	dates := map[string]float64{
+		"old.xg":    45000, // 2023-03-15
+		"mid.XG":    45100, // 2023-06-23
+		"new.xg":    45200.75,
+		"notes.txt": 0,
+	}
+	for name, date := range dates {
+		header := testMatchHeader(name, "Bob", 5)
+		putFloat64(header, offHMDate, date)
+		data := buildTestXGFile(testGameFile(header, testGameHeader(1, 0, 0), testGameFooter(1, 1)))
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	matches, err := ParseXGDirSince(dir, time.Date(2023, 6, 23, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("ParseXGDirSince() error = %v", err)
+	}
+
+	var names []string
+	for _, m := range matches {
+		names = append(names, m.Metadata.Player1Name)
+	}
+	if got := strings.Join(names, ","); got != "mid.XG,new.xg" {
+		t.Errorf("matches = %s, want mid.XG,new.xg", got)
+	}
+}
+
+func TestParseXGDirSince_Errors(t *testing.T) {
+	dir := t.TempDir()
+	dates := map[string]float64{
+		"good.xg":    45100,
+		"future.xg":  4e6, // Year 12851, not a valid match date
+		"corrupt.xg": -1,
+	}
+	for name, date := range dates {
+		header := testMatchHeader(name, "Bob", 5)
+		putFloat64(header, offHMDate, date)
+		data := buildTestXGFile(testGameFile(header, testGameHeader(1, 0, 0), testGameFooter(1, 1)))
+		if date < 0 {
+			data = []byte("not an XG file")
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The bad files are skipped and reported, the good one is still parsed
+	matches, err := ParseXGDirSince(dir, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	if len(matches) != 1 || matches[0].Metadata.Player1Name != "good.xg" {
+		t.Errorf("matches = %d, want good.xg only", len(matches))
+	}
+	if err == nil || !strings.Contains(err.Error(), "corrupt.xg") || !strings.Contains(err.Error(), "future.xg") || strings.Contains(err.Error(), "good.xg") {
+		t.Errorf("ParseXGDirSince() error = %v, want the errors of corrupt.xg and future.xg", err)
+	}
+}
+
+func TestParseXG_CubeTurns(t *testing.T) {
+	move := [8]int32{13, 10, 10, 9, -1, -1, -1, -1}
+	segments := testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 7),
+		testGameHeader(1, 0, 0),
+		testMoveRecord(1, [2]int32{3, 1}, move),
+		testCubeRecord(-1, 1, 1, 1), // Bob doubles, Alice takes
+		testMoveRecord(-1, [2]int32{5, 2}, move),
+		testCubeRecord(1, 0, 0, 2), // Alice holds the cube
+		testMoveRecord(1, [2]int32{6, 4}, move),
+		testCubeRecord(1, 1, 1, 2), // Alice redoubles, Bob takes
+		testMoveRecord(1, [2]int32{6, 4}, move),
+		testGameFooter(1, 4),
+		testGameHeader(2, 4, 0),
+		testMoveRecord(-1, [2]int32{2, 1}, move),
+		testCubeRecord(1, 1, 0, 1), // Alice doubles, Bob passes
+		testGameFooter(1, 1),
+	)
+
+	match, err := ParseXG(segments)
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	if len(match.Games) != 2 {
+		t.Fatalf("game count = %d, want 2", len(match.Games))
+	}
+
+	if g := match.Games[0]; g.FinalCube != 4 || g.CubeTurns != 2 {
+		t.Errorf("game 1 FinalCube = %d, CubeTurns = %d, want 4, 2", g.FinalCube, g.CubeTurns)
+	}
+	if g := match.Games[1]; g.FinalCube != 1 || g.CubeTurns != 0 {
+		t.Errorf("game 2 FinalCube = %d, CubeTurns = %d, want 1, 0", g.FinalCube, g.CubeTurns)
+	}
+}
+
+func TestParseXGAuto(t *testing.T) {
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0), testGameFooter(1, 1))
+	raw := buildTestXGFile(gameFile)
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(raw)
+	zw.Close()
+
+	// Line-wrapped base64, as produced by most encoders
+	encoded := base64.StdEncoding.EncodeToString(raw)
+	var wrapped strings.Builder
+	for len(encoded) > 76 {
+		wrapped.WriteString(encoded[:76] + "\n")
+		encoded = encoded[76:]
+	}
+	wrapped.WriteString(encoded + "\n")
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"raw", raw},
+		{"gzip", gz.Bytes()},
+		{"base64", []byte(wrapped.String())},
+		{"base64 gzip", []byte(base64.StdEncoding.EncodeToString(gz.Bytes()))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := ParseXGAuto(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("ParseXGAuto() error = %v", err)
+			}
+			if match.Metadata.Player1Name != "Alice" || len(match.Games) != 1 {
+				t.Errorf("ParseXGAuto() = %+v", match)
+			}
+		})
+	}
+
+	if _, err := ParseXGAuto(strings.NewReader("not an xg file!")); !errors.Is(err, ErrNotXGFile) {
+		t.Errorf("ParseXGAuto() error = %v, want ErrNotXGFile", err)
+	}
+}
+
+func TestParseXGFromReaderLimited(t *testing.T) {
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0), testGameFooter(1, 1))
+
+	match, err := ParseXGFromReaderLimited(bytes.NewReader(buildTestXGFile(gameFile)), 1<<20)
+	if err != nil {
+		t.Fatalf("ParseXGFromReaderLimited() error = %v", err)
+	}
+	if match.Metadata.Player1Name != "Alice" {
+		t.Errorf("Player1Name = %q, want Alice", match.Metadata.Player1Name)
+	}
+
+	// 16 MB of zeros compress to a few kilobytes
+	bomb := buildTestXGFile(gameFile, testArchiveFile{name: "temp.xgr", data: make([]byte, 16<<20)})
+	if len(bomb) > 1<<20 {
+		t.Fatalf("test archive is %d bytes, want a small file", len(bomb))
+	}
+	if _, err := ParseXGFromReaderLimited(bytes.NewReader(bomb), 1<<20); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("ParseXGFromReaderLimited() error = %v, want ErrTooLarge", err)
+	}
+	if _, err := ParseXGFromReader(bytes.NewReader(bomb)); err != nil {
+		t.Errorf("ParseXGFromReader() error = %v without a limit", err)
+	}
+}
+
+func TestParseXGTopN(t *testing.T) {
+	rec := testMoveRecord(1, [2]int32{3, 1}, [8]int32{8, 5, 6, 5, -1, -1, -1, -1})
+	equities := []float32{0.05, -0.12, 0.21, 0.0, -0.3, 0.1}
+	for i, eq := range equities {
+		putMoveCandidate(rec, i, [8]int8{int8(24 - i), 21, -1, -1, -1, -1, -1, -1}, [7]float32{0, 0, 0.5, 0, 0, 0, eq}, 2)
+	}
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0), rec, testGameFooter(1, 1))
+	data := buildTestXGFile(gameFile)
+
+	for _, tt := range []struct {
+		n    int
+		want int
+	}{{3, 3}, {10, 6}} {
+		match, err := ParseXGTopN(bytes.NewReader(data), tt.n)
+		if err != nil {
+			t.Fatalf("ParseXGTopN() error = %v", err)
+		}
+		analysis := match.Games[0].Moves[0].CheckerMove.Analysis
+		if len(analysis) != tt.want {
+			t.Errorf("n=%d: analysis length = %d, want %d", tt.n, len(analysis), tt.want)
+			continue
+		}
+		if analysis[0].Equity != 0.21 || analysis[0].Move[0] != 23 {
+			t.Errorf("n=%d: best candidate = %+v, want equity 0.21 from 23", tt.n, analysis[0])
+		}
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Alice", "Alice"},
+		{"Alice\x00\x00\x00", "Alice"},
+		{"Al\x00ice", "Alice"},
+		{" Jürgen\t\r\n", "Jürgen"},
+		{"プレーヤー 1\x1b", "プレーヤー 1"},
+		{"\x00\x00", ""},
+	}
+
+	for _, tt := range tests {
+		if got := SanitizeName(tt.input); got != tt.expected {
+			t.Errorf("SanitizeName(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+
+	// A name made only of nulls falls back to the shortstring name
+	if got := getPreferredString("\x00\x00", "Bob\x00"); got != "Bob" {
+		t.Errorf("getPreferredString() = %q, want %q", got, "Bob")
+	}
+}
+
+func TestCheckerMoveSortedDice(t *testing.T) {
+	tests := []struct {
+		dice, want [2]int32
+	}{
+		{[2]int32{1, 5}, [2]int32{5, 1}},
+		{[2]int32{5, 1}, [2]int32{5, 1}},
+		{[2]int32{4, 4}, [2]int32{4, 4}},
+	}
+	for _, tt := range tests {
+		move := &CheckerMove{Dice: tt.dice}
+		if got := move.SortedDice(); got != tt.want {
+			t.Errorf("SortedDice() of %v = %v, want %v", tt.dice, got, tt.want)
+		}
+		if move.Dice != tt.dice {
+			t.Errorf("SortedDice() changed Dice to %v", move.Dice)
+		}
+	}
+}
+
+func TestCheckerAnalysisMovePairs(t *testing.T) {
+	tests := []struct {
+		move [8]int8
+		want [][2]int
+	}{
+		{[8]int8{24, 18, 18, 13, -1, -1, -1, -1}, [][2]int{{24, 18}, {18, 13}}}, // 24/18/13
+		{[8]int8{25, 22, 6, -2, -1, -1, -1, -1}, [][2]int{{25, 22}, {6, 0}}},
+		{ParseMoveNotation("6/2(2) 5/1(2)"), [][2]int{{6, 2}, {6, 2}, {5, 1}, {5, 1}}},
+	}
+	for _, tt := range tests {
+		a := CheckerAnalysis{Move: tt.move}
+		if got := a.MovePairs(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("MovePairs() of %v = %v, want %v", tt.move, got, tt.want)
+		}
+	}
+
+	empty := CheckerAnalysis{Move: [8]int8{-1, -1, -1, -1, -1, -1, -1, -1}}
+	if got := empty.MovePairs(); len(got) != 0 {
+		t.Errorf("MovePairs() of an empty move = %v", got)
+	}
+}
+
+func TestCheckerAnalysisPositionPlausible(t *testing.T) {
+	// Bear-off with O on roll, whose move is applied after a perspective swap
+	input := "XGID=-A--B-DCC----A------bbbcdA:1:1:-1:41:0:0:0:7:10\n" +
+		"\n" +
+		"O to play 41\n" +
+		"\n" +
+		"    1. 4-ply       4/3 4/Off                    eq:+0.123\n"
+
+	move, _, err := ParseXGIDFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseXGIDFromReader() error = %v", err)
+	}
+	if len(move.Analysis) != 1 {
+		t.Fatalf("len(Analysis) = %d, want 1", len(move.Analysis))
+	}
+	analysis := move.Analysis[0]
+	if !analysis.PositionPlausible(move.Position) {
+		t.Errorf("PositionPlausible() = false for %v", analysis.Position.Checkers)
+	}
+
+	extra := analysis
+	extra.Position.Checkers[12]-- // A 16th checker for X, the opponent
+	if extra.PositionPlausible(move.Position) {
+		t.Errorf("PositionPlausible() = true with 16 checkers")
+	}
+
+	bar := analysis
+	bar.Position.Checkers[0] = 1
+	if bar.PositionPlausible(move.Position) {
+		t.Errorf("PositionPlausible() = true with a positive checker on the opponent's bar")
+	}
+
+	// The checker borne off was taken from the opponent's side
+	wrong := analysis
+	wrong.Position = move.Position
+	wrong.Position.Checkers[24]++
+	if wrong.PositionPlausible(move.Position) {
+		t.Errorf("PositionPlausible() = true with the opponent's checker borne off")
+	}
+
+	// Both sides keep 15 checkers although the move bears one off
+	start := StartingPosition()
+	full := CheckerAnalysis{Position: start, Move: [8]int8{6, -2, -1, -1, -1, -1, -1, -1}}
+	if full.PositionPlausible(start) {
+		t.Errorf("PositionPlausible() = true after bearing off with 15 checkers left")
+	}
+	full.Move = [8]int8{8, 2, -1, -1, -1, -1, -1, -1}
+	full.Position = ApplyMove(start, full.Move, Player1)
+	if !full.PositionPlausible(start) {
+		t.Errorf("PositionPlausible() = false for 8/2 from the opening position")
+	}
+}
+
+func TestCheckerAnalysisEquityFor(t *testing.T) {
+	a := CheckerAnalysis{Equity: 0.375}
+	tests := []struct {
+		player, activePlayer int32
+		want                 float32
+	}{
+		{1, 1, 0.375},
+		{-1, 1, -0.375},
+		{1, -1, -0.375},
+		{-1, -1, 0.375},
+	}
+	for _, tt := range tests {
+		if got := a.EquityFor(tt.player, tt.activePlayer); got != tt.want {
+			t.Errorf("EquityFor(%d, %d) = %v, want %v", tt.player, tt.activePlayer, got, tt.want)
+		}
+	}
+}
+
+func TestCheckerAnalysisFromOpponentPerspective(t *testing.T) {
+	a := CheckerAnalysis{
+		Move:              [8]int8{8, 5, 6, 5, -1, -1, -1, -1},
+		Player1WinRate:    0.625,
+		Player1GammonRate: 0.25,
+		Player1BgRate:     0.03125,
+		Player2GammonRate: 0.125,
+		Player2BgRate:     0.0078125,
+		Equity:            0.375,
+		AnalysisDepth:     3,
+	}
+
+	opp := a.FromOpponentPerspective()
+	if opp.Player1WinRate != 0.375 || opp.Equity != -0.375 {
+		t.Errorf("win rate = %v, equity = %v, want 0.375, -0.375", opp.Player1WinRate, opp.Equity)
+	}
+	if opp.Player1GammonRate != 0.125 || opp.Player2GammonRate != 0.25 {
+		t.Errorf("gammon rates = %v, %v, want 0.125, 0.25", opp.Player1GammonRate, opp.Player2GammonRate)
+	}
+	if opp.Player1BgRate != 0.0078125 || opp.Player2BgRate != 0.03125 {
+		t.Errorf("backgammon rates = %v, %v, want 0.0078125, 0.03125", opp.Player1BgRate, opp.Player2BgRate)
+	}
+	if opp.Move != a.Move || opp.AnalysisDepth != a.AnalysisDepth {
+		t.Errorf("Move or AnalysisDepth changed: %+v", opp)
+	}
+
+	if back := opp.FromOpponentPerspective(); back != a {
+		t.Errorf("double inversion = %+v, want %+v", back, a)
+	}
+}
+
+func TestGameScoreAfter(t *testing.T) {
+	g := Game{InitialScore: [2]int32{1, 3}, Winner: Player1, PointsWon: 2}
+	if got := g.ScoreAfter(); got != [2]int32{3, 3} {
+		t.Errorf("ScoreAfter() = %v, want [3 3] when player 1 wins 2 points", got)
+	}
+
+	g.Winner = Player2
+	if got := g.ScoreAfter(); got != [2]int32{1, 5} {
+		t.Errorf("ScoreAfter() = %v, want [1 5] when player 2 wins 2 points", got)
+	}
+
+	g.Winner = 0
+	if got := g.ScoreAfter(); got != g.InitialScore {
+		t.Errorf("ScoreAfter() = %v, want %v for an incomplete game", got, g.InitialScore)
+	}
+}
+
+func TestGameLuckByPlayer(t *testing.T) {
+	move := func(activeP int32, luck float64) []byte {
+		rec := testMoveRecord(activeP, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1})
+		putFloat64(rec, offMEErrLuck, luck)
+		return rec
+	}
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		move(Player1, 0.25),
+		move(Player2, -0.5),
+		testCubeRecord(Player1, 1, 1, 1),
+		move(Player1, 0.125),
+		move(Player2, 0.0625),
+		testGameFooter(Player1, 2),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+
+	game := match.Games[0]
+	if got := game.Moves[0].CheckerMove.Luck; got != 0.25 {
+		t.Errorf("Luck = %v, want 0.25", got)
+	}
+	if p1, p2 := game.LuckByPlayer(); p1 != 0.375 || p2 != -0.4375 {
+		t.Errorf("LuckByPlayer() = %v, %v, want 0.375, -0.4375", p1, p2)
+	}
+	if p1, p2 := (&Game{}).LuckByPlayer(); p1 != 0 || p2 != 0 {
+		t.Errorf("LuckByPlayer() = %v, %v for a game without moves, want 0, 0", p1, p2)
+	}
+}
+
+// TestPlayerSides checks that the helpers reading ActivePlayer and those
+// reading Winner agree on which player is which: the player who rolled all
+// the luck wins the game and the match.
+func TestPlayerSides(t *testing.T) {
+	move := func(activeP int32, luck float64) []byte {
+		rec := testMoveRecord(activeP, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1})
+		putFloat64(rec, offMEErrLuck, luck)
+		putMoveCandidate(rec, 0, [8]int8{7, 4, 5, 4, -1, -1, -1, -1}, [7]float32{0, 0, 0.5, 0, 0, 0, 0.25}, 2)
+		return rec
+	}
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 1),
+		testGameHeader(1, 0, 0),
+		move(Player1, -0.5),
+		move(Player2, 0.75),
+		testGameFooter(Player2, 1),
+		testMatchFooter(0, 1, Player2),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	game := &match.Games[0]
+
+	p1, p2 := game.LuckByPlayer()
+	if p1 >= 0 || p2 <= 0 {
+		t.Fatalf("LuckByPlayer() = %v, %v, want Bob to be the lucky player", p1, p2)
+	}
+	if got := game.ScoreAfter(); got != [2]int32{0, 1} {
+		t.Errorf("ScoreAfter() = %v, want [0 1] for Bob", got)
+	}
+	if winner, _ := match.Result(); winner != "Bob" {
+		t.Errorf("Result() winner = %q, want Bob", winner)
+	}
+	match.Winner = 0
+	if winner, _ := match.Result(); winner != "Bob" {
+		t.Errorf("Result() winner = %q from the score, want Bob", winner)
+	}
+
+	lucky := game.Moves[1].CheckerMove
+	if row := match.csvRow(game, 1, &game.Moves[1]); row[2] != "Bob" {
+		t.Errorf("csvRow() player = %q, want Bob", row[2])
+	}
+	if got := lucky.Analysis[0].EquityFor(game.Winner, lucky.ActivePlayer); got != 0.25 {
+		t.Errorf("EquityFor(Winner) = %v, want the equity of Bob on roll, 0.25", got)
+	}
+}
+
+func TestMatchResult(t *testing.T) {
+	segments := testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 3),
+		testGameHeader(1, 0, 0),
+		testGameFooter(Player1, 2),
+		testGameHeader(2, 2, 0),
+		testGameFooter(Player2, 1),
+		testGameHeader(3, 2, 1),
+		testGameFooter(Player1, 1),
+		testMatchFooter(3, 1, Player1),
+	)
+	match, err := ParseXG(segments)
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+
+	winner, score := match.Result()
+	if winner != "Alice" || score != [2]int32{3, 1} {
+		t.Errorf("Result() = %q, %v, want Alice, [3 1]", winner, score)
+	}
+
+	// Without the match footer the winner follows from the match length
+	match.Winner = 0
+	if winner, _ := match.Result(); winner != "Alice" {
+		t.Errorf("Result() winner = %q without footer, want Alice", winner)
+	}
+
+	// An unfinished match has no winner yet
+	match.Games = match.Games[:2]
+	if winner, score := match.Result(); winner != "" || score != [2]int32{2, 1} {
+		t.Errorf("Result() = %q, %v for an unfinished match, want \"\", [2 1]", winner, score)
+	}
+}
+
+func TestMatchIsComplete(t *testing.T) {
+	completed, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 3),
+		testGameHeader(1, 0, 0),
+		testGameFooter(Player1, 2),
+		testGameHeader(2, 2, 0),
+		testGameFooter(Player1, 1),
+		testMatchFooter(3, 0, Player1),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	if !completed.IsComplete() {
+		t.Errorf("IsComplete() = false for a completed match")
+	}
+
+	// Saved during game 2: no game or match footer
+	inProgress := testGameHeader(2, 2, 0)
+	inProgress[offHGInProgress] = 1
+	partial, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 3),
+		testGameHeader(1, 0, 0),
+		testGameFooter(Player1, 2),
+		inProgress,
+		testMoveRecord(1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1}),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	if len(partial.Games) != 2 || partial.IsComplete() {
+		t.Errorf("IsComplete() = %v with %d games for a match in progress, want false with 2", partial.IsComplete(), len(partial.Games))
+	}
+
+	// Money sessions are complete only with a match footer
+	money := &Match{Games: []Game{{Winner: Player1, PointsWon: 4}}}
+	if money.IsComplete() {
+		t.Errorf("IsComplete() = true for a money session without footer")
+	}
+}
+
+func TestParseXG_InProgress(t *testing.T) {
+	unfinished := testGameHeader(2, 2, 0)
+	unfinished[offHGInProgress] = 1
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		testGameFooter(Player1, 2),
+		unfinished,
+		testMoveRecord(1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1}),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	if len(match.Games) != 2 {
+		t.Fatalf("len(Games) = %d, want 2", len(match.Games))
+	}
+	if match.Games[0].InProgress || !match.Games[1].InProgress {
+		t.Errorf("InProgress = %v, %v, want false, true", match.Games[0].InProgress, match.Games[1].InProgress)
+	}
+}
+
+func TestParseXG_CubeOwner(t *testing.T) {
+	// Decisions XG did not analyse have a zeroed analysis, cube value included
+	unanalysed := func(rec []byte) []byte {
+		if rec[8] == 2 {
+			putInt32(rec, offCEDCube, 0)
+		} else {
+			putInt32(rec, offMEDCube, 0)
+		}
+		return rec
+	}
+	// The owner in the analysis of the file wins over the tracked one
+	analysed := testMoveRecord(1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1})
+	putInt32(analysed, offMEDCubePos, -1)
+
+	segments := testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 7),
+		testGameHeader(1, 0, 0),
+		unanalysed(testCubeRecord(1, 1, 1, 1)), // Player 1 doubles, player 2 takes
+		unanalysed(testMoveRecord(-1, [2]int32{6, 4}, [8]int32{23, 17, 17, 13, -1, -1, -1, -1})),
+		unanalysed(testCubeRecord(-1, 1, 1, 2)), // Player 2 redoubles, player 1 takes
+		unanalysed(testCubeRecord(1, 1, 0, 4)),  // Player 1 redoubles, player 2 passes
+		testGameFooter(Player1, 4),
+		testGameHeader(2, 4, 0),
+		unanalysed(testCubeRecord(-1, 1, 2, 1)), // Player 2 doubles, player 1 beavers
+		unanalysed(testCubeRecord(-1, 0, 0, 4)), // The cube is back with player 2
+		analysed,
+		testGameFooter(1, 4),
+	)
+	match, err := ParseXG(segments)
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+
+	// Owners in absolute terms: 1 for player 1, -1 for player 2
+	want := [][]int32{{0, -1, -1, 1}, {0, -1, -1}}
+	for g, owners := range want {
+		moves := match.Games[g].Moves
+		if len(moves) != len(owners) {
+			t.Fatalf("game %d: %d moves, want %d", g+1, len(moves), len(owners))
+		}
+		for i, owner := range owners {
+			var got int32
+			if c := moves[i].CubeMove; c != nil {
+				got = c.Position.Absolute(c.ActivePlayer).CubePos
+			} else {
+				c := moves[i].CheckerMove
+				got = c.Position.Absolute(c.ActivePlayer).CubePos
+			}
+			if got != owner {
+				t.Errorf("game %d move %d: owner = %d, want %d", g+1, i+1, got, owner)
+			}
+		}
+	}
+}
+
+func TestMatchToSummaryJSON(t *testing.T) {
+	move := testMoveRecord(1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1})
+	putMoveCandidate(move, 0, [8]int8{8, 5, 6, 5, -1, -1, -1, -1}, [7]float32{0, 0, 0.5, 0, 0, 0, 0.1}, 2)
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		move,
+		testCubeRecord(-1, 1, 1, 1),
+		testGameFooter(1, 2),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+
+	data, err := match.ToSummaryJSON()
+	if err != nil {
+		t.Fatalf("ToSummaryJSON() error = %v", err)
+	}
+	for _, field := range []string{`"moves"`, `"analysis"`, `"checker_move"`} {
+		if strings.Contains(string(data), field) {
+			t.Errorf("ToSummaryJSON() contains %s:\n%s", field, data)
+		}
+	}
+
+	var summary struct {
+		Metadata MatchMetadata `json:"metadata"`
+		Games    []struct {
+			Winner       int32 `json:"winner"`
+			PointsWon    int32 `json:"points_won"`
+			CheckerMoves int   `json:"checker_moves"`
+			CubeMoves    int   `json:"cube_moves"`
+		} `json:"games"`
+	}
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if summary.Metadata.Player1Name != "Alice" || summary.Metadata.MatchLength != 5 {
+		t.Errorf("Metadata = %+v", summary.Metadata)
+	}
+	if len(summary.Games) != 1 {
+		t.Fatalf("len(Games) = %d, want 1", len(summary.Games))
+	}
+	if g := summary.Games[0]; g.Winner != 1 || g.PointsWon != 2 || g.CheckerMoves != 1 || g.CubeMoves != 1 {
+		t.Errorf("Games[0] = %+v, want winner 1, 2 points, 1 checker move and 1 cube move", g)
+	}
+}
+
+func TestMatchClone(t *testing.T) {
+	move := testMoveRecord(1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1})
+	putMoveCandidate(move, 0, [8]int8{8, 5, 6, 5, -1, -1, -1, -1}, [7]float32{0, 0, 0.5, 0, 0, 0, 0.1}, 2)
+	putMoveCandidate(move, 1, [8]int8{24, 21, 24, 23, -1, -1, -1, -1}, [7]float32{0, 0, 0.4, 0, 0, 0, -0.1}, 2)
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		move,
+		testCubeRecord(-1, 1, 1, 1),
+		testGameFooter(1, 2),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	before, err := json.Marshal(match)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	clone := match.Clone()
+	if !reflect.DeepEqual(clone, match) {
+		t.Fatalf("Clone() = %+v, want %+v", clone, match)
+	}
+
+	game := &clone.Games[0]
+	analysis := game.Moves[0].CheckerMove.Analysis
+	analysis[0], analysis[1] = analysis[1], analysis[0]
+	game.Moves[0].CheckerMove.Dice[0] = 6
+	game.Moves[1].CubeMove.Analysis.CubefulNoDouble = 1
+	game.Moves[1].Comment = "changed"
+	game.Moves = append(game.Moves, Move{MoveType: "cube"})
+	clone.Metadata.Player1Name = "Carol"
+
+	after, err := json.Marshal(match)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("modifying the clone changed the original:\n%s\nwant\n%s", after, before)
+	}
+}
+
+func TestMatchIsPostCrawford(t *testing.T) {
+	hm := testMatchHeader("Alice", "Bob", 5)
+	hm[offHMCrawford] = 1
+	segments := testGameFileSegments(
+		hm,
+		testGameHeader(1, 0, 0),
+		testGameFooter(-1, 4),
+		testGameHeader(2, 4, 0), // Crawford game
+		testGameFooter(1, 2),
+		testGameHeader(3, 4, 2),
+		testGameFooter(1, 1),
+		testGameHeader(4, 4, 3),
+		testGameFooter(-1, 1),
+	)
+	match, err := ParseXG(segments)
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	if !match.Metadata.CrawfordRule {
+		t.Fatalf("CrawfordRule = false")
+	}
+
+	for i, want := range []bool{false, false, true, true} {
+		if got := match.IsPostCrawford(i); got != want {
+			t.Errorf("IsPostCrawford(%d) = %v, want %v", i, got, want)
+		}
+	}
+	if match.IsPostCrawford(4) || match.IsPostCrawford(-1) {
+		t.Errorf("IsPostCrawford() = true for an index out of range")
+	}
+
+	match.Metadata.CrawfordRule = false
+	if match.IsPostCrawford(2) {
+		t.Errorf("IsPostCrawford(2) = true without the Crawford rule")
+	}
+}
+
+func TestParseXG_AbsolutePosition(t *testing.T) {
+	board := StartingPosition().Checkers // Absolute frame, X positive
+
+	// O plays 31 as 8/5 6/5 from its own point of view
+	played := ParseMoveNotation("8/5 6/5")
+	rec := testMoveRecord(-1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1})
+	putPosition(rec, offMEPositionI, board)
+	putMoveCandidate(rec, 0, [8]int8{7, 4, 5, 4, -1, -1, -1, -1}, [7]float32{0, 0, 0.5, 0, 0, 0, 0.1}, 2)
+	after := ApplyMove(Position{Checkers: swapPositionCheckers(board)}, played, 1)
+	putPosition(rec, offMEDPosPlayed, after.Checkers)
+
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		rec,
+		testGameFooter(1, 1),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+
+	move := match.Games[0].Moves[0].CheckerMove
+	if initial := move.Position.Absolute(move.ActivePlayer); initial.Checkers != board {
+		t.Fatalf("initial absolute board = %v, want %v", initial.Checkers, board)
+	}
+
+	// The same play applied to the absolute board by the negative checkers
+	want := ApplyMove(Position{Checkers: board}, mirrorMove(played), -1).Checkers
+	if got := move.Analysis[0].AbsolutePosition.Checkers; got != want {
+		t.Errorf("AbsolutePosition = %v, want %v", got, want)
+	}
+}
+
+func TestParseXG_MatchAndGameComments(t *testing.T) {
+	hm := testMatchHeader("Alice", "Bob", 5)
+	putInt32(hm, offHMCommentHdr, 0)
+	hg := testGameHeader(1, 0, 0)
+	putInt32(hg, offHGCommentFtr, 2)
+
+	segments := testGameFileSegments(hm, hg, testGameFooter(1, 1))
+	segments = append(segments, &Segment{
+		Type: SegmentXGComment,
+		Data: []byte(`{\rtf1\ansi Club final\par}` + "\r\n" + "move comment\r\n" + `{\rtf1\ansi Well played\par}` + "\r\n"),
+	})
+
+	match, err := ParseXG(segments)
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	if match.Metadata.HeaderComment != "Club final" || match.Metadata.FooterComment != "" {
+		t.Errorf("match comments = %q, %q, want %q, \"\"", match.Metadata.HeaderComment, match.Metadata.FooterComment, "Club final")
+	}
+	game := match.Games[0]
+	if game.HeaderComment != "" || game.FooterComment != "Well played" {
+		t.Errorf("game comments = %q, %q, want \"\", %q", game.HeaderComment, game.FooterComment, "Well played")
+	}
+}
+
+func TestParseXGMulti(t *testing.T) {
+	segments := testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 3),
+		testGameHeader(1, 0, 0),
+		testGameFooter(Player1, 2),
+		testGameHeader(2, 2, 0),
+		testGameFooter(Player1, 1),
+		testMatchFooter(3, 0, Player1),
+		testMatchHeader("Carol", "Dave", 5),
+		testGameHeader(1, 0, 0),
+		testGameFooter(1, 1),
+	)
+
+	matches, err := ParseXGMulti(segments)
+	if err != nil {
+		t.Fatalf("ParseXGMulti() error = %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("len(matches) = %d, want 2", len(matches))
+	}
+	if m := matches[0]; m.Metadata.Player1Name != "Alice" || m.Metadata.MatchLength != 3 || len(m.Games) != 2 || m.Winner != Player1 {
+		t.Errorf("match 1 = %s, length %d, %d games, winner %d", m.Metadata.Player1Name, m.Metadata.MatchLength, len(m.Games), m.Winner)
+	}
+	if m := matches[1]; m.Metadata.Player1Name != "Carol" || m.Metadata.MatchLength != 5 || len(m.Games) != 1 || m.Winner != 0 {
+		t.Errorf("match 2 = %s, length %d, %d games, winner %d", m.Metadata.Player1Name, m.Metadata.MatchLength, len(m.Games), m.Winner)
+	}
+
+	// ParseXG keeps merging everything into one match
+	match, err := ParseXG(segments)
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	if len(match.Games) != 3 || match.Metadata.Player1Name != "Carol" {
+		t.Errorf("ParseXG() = %s with %d games, want Carol with 3", match.Metadata.Player1Name, len(match.Games))
+	}
+}
+
+func TestParseXG_MoneyMatch(t *testing.T) {
+	hm := testMatchHeader("Alice", "Bob", 0)
+	putInt32(hm, offHMSiteId, 7)
+	putInt32(hm, offHMCurrency, 2)
+	putFloat32(hm, offHMWinMoney, 10)
+	putFloat32(hm, offHMLoseMoney, 12.5)
+	putFloat32(hm, offHMFeeMoney, 0.5)
+	putInt32(hm, offHMTableStake, 5)
+
+	match, err := ParseXG(testGameFileSegments(hm, testGameHeader(1, 0, 0), testGameFooter(1, 2)))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	m := match.Metadata
+	if m.SiteID != 7 || m.Currency != 2 || m.WinMoney != 10 || m.LoseMoney != 12.5 || m.FeeMoney != 0.5 || m.TableStake != 5 {
+		t.Errorf("money fields = site %d, currency %d, win %v, lose %v, fee %v, stake %d",
+			m.SiteID, m.Currency, m.WinMoney, m.LoseMoney, m.FeeMoney, m.TableStake)
+	}
+}
+
+func TestParseXG_GameID(t *testing.T) {
+	hm := testMatchHeader("Alice", "Bob", 5)
+	putInt32(hm, offHMGameId, 4242)
+
+	match, err := ParseXG(testGameFileSegments(hm, testGameHeader(1, 0, 0), testGameFooter(1, 2)))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	if match.Metadata.GameID != 4242 {
+		t.Errorf("GameID = %d, want 4242", match.Metadata.GameID)
+	}
+}
+
+func TestParseXGFromFile_Magic(t *testing.T) {
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0), testGameFooter(1, 2))
+	match, err := ParseXGFromFile(writeTestXGFile(t, buildTestXGFile(gameFile)))
+	if err != nil {
+		t.Fatalf("ParseXGFromFile() error = %v", err)
+	}
+	// "DMLI" read as a little-endian uint32
+	if match.Metadata.Magic != 0x494c4d44 || match.Metadata.EngineVersion != 30 {
+		t.Errorf("Magic, EngineVersion = %#x, %d, want 0x494c4d44, 30", match.Metadata.Magic, match.Metadata.EngineVersion)
+	}
+}
+
+func TestParseXG_MatchFooters(t *testing.T) {
+	// A file saved again keeps the stale footer of its first save
+	stale := testMatchFooter(2, 1, Player2)
+	putFloat64(stale, offFMElo1, 1490)
+	putFloat64(stale, offFMElo2, 1510)
+	last := testMatchFooter(3, 1, Player1)
+	putFloat64(last, offFMElo1, 1512.5)
+	putFloat64(last, offFMElo2, 1487.5)
+
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 3),
+		testGameHeader(1, 0, 0),
+		testGameFooter(Player1, 3),
+		stale,
+		last,
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	if match.Winner != Player1 {
+		t.Errorf("Winner = %d, want %d", match.Winner, Player1)
+	}
+	if got := match.Metadata.FinalScore; got != [2]int32{3, 1} {
+		t.Errorf("FinalScore = %v, want [3 1]", got)
+	}
+	if got := match.Metadata.FinalElo; got != [2]float64{1512.5, 1487.5} {
+		t.Errorf("FinalElo = %v, want [1512.5 1487.5]", got)
+	}
+}
+
+func TestParseXG_RolloutSettings(t *testing.T) {
+	move := testMoveRecord(1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1})
+	putMoveCandidate(move, 0, [8]int8{8, 5, 6, 5, -1, -1, -1, -1}, [7]float32{}, 3)
+	putMoveCandidate(move, 1, [8]int8{24, 21, 24, 23, -1, -1, -1, -1}, [7]float32{}, 3)
+	putInt32(move, offMERolloutIdx+4*1, 0) // Only candidate 1 was rolled out
+	cube := testCubeRecord(-1, 1, 1, 1)
+	putInt32(cube, offCERolloutIdx, 1)
+
+	segments := testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		move,
+		cube,
+		testGameFooter(1, 2),
+	)
+	segments = append(segments, &Segment{
+		Type:     SegmentXGRollouts,
+		Data:     bytes.Join([][]byte{testRolloutRecord(1296, 0), testRolloutRecord(648, 7)}, nil),
+		Filename: "temp.xgr",
+	})
+
+	match, err := ParseXG(segments)
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	moves := match.Games[0].Moves
+
+	analysis := moves[0].CheckerMove.Analysis
+	if analysis[0].Rollout != nil {
+		t.Errorf("candidate 0 Rollout = %+v, want nil", analysis[0].Rollout)
+	}
+	if r := analysis[1].Rollout; r == nil || r.Trials != 1296 || r.Truncated || r.TruncationPly != 0 || !r.VarianceReduction {
+		t.Errorf("candidate 1 Rollout = %+v, want 1296 untruncated trials with variance reduction", r)
+	}
+	if r := moves[1].CubeMove.Analysis.Rollout; r == nil || r.Trials != 648 || !r.Truncated || r.TruncationPly != 7 {
+		t.Errorf("cube Rollout = %+v, want 648 trials truncated at 7 plies", r)
+	}
+
+	// Without the rollout segment the analyses have no settings
+	match, err = ParseXG(segments[:1])
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	if r := match.Games[0].Moves[1].CubeMove.Analysis.Rollout; r != nil {
+		t.Errorf("cube Rollout without rollout segment = %+v, want nil", r)
+	}
+}
+
+func TestParseXG_InitialPosition(t *testing.T) {
+	// Nackgammon: two back checkers on each of the 24 and 23 points
+	var nack [26]int8
+	nack[24], nack[23], nack[13], nack[8], nack[6] = 2, 2, 4, 3, 4
+	nack[1], nack[2], nack[12], nack[17], nack[19] = -2, -2, -4, -3, -4
+
+	hm := testMatchHeader("Alice", "Bob", 5)
+	putInt32(hm, offHMVariation, 1)
+	hg := testGameHeader(1, 0, 0)
+	putPosition(hg, offHGPosInit, nack)
+
+	match, err := ParseXG(testGameFileSegments(hm, hg, testGameFooter(1, 1)))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	got := match.Games[0].InitialPosition
+	if got != nack {
+		t.Errorf("InitialPosition = %v, want %v", got, nack)
+	}
+	if got == StartingPosition().Checkers {
+		t.Errorf("InitialPosition of a Nackgammon game is the standard opening")
+	}
+}
+
+// countingReaderAt is a ReaderAt counting the bytes read, like a client
+// fetching byte ranges over HTTP
+type countingReaderAt struct {
+	r    *bytes.Reader
+	read int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.read += int64(n)
+	return n, err
+}
+
+func TestParseXGFromReaderAt(t *testing.T) {
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0), testGameFooter(1, 1))
+	rollouts := make([]byte, 1<<20)
+	for i := range rollouts {
+		rollouts[i] = byte(i * 7) // Compresses poorly, as real rollouts
+	}
+	data := buildTestXGFile(gameFile, testArchiveFile{name: "temp.xgr", data: rollouts, stored: true})
+
+	ra := &countingReaderAt{r: bytes.NewReader(data)}
+	match, err := ParseXGFromReaderAt(ra, int64(len(data)))
+	if err != nil {
+		t.Fatalf("ParseXGFromReaderAt() error = %v", err)
+	}
+	if match.Metadata.Player1Name != "Alice" || match.Metadata.ProductVersion != "eXtreme Gammon 2.19" || len(match.Games) != 1 {
+		t.Errorf("match = %+v", match)
+	}
+	if ra.read >= int64(len(rollouts)) {
+		t.Errorf("read %d bytes of a %d byte file, want the rollouts skipped", ra.read, len(data))
+	}
+
+	// Headerless archives are read too
+	bare := buildTestArchive(testArchiveFile{name: "temp.xg", data: gameFile})
+	if _, err := ParseXGFromReaderAt(bytes.NewReader(bare), int64(len(bare))); err != nil {
+		t.Errorf("ParseXGFromReaderAt() headerless error = %v", err)
+	}
+	text := []byte("XGID=-b----E-C---eE---c-e----B-:0:0:1:52:0:0:0:13:10\n")
+	if _, err := ParseXGFromReaderAt(bytes.NewReader(text), int64(len(text))); !errors.Is(err, ErrNotXGFile) {
+		t.Errorf("ParseXGFromReaderAt() error = %v, want ErrNotXGFile", err)
+	}
+}
+
+func TestParseXGFromArchive(t *testing.T) {
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0),
+		testMoveRecord(1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1}), testGameFooter(-1, 1))
+	data := buildTestXGFile(gameFile)
+
+	want, err := ParseXGFromReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseXGFromReader() error = %v", err)
+	}
+
+	za, err := NewZlibArchive(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewZlibArchive() error = %v", err)
+	}
+	match, err := ParseXGFromArchive(za)
+	if err != nil {
+		t.Fatalf("ParseXGFromArchive() error = %v", err)
+	}
+
+	// Only the product version of the GDF header is missing
+	if match.Metadata.ProductVersion != "" {
+		t.Errorf("ProductVersion = %q, want empty", match.Metadata.ProductVersion)
+	}
+	match.Metadata.ProductVersion = want.Metadata.ProductVersion
+	if !reflect.DeepEqual(match, want) {
+		t.Errorf("ParseXGFromArchive() = %+v, want %+v", match, want)
+	}
+}
+
+// forwardOnlySeeker is a ReadSeeker whose Seek does not move: it only records
+// the calls other than rewinds to the start, like wrappers over streams that
+// cannot go back
+type forwardOnlySeeker struct {
+	r     *bytes.Reader
+	seeks int
+}
+
+func (f *forwardOnlySeeker) Read(p []byte) (int, error) {
+	return f.r.Read(p)
+}
+
+func (f *forwardOnlySeeker) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekStart {
+		f.seeks++
+	}
+	return 0, nil
+}
+
+func TestParseXGFromReader_ForwardOnly(t *testing.T) {
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0), testGameFooter(1, 1))
+	data := buildTestXGFile(gameFile)
+
+	r := &forwardOnlySeeker{r: bytes.NewReader(data)}
+	match, err := ParseXGFromReader(r)
+	if err != nil {
+		t.Fatalf("ParseXGFromReader() error = %v", err)
+	}
+	if match.Metadata.Player1Name != "Alice" || len(match.Games) != 1 {
+		t.Errorf("ParseXGFromReader() = %+v", match)
+	}
+	if r.seeks != 0 {
+		t.Errorf("Seek called %d times, want the stream read once without seeking", r.seeks)
+	}
+
+	r = &forwardOnlySeeker{r: bytes.NewReader(data)}
+	match, err = Parse(r, WithMetadataOnly())
+	if err != nil {
+		t.Fatalf("Parse(WithMetadataOnly) error = %v", err)
+	}
+	if match.Metadata.Player2Name != "Bob" || r.seeks != 0 {
+		t.Errorf("Parse(WithMetadataOnly) = %+v with %d seeks", match.Metadata, r.seeks)
+	}
+}
+
+func TestParseXGFromReader_Limit(t *testing.T) {
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0), testGameFooter(1, 1))
+	data := buildTestXGFile(gameFile)
+
+	r := &forwardOnlySeeker{r: bytes.NewReader(data)}
+	if _, err := Parse(r, WithMaxBytes(64)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Parse(WithMaxBytes) error = %v, want ErrTooLarge", err)
+	}
+	if read := int64(len(data)) - int64(r.r.Len()); read > 65 {
+		t.Errorf("Parse(WithMaxBytes) read %d bytes, want at most 65", read)
+	}
+}
+
+func TestParseXGFromReader_Offset(t *testing.T) {
+	gameFile := testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0), testGameFooter(1, 1))
+	data := buildTestXGFile(gameFile)
+
+	// A reader already read from is parsed from its start, whether it is
+	// used as-is or buffered
+	advanced := bytes.NewReader(data)
+	advanced.Seek(100, io.SeekStart)
+	readers := map[string]io.ReadSeeker{
+		"bytes.Reader": advanced,
+		"buffered":     struct{ io.ReadSeeker }{bytes.NewReader(data)},
+	}
+	readers["buffered"].Seek(100, io.SeekStart)
+
+	for name, r := range readers {
+		match, err := ParseXGFromReader(r)
+		if err != nil {
+			t.Fatalf("%s: ParseXGFromReader() error = %v", name, err)
+		}
+		if match.Metadata.Player1Name != "Alice" || len(match.Games) != 1 {
+			t.Errorf("%s: ParseXGFromReader() = %+v", name, match)
+		}
+	}
+}
+
+func TestFindDuplicateGames(t *testing.T) {
+	opening := testMoveRecord(1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1})
+	reply := testMoveRecord(-1, [2]int32{6, 4}, [8]int32{23, 17, 17, 13, -1, -1, -1, -1})
+	other := testMoveRecord(-1, [2]int32{6, 4}, [8]int32{23, 13, -1, -1, -1, -1, -1, -1})
+
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0), opening, reply, testGameFooter(-1, 1),
+		testGameHeader(2, 1, 0), opening, reply, testGameFooter(1, 1),
+		testGameHeader(3, 1, 0), opening, reply, testGameFooter(1, 1), // Duplicate of game 2
+		testGameHeader(4, 1, 0), opening, other, testGameFooter(1, 1),
+		testGameHeader(5, 1, 0), opening, reply, testGameFooter(1, 1), // Duplicate of game 2
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+
+	got := match.FindDuplicateGames()
+	if len(got) != 2 || got[0] != 2 || got[1] != 4 {
+		t.Errorf("FindDuplicateGames() = %v, want [2 4]", got)
+	}
+}
+
+func TestParseXG_EvalRatesMatchText(t *testing.T) {
+	// Eval arrays of the positions of XG's text exports, as the raw
+	// little-endian float32 bytes of the binary records. Decoding them must
+	// give the rates printed in the exports.
+	cubeEval := "99bb963bb98d063e02bcc53e000000005a647b3e75029a3c48e1ba3e"
+	moveEvals := []string{
+		"faed6b3a492e9f3e17d93e3f0000000000000000000000005a64fbbe",
+		"52499d3ac66db43e9031473f00000000000000000000000004560ebf",
+	}
+
+	textMove, _, err := ParseXGIDFile("../test/2025-11-04/01_checkerPosition_EN.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	textCube, _, err := ParseXGIDCubeFile("../test/2025-11-04/03_DT_EN.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	ce := testCubeRecord(1, 0, 0, 1)
+	copy(ce[offCEDEval:], raw(cubeEval))
+	rec := testMoveRecord(1, [2]int32{2, 1}, [8]int32{18, 17, 13, 11, -1, -1, -1, -1})
+	for i, s := range moveEvals {
+		putMoveCandidate(rec, i, [8]int8{-1, -1, -1, -1, -1, -1, -1, -1}, [7]float32{}, 4)
+		copy(rec[offMEDEval+28*i:], raw(s))
+	}
+
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 7),
+		testGameHeader(1, 0, 0),
+		ce,
+		rec,
+		testGameFooter(1, 1),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+
+	// The exports print the rates to 0.01%
+	same := func(x, y float32) bool { return math.Abs(float64(x-y)) < 1e-5 }
+
+	binMove := match.Games[0].Moves[1].CheckerMove
+	for i := range moveEvals {
+		got, want := binMove.Analysis[i], textMove.Analysis[i]
+		if !same(got.Player1WinRate, want.Player1WinRate) || !same(got.Player1GammonRate, want.Player1GammonRate) ||
+			!same(got.Player1BgRate, want.Player1BgRate) || !same(got.Player2GammonRate, want.Player2GammonRate) ||
+			!same(got.Player2BgRate, want.Player2BgRate) || !same(got.Equity, want.Equity) {
+			t.Errorf("candidate %d: binary rates %+v, text rates %+v", i, got, want)
+		}
+	}
+
+	got, want := match.Games[0].Moves[0].CubeMove.Analysis, textCube.Analysis
+	if !same(got.Player1WinRate, want.Player1WinRate) || !same(got.Player1GammonRate, want.Player1GammonRate) ||
+		!same(got.Player1BgRate, want.Player1BgRate) || !same(got.Player2GammonRate, want.Player2GammonRate) ||
+		!same(got.Player2BgRate, want.Player2BgRate) || !same(got.CubelessNoDouble, want.CubelessNoDouble) {
+		t.Errorf("cube: binary rates %+v, text rates %+v", got, want)
+	}
+}
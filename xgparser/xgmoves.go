@@ -0,0 +1,218 @@
+//
+//   xgmoves.go - Legal move generation
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+// moveGenerator enumerates the plays of one roll for the player owning the
+// positive checkers, moving from 24 toward 1 with the bar at index 25.
+type moveGenerator struct {
+	plays   [][8]int8
+	dice    [][]int8 // Dice used by each play, same order as plays
+	seen    map[[26]int8]bool
+	maxUsed int
+}
+
+// allHome reports whether all positive checkers are in the home board (1-6)
+func allHome(board *[26]int8) bool {
+	for i := 7; i <= 25; i++ {
+		if board[i] > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// step returns the destination of a checker moving die pips from point from,
+// -2 for bearing off, and whether the step is legal.
+func step(board *[26]int8, from int, die int8) (int8, bool) {
+	if board[from] <= 0 || (board[25] > 0 && from != 25) {
+		return 0, false
+	}
+
+	to := from - int(die)
+	if to >= 1 {
+		return int8(to), board[to] >= -1
+	}
+
+	if !allHome(board) {
+		return 0, false
+	}
+	if to < 0 {
+		// Bearing off with a larger die needs the highest checker
+		for i := from + 1; i <= 6; i++ {
+			if board[i] > 0 {
+				return 0, false
+			}
+		}
+	}
+	return -2, true
+}
+
+// generate plays the remaining dice in order from the given board
+func (g *moveGenerator) generate(board [26]int8, dice []int8, used []int8, move [8]int8) {
+	moved := false
+	for from := 25; from >= 1; from-- {
+		to, ok := step(&board, from, dice[0])
+		if !ok {
+			continue
+		}
+		moved = true
+
+		next := board
+		next[from]--
+		if to != -2 {
+			if next[to] == -1 {
+				next[to] = 0
+				next[0]-- // Hit checker goes to the opponent's bar
+			}
+			next[to]++
+		}
+
+		nextMove := move
+		nextMove[2*len(used)] = int8(from)
+		nextMove[2*len(used)+1] = to
+		nextUsed := append(append([]int8(nil), used...), dice[0])
+
+		if len(dice) > 1 {
+			g.generate(next, dice[1:], nextUsed, nextMove)
+		} else {
+			g.add(next, nextUsed, nextMove)
+		}
+	}
+
+	if !moved {
+		g.add(board, used, move)
+	}
+}
+
+// add records a finished play, keeping one play per resulting position
+func (g *moveGenerator) add(board [26]int8, used []int8, move [8]int8) {
+	if len(used) == 0 || g.seen[board] {
+		return
+	}
+	g.seen[board] = true
+	g.plays = append(g.plays, move)
+	g.dice = append(g.dice, used)
+	if len(used) > g.maxUsed {
+		g.maxUsed = len(used)
+	}
+}
+
+// LegalMoves returns all legal plays of a roll, one per resulting position.
+// Moves use the same encoding as ApplyMove: from/to pairs with 25 for the bar,
+// 1-24 for points, -2 for bearing off and -1 for unused entries.
+// activePlayer selects the checkers to move as in ApplyMove: 1 moves the
+// positive checkers from 24 toward 1 with the bar at 25, -1 moves the negative
+// checkers from 1 toward 24 with the bar at 0. Positions of a Match are seen
+// from the player on roll, so they are played with activePlayer 1.
+//
+// The rules are enforced as in XG: checkers on the bar enter first, doubles
+// are played four times, as many dice as possible must be played, and when
+// only one die of a roll can be played it must be the larger if possible.
+// No play is returned when the player cannot move.
+func (p Position) LegalMoves(dice [2]int32, activePlayer int32) [][8]int8 {
+	board := p.Checkers
+	if activePlayer == Player2 {
+		board = swapPositionCheckers(board)
+	}
+
+	g := &moveGenerator{seen: make(map[[26]int8]bool)}
+	var empty [8]int8
+	for i := range empty {
+		empty[i] = -1
+	}
+
+	d1, d2 := int8(dice[0]), int8(dice[1])
+	if d1 < 1 || d1 > 6 || d2 < 1 || d2 > 6 {
+		return nil
+	}
+	var pips []int8
+	for _, pip := range DicePips(dice) {
+		pips = append(pips, int8(pip))
+	}
+	g.generate(board, pips, nil, empty)
+	if d1 != d2 {
+		g.generate(board, []int8{d2, d1}, nil, empty)
+	}
+
+	// Only one die can be played: the larger one must be used if possible
+	high := d1
+	if d2 > high {
+		high = d2
+	}
+	highPlayable := false
+	if g.maxUsed == 1 && d1 != d2 {
+		for _, used := range g.dice {
+			highPlayable = highPlayable || used[0] == high
+		}
+	}
+
+	var plays [][8]int8
+	for i, play := range g.plays {
+		if len(g.dice[i]) < g.maxUsed || (highPlayable && g.dice[i][0] != high) {
+			continue
+		}
+		if activePlayer == Player2 {
+			play = mirrorMove(play)
+		}
+		plays = append(plays, play)
+	}
+
+	return plays
+}
+
+// DicePips returns the pips to play for a roll: four times the die for
+// doubles, the two dice otherwise
+func DicePips(dice [2]int32) []int {
+	if dice[0] == dice[1] {
+		d := int(dice[0])
+		return []int{d, d, d, d}
+	}
+	return []int{int(dice[0]), int(dice[1])}
+}
+
+// mirrorMove converts a move between the two players' numbering of the board
+// indexes: point i becomes 25-i, so each player's bar maps to the other's index.
+func mirrorMove(move [8]int8) [8]int8 {
+	for i, m := range move {
+		if m >= 0 {
+			move[i] = 25 - m
+		}
+	}
+	return move
+}
+
+// CanMove reports whether the player can play at least one die of the roll.
+// A player on the bar who cannot enter with either die dances, which XG
+// records as an empty move. activePlayer is used as in LegalMoves.
+func (p Position) CanMove(dice [2]int32, activePlayer int32) bool {
+	board := p.Checkers
+	if activePlayer == Player2 {
+		board = swapPositionCheckers(board)
+	}
+
+	for _, die := range dice {
+		if die < 1 || die > 6 {
+			continue
+		}
+		for from := 25; from >= 1; from-- {
+			if _, ok := step(&board, from, int8(die)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AllCheckersHome reports whether the player has all its checkers in its home
+// board (points 1-6) and none on the bar, the precondition for bearing off.
+// activePlayer selects the checkers as in LegalMoves.
+func (p Position) AllCheckersHome(activePlayer int32) bool {
+	board := p.Checkers
+	if activePlayer == Player2 {
+		board = swapPositionCheckers(board)
+	}
+	return allHome(&board)
+}
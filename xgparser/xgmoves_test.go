@@ -0,0 +1,172 @@
+//
+//   xgmoves_test.go - Unit tests for legal move generation
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+// hasPlay reports whether plays contains a play reaching the same position as notation
+func hasPlay(pos Position, plays [][8]int8, notation string) bool {
+	want := ApplyMove(pos, ParseMoveNotation(notation), 1)
+	for _, play := range plays {
+		if ApplyMove(pos, play, 1) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLegalMoves_Opening(t *testing.T) {
+	pos := StartingPosition()
+	plays := pos.LegalMoves([2]int32{3, 1}, 1)
+
+	// 4 plays moving one checker 4 pips and 12 moving two checkers; 13/12 is blocked
+	if len(plays) != 16 {
+		t.Errorf("len(LegalMoves) = %d, want 16: %v", len(plays), plays)
+	}
+	for _, notation := range []string{"8/5 6/5", "24/23 13/10", "13/9", "24/21 24/23", "6/2"} {
+		if !hasPlay(pos, plays, notation) {
+			t.Errorf("LegalMoves missing %s", notation)
+		}
+	}
+	if hasPlay(pos, plays, "13/12 13/10") {
+		t.Errorf("LegalMoves contains blocked 13/12")
+	}
+
+	doubles := pos.LegalMoves([2]int32{6, 6}, 1)
+	if !hasPlay(pos, doubles, "24/18(2) 13/7(2)") {
+		t.Errorf("LegalMoves(66) missing 24/18(2) 13/7(2)")
+	}
+	for _, play := range doubles {
+		if play[7] == -1 {
+			t.Errorf("LegalMoves(66) play %s does not use four dice", FormatMove(play))
+		}
+	}
+}
+
+func TestLegalMoves_BarEntry(t *testing.T) {
+	var pos Position
+	pos.Checkers[25] = 1
+	pos.Checkers[6] = 14
+	pos.Checkers[22] = -2 // Blocks entering with the 3
+	pos.Checkers[12] = -13
+
+	plays := pos.LegalMoves([2]int32{5, 3}, 1)
+	if len(plays) != 2 || !hasPlay(pos, plays, "Bar/20 20/17") || !hasPlay(pos, plays, "Bar/20 6/3") {
+		t.Errorf("LegalMoves = %v, want Bar/20 20/17 and Bar/20 6/3", plays)
+	}
+
+	// The same position seen with the negative checkers moving
+	swapped := Position{Checkers: swapPositionCheckers(pos.Checkers)}
+	plays = swapped.LegalMoves([2]int32{5, 3}, -1)
+	if len(plays) != 2 {
+		t.Fatalf("len(LegalMoves(-1)) = %d, want 2", len(plays))
+	}
+	for _, play := range plays {
+		if play[0] != 0 || play[1] != 5 {
+			t.Errorf("LegalMoves(-1) play %v does not enter from the bar to 5", play)
+		}
+		if after := ApplyMove(swapped, play, -1); after.Checkers[0] != 0 {
+			t.Errorf("ApplyMove(%v) left checkers on the bar", play)
+		}
+	}
+}
+
+func TestLegalMoves_LargerDie(t *testing.T) {
+	// Either die can be played but not both: the 6 must be played
+	var pos Position
+	pos.Checkers[8] = 1
+	pos.Checkers[1] = -2
+	pos.Checkers[3] = -2
+
+	plays := pos.LegalMoves([2]int32{6, 1}, 1)
+	if len(plays) != 1 || !hasPlay(pos, plays, "8/2") {
+		t.Errorf("LegalMoves = %v, want only 8/2", plays)
+	}
+}
+
+func TestLegalMoves_BearOff(t *testing.T) {
+	var pos Position
+	pos.Checkers[4] = 1
+	pos.Checkers[2] = 1
+	pos.Checkers[20] = -15
+
+	plays := pos.LegalMoves([2]int32{6, 5}, 1)
+	if len(plays) != 1 || !hasPlay(pos, plays, "4/Off 2/Off") {
+		t.Errorf("LegalMoves = %v, want only 4/Off 2/Off", plays)
+	}
+}
+
+func TestDicePips(t *testing.T) {
+	if got := DicePips([2]int32{3, 3}); !reflect.DeepEqual(got, []int{3, 3, 3, 3}) {
+		t.Errorf("DicePips({3, 3}) = %v, want [3 3 3 3]", got)
+	}
+	if got := DicePips([2]int32{5, 2}); !reflect.DeepEqual(got, []int{5, 2}) {
+		t.Errorf("DicePips({5, 2}) = %v, want [5 2]", got)
+	}
+}
+
+func TestCanMove(t *testing.T) {
+	var pos Position
+	pos.Checkers[25] = 1
+	pos.Checkers[6] = 14
+	pos.Checkers[19] = -2 // Blocks entering with the 6
+	pos.Checkers[21] = -2 // Blocks entering with the 4
+	pos.Checkers[12] = -11
+
+	if pos.CanMove([2]int32{6, 4}, 1) {
+		t.Errorf("CanMove(64) = true, want false when dancing")
+	}
+	if len(pos.LegalMoves([2]int32{6, 4}, 1)) != 0 {
+		t.Errorf("LegalMoves(64) is not empty when dancing")
+	}
+	if !pos.CanMove([2]int32{6, 3}, 1) {
+		t.Errorf("CanMove(63) = false, want true when the 3 enters")
+	}
+
+	swapped := Position{Checkers: swapPositionCheckers(pos.Checkers)}
+	if swapped.CanMove([2]int32{4, 4}, -1) {
+		t.Errorf("CanMove(44, -1) = true, want false when dancing")
+	}
+	if !StartingPosition().CanMove([2]int32{6, 6}, 1) {
+		t.Errorf("CanMove(66) from the opening = false")
+	}
+}
+
+func TestAllCheckersHome(t *testing.T) {
+	var pos Position
+	pos.Checkers[6] = 5
+	pos.Checkers[4] = 6
+	pos.Checkers[1] = 4
+	pos.Checkers[20] = -15
+
+	if !pos.AllCheckersHome(1) {
+		t.Errorf("AllCheckersHome(1) = false with all checkers home")
+	}
+	if !pos.AllCheckersHome(-1) {
+		t.Errorf("AllCheckersHome(-1) = false with the opponent's checkers on its 5 point")
+	}
+
+	pos.Checkers[6]--
+	pos.Checkers[8] = 1 // Straggler
+	if pos.AllCheckersHome(1) {
+		t.Errorf("AllCheckersHome(1) = true with a checker on the 8 point")
+	}
+
+	pos.Checkers[8] = 0
+	pos.Checkers[25] = 1
+	if pos.AllCheckersHome(1) {
+		t.Errorf("AllCheckersHome(1) = true with a checker on the bar")
+	}
+
+	swapped := Position{Checkers: swapPositionCheckers(pos.Checkers)}
+	if !swapped.AllCheckersHome(1) || swapped.AllCheckersHome(-1) {
+		t.Errorf("AllCheckersHome of the swapped position = %v, %v, want true, false",
+			swapped.AllCheckersHome(1), swapped.AllCheckersHome(-1))
+	}
+}
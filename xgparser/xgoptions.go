@@ -0,0 +1,122 @@
+//
+//   xgoptions.go - Configurable XG file parsing
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/text/encoding"
+)
+
+// parseOptions holds the settings of Parse
+type parseOptions struct {
+	validate     bool
+	metadataOnly bool
+	topN         int
+	maxBytes     int64
+	nameEncoding encoding.Encoding
+}
+
+// Option configures Parse. ParseXG, ParseXGMulti and ParseXGCallback, which
+// parse segments already extracted from the file, also take options but
+// reject those that need the file stream: WithMetadataOnly and WithMaxBytes.
+// ParseXGCallback also rejects WithValidation, as it builds no Match.
+type Option func(*parseOptions)
+
+// WithValidation makes Parse check the parsed match with Match.Validate
+func WithValidation() Option {
+	return func(o *parseOptions) {
+		o.validate = true
+	}
+}
+
+// WithMetadataOnly makes Parse read only the match header, as PeekMatchHeader.
+// The returned match has its Metadata set and no games.
+func WithMetadataOnly() Option {
+	return func(o *parseOptions) {
+		o.metadataOnly = true
+	}
+}
+
+// WithTopN keeps only the n best analysed candidates of each checker move, as ParseXGTopN
+func WithTopN(n int) Option {
+	return func(o *parseOptions) {
+		o.topN = n
+	}
+}
+
+// WithMaxBytes limits the decompressed size of the archive, as ParseXGFromReaderLimited
+func WithMaxBytes(maxBytes int64) Option {
+	return func(o *parseOptions) {
+		o.maxBytes = maxBytes
+	}
+}
+
+// WithNameEncoding decodes the legacy player, event, location and round names
+// with enc, e.g. charmap.Windows1252. XG writes these shortstrings in the
+// Windows code page of the computer that saved the file; they are only used
+// when the Unicode names are empty. Without this option their bytes are read
+// as UTF-8.
+func WithNameEncoding(enc encoding.Encoding) Option {
+	return func(o *parseOptions) {
+		o.nameEncoding = enc
+	}
+}
+
+// Parse parses an XG file with the given options. Without options it is the
+// same as ParseXGFromReader.
+func Parse(r io.ReadSeeker, opts ...Option) (*Match, error) {
+	var o parseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.maxBytes < 0 {
+		return nil, fmt.Errorf("invalid size limit %d", o.maxBytes)
+	}
+
+	if o.metadataOnly {
+		metadata, err := peekMatchHeader(r, o.maxBytes, o.nameEncoding)
+		if err != nil {
+			return nil, err
+		}
+		return &Match{Metadata: *metadata}, nil
+	}
+
+	segments, err := readSegments(r, o.maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	return parseXG(segments, o)
+}
+
+// segmentOptions reads the options of the functions parsing extracted
+// segments, rejecting those that need the file stream
+func segmentOptions(opts []Option) (parseOptions, error) {
+	var o parseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.metadataOnly {
+		return o, fmt.Errorf("WithMetadataOnly needs the file stream, use Parse or PeekMatchHeader")
+	}
+	if o.maxBytes != 0 {
+		return o, fmt.Errorf("WithMaxBytes needs the file stream, use Parse")
+	}
+	return o, nil
+}
+
+// finish applies the options that work on a parsed match: WithTopN and
+// WithValidation
+func (o parseOptions) finish(match *Match) error {
+	if o.topN > 0 {
+		match.keepTopAnalysis(o.topN)
+	}
+	if o.validate {
+		return match.Validate()
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+//
+//   xgoptions_test.go - Unit tests for configurable parsing
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// testOptionsFile builds a two game match whose first move has six candidates.
+// The second game starts at secondScore, which is [2 0] for a consistent match.
+func testOptionsFile(secondScore [2]int32) []byte {
+	rec := testMoveRecord(1, [2]int32{3, 1}, [8]int32{8, 5, 6, 5, -1, -1, -1, -1})
+	for i := 0; i < 6; i++ {
+		putMoveCandidate(rec, i, [8]int8{int8(24 - i), 21, -1, -1, -1, -1, -1, -1}, [7]float32{0, 0, 0.5, 0, 0, 0, float32(i) / 10}, 2)
+	}
+	return buildTestXGFile(testGameFile(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		rec,
+		testGameFooter(Player1, 2),
+		testGameHeader(2, secondScore[0], secondScore[1]),
+		testGameFooter(1, 1),
+	), testArchiveFile{name: "temp.xgr", data: make([]byte, 1<<20)})
+}
+
+// errAny marks tests expecting an error without checking which one
+var errAny = errors.New("any error")
+
+func TestParse(t *testing.T) {
+	valid := testOptionsFile([2]int32{2, 0})
+	invalid := testOptionsFile([2]int32{3, 0})
+
+	tests := []struct {
+		name       string
+		data       []byte
+		opts       []Option
+		err        error // Expected error, errAny for any error
+		games      int
+		candidates int
+	}{
+		{"defaults", valid, nil, nil, 2, 6},
+		{"top n", valid, []Option{WithTopN(3)}, nil, 2, 3},
+		{"validation", valid, []Option{WithValidation()}, nil, 2, 6},
+		{"validation fails", invalid, []Option{WithValidation()}, errAny, 0, 0},
+		{"no validation", invalid, nil, nil, 2, 6},
+		{"metadata only", valid, []Option{WithMetadataOnly()}, nil, 0, 0},
+		{"metadata only with a limit", valid, []Option{WithMetadataOnly(), WithMaxBytes(1024)}, ErrTooLarge, 0, 0},
+		{"metadata only with room", valid, []Option{WithMetadataOnly(), WithMaxBytes(4 << 20)}, nil, 0, 0},
+		{"limit", valid, []Option{WithMaxBytes(1024)}, ErrTooLarge, 0, 0},
+		{"limit with room", valid, []Option{WithMaxBytes(4 << 20), WithTopN(1), WithValidation()}, nil, 2, 1},
+		{"negative limit", valid, []Option{WithMaxBytes(-1)}, errAny, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := Parse(bytes.NewReader(tt.data), tt.opts...)
+			if tt.err != nil {
+				if err == nil || (tt.err != errAny && !errors.Is(err, tt.err)) {
+					t.Errorf("Parse() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if match.Metadata.Player1Name != "Alice" || match.Metadata.MatchLength != 5 {
+				t.Errorf("Metadata = %+v", match.Metadata)
+			}
+			if len(match.Games) != tt.games {
+				t.Fatalf("len(Games) = %d, want %d", len(match.Games), tt.games)
+			}
+			if tt.games > 0 {
+				if got := len(match.Games[0].Moves[0].CheckerMove.Analysis); got != tt.candidates {
+					t.Errorf("candidates = %d, want %d", got, tt.candidates)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchValidate(t *testing.T) {
+	match := &Match{
+		Metadata: MatchMetadata{MatchLength: 3},
+		Games: []Game{
+			{GameNumber: 1, Winner: Player1, PointsWon: 2},
+			{GameNumber: 2, InitialScore: [2]int32{2, 0}, Winner: Player2, PointsWon: 1},
+		},
+	}
+	if err := match.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	match.Games[1].InitialScore = [2]int32{0, 2}
+	if err := match.Validate(); err == nil {
+		t.Errorf("Validate() = nil for a game not starting at the previous score")
+	}
+
+	match.Games[0].PointsWon = 4
+	match.Games[1].InitialScore = [2]int32{4, 0}
+	if err := match.Validate(); err == nil {
+		t.Errorf("Validate() = nil for a game played after the match was won")
+	}
+
+	match.Games[0].PointsWon = 2
+	match.Games[1].InitialScore = [2]int32{2, 0}
+	match.Games[1].Winner = 2
+	if err := match.Validate(); err == nil {
+		t.Errorf("Validate() = nil for an invalid winner")
+	}
+}
+
+func TestWithNameEncoding(t *testing.T) {
+	// "René" and "Jürgen" in the Windows-1252 code page
+	data := buildTestXGFile(testGameFile(
+		testMatchHeader("Ren\xe9", "J\xfcrgen", 5),
+		testGameHeader(1, 0, 0),
+		testGameFooter(-1, 2),
+	))
+
+	match, err := Parse(bytes.NewReader(data), WithNameEncoding(charmap.Windows1252))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if match.Metadata.Player1Name != "René" || match.Metadata.Player2Name != "Jürgen" {
+		t.Errorf("names = %q, %q, want \"René\", \"Jürgen\"", match.Metadata.Player1Name, match.Metadata.Player2Name)
+	}
+
+	match, err = Parse(bytes.NewReader(data), WithMetadataOnly(), WithNameEncoding(charmap.Windows1252))
+	if err != nil {
+		t.Fatalf("Parse(WithMetadataOnly) error = %v", err)
+	}
+	if match.Metadata.Player1Name != "René" {
+		t.Errorf("metadata only Player1Name = %q, want \"René\"", match.Metadata.Player1Name)
+	}
+
+	// Without the option the invalid UTF-8 byte is not decoded as é
+	match, err = Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if match.Metadata.Player1Name == "René" {
+		t.Errorf("Player1Name decoded without WithNameEncoding")
+	}
+}
+
+func TestSegmentOptions(t *testing.T) {
+	move := testMoveRecord(1, [2]int32{3, 1}, [8]int32{8, 5, 6, 5, -1, -1, -1, -1})
+	for i := 0; i < 3; i++ {
+		putMoveCandidate(move, i, [8]int8{int8(24 - i), 21, -1, -1, -1, -1, -1, -1}, [7]float32{0, 0, 0.5, 0, 0, 0, float32(i) / 10}, 2)
+	}
+	segments := testGameFileSegments(
+		testMatchHeader("Ren\xe9", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		move,
+		testGameFooter(Player1, 2),
+		testMatchHeader("J\xfcrgen", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		testGameFooter(-1, 1),
+	)
+	opts := []Option{WithNameEncoding(charmap.Windows1252), WithTopN(1), WithValidation()}
+	// ParseXG merges both matches into one, which would not validate
+	match, err := ParseXG(segments, WithTopN(1))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	if got := len(match.Games[0].Moves[0].CheckerMove.Analysis); got != 1 {
+		t.Errorf("ParseXG(WithTopN(1)) kept %d candidates", got)
+	}
+
+	matches, err := ParseXGMulti(segments, opts...)
+	if err != nil {
+		t.Fatalf("ParseXGMulti() error = %v", err)
+	}
+	if len(matches) != 2 || matches[0].Metadata.Player1Name != "René" || matches[1].Metadata.Player1Name != "Jürgen" {
+		t.Errorf("ParseXGMulti(WithNameEncoding) = %d matches", len(matches))
+	}
+	if got := len(matches[0].Games[0].Moves[0].CheckerMove.Analysis); got != 1 {
+		t.Errorf("ParseXGMulti(WithTopN(1)) kept %d candidates", got)
+	}
+
+	// Options needing the file stream, or a Match for the callback, are rejected
+	for _, opt := range []Option{WithMetadataOnly(), WithMaxBytes(1 << 20)} {
+		if _, err := ParseXG(segments, opt); err == nil {
+			t.Errorf("ParseXG() accepted a file stream option")
+		}
+		if _, err := ParseXGMulti(segments, opt); err == nil {
+			t.Errorf("ParseXGMulti() accepted a file stream option")
+		}
+		if err := ParseXGCallback(segments, &recordingHandler{}, opt); err == nil {
+			t.Errorf("ParseXGCallback() accepted a file stream option")
+		}
+	}
+	h := &recordingHandler{}
+	if err := ParseXGCallback(segments, h, WithValidation()); err == nil || len(h.calls) != 0 {
+		t.Errorf("ParseXGCallback(WithValidation) error = %v after %d calls", err, len(h.calls))
+	}
+}
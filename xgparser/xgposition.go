@@ -0,0 +1,153 @@
+//
+//   xgposition.go - Position helpers
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+// sideCheckers splits a position into the checker counts of each side, indexed
+// by distance from bearing off: 1-24 are points from that side's point of view
+// and 25 is its bar. The player on roll owns the positive checkers.
+func (p Position) sideCheckers() (onRoll, opponent [26]int) {
+	for i, c := range p.Checkers {
+		if c > 0 {
+			onRoll[i] += int(c)
+		} else if c < 0 {
+			opponent[25-i] -= int(c)
+		}
+	}
+	return onRoll, opponent
+}
+
+// pips returns the pip count of one side
+func pips(checkers [26]int) int {
+	total := 0
+	for i, n := range checkers {
+		total += i * n
+	}
+	return total
+}
+
+// PipCount returns the pip counts of player 1 and player 2.
+// The position is seen from the player on roll, activePlayer tells which
+// player that is (1 = player 1, -1 = player 2).
+func (p Position) PipCount(activePlayer int32) (int, int) {
+	onRoll, opponent := p.sideCheckers()
+	if activePlayer == Player2 {
+		return pips(opponent), pips(onRoll)
+	}
+	return pips(onRoll), pips(opponent)
+}
+
+// RaceLead returns which player leads the race, 1 for player 1, -1 for player 2
+// and 0 when the pip counts are equal, and by how many pips. The leader is the
+// player with the lower PipCount; activePlayer is used as in PipCount.
+func (p Position) RaceLead(activePlayer int32) (leader int, pipDiff int) {
+	p1, p2 := p.PipCount(activePlayer)
+	switch {
+	case p1 < p2:
+		return 1, p2 - p1
+	case p2 < p1:
+		return -1, p1 - p2
+	}
+	return 0, 0
+}
+
+// CheckersOff returns the number of checkers player 1 and player 2 have borne
+// off: 15 minus the checkers left on the board, bar included.
+// activePlayer tells which player is on roll as in PipCount.
+func (p Position) CheckersOff(activePlayer int32) (int, int) {
+	onRoll, opponent := checkerCount(p.Checkers)
+	offOnRoll, offOpponent := 15-onRoll, 15-opponent
+	if activePlayer == Player2 {
+		return offOpponent, offOnRoll
+	}
+	return offOnRoll, offOpponent
+}
+
+// keithAdjustments estimates the pips wasted while bearing off with the
+// adjustments of the Keith count: 2 per checker beyond the first on the 1
+// point, 1 per checker beyond the first on the 2 point, 1 per checker beyond
+// the third on the 3 point and 1 per empty 4, 5 or 6 point. Unlike the Keith
+// count it is 0 when checkers remain outside the home board, and it leaves out
+// the count's final step, where the player on roll adds a seventh of their
+// count: that step turns the count into a doubling threshold, not a number of
+// pips. It is 0 when all checkers are off.
+func keithAdjustments(checkers [26]int) int {
+	for i := 7; i <= 25; i++ {
+		if checkers[i] > 0 {
+			return 0
+		}
+	}
+	if pips(checkers) == 0 {
+		return 0
+	}
+
+	wastage := 2*max(checkers[1]-1, 0) + max(checkers[2]-1, 0) + max(checkers[3]-3, 0)
+	for i := 4; i <= 6; i++ {
+		if checkers[i] == 0 {
+			wastage++
+		}
+	}
+	return wastage
+}
+
+// EffectivePipCount returns the effective pip counts of player 1 and player 2:
+// the raw pip count plus the estimated wastage of a bear-off position, see
+// keithAdjustments. It is not the Keith count: the player on roll gets no 8/7
+// factor. Positions with checkers outside the home board get no wastage.
+func (p Position) EffectivePipCount(activePlayer int32) (float64, float64) {
+	onRoll, opponent := p.sideCheckers()
+	epcOnRoll := float64(pips(onRoll) + keithAdjustments(onRoll))
+	epcOpponent := float64(pips(opponent) + keithAdjustments(opponent))
+	if activePlayer == Player2 {
+		return epcOpponent, epcOnRoll
+	}
+	return epcOnRoll, epcOpponent
+}
+
+// StartingPosition returns the standard backgammon starting position, seen
+// from the player on roll as positions are stored: two checkers on the 24
+// point, five on the 13, three on the 8 and five on the 6 for each side, the
+// cube centered at 1 and the score 0-0.
+func StartingPosition() Position {
+	var pos Position
+	pos.Checkers[24], pos.Checkers[13], pos.Checkers[8], pos.Checkers[6] = 2, 5, 3, 5
+	pos.Checkers[1], pos.Checkers[12], pos.Checkers[17], pos.Checkers[19] = -2, -5, -3, -5
+	pos.Cube = 1
+	return pos
+}
+
+// NackgammonStart returns the starting position of Nackgammon, as
+// StartingPosition: two checkers on the 24 and 23 points, four on the 13,
+// three on the 8 and four on the 6 for each side.
+func NackgammonStart() Position {
+	var pos Position
+	pos.Checkers[24], pos.Checkers[23], pos.Checkers[13], pos.Checkers[8], pos.Checkers[6] = 2, 2, 4, 3, 4
+	pos.Checkers[1], pos.Checkers[2], pos.Checkers[12], pos.Checkers[17], pos.Checkers[19] = -2, -2, -4, -3, -4
+	pos.Cube = 1
+	return pos
+}
+
+// Absolute returns the position in X's (player 1's) absolute orientation, the
+// board as an XGID describes it: X checkers positive, points numbered from X's
+// point of view, X's bar at index 25 and O's bar at index 0.
+// Positions are stored from the point of view of the player on roll, so only
+// positions with activePlayer == Player2 are flipped back.
+func (p Position) Absolute(activePlayer int32) Position {
+	if activePlayer == Player2 {
+		return swapPosition(p)
+	}
+	return p
+}
+
+// Mirror returns the position flipped left-right for display with the home
+// board on the other side: point i becomes point 25-i for the 24 points, while
+// checker colors, bars and the other fields are unchanged.
+func (p Position) Mirror() Position {
+	mirrored := p
+	for i := 1; i <= 24; i++ {
+		mirrored.Checkers[i] = p.Checkers[25-i]
+	}
+	return mirrored
+}
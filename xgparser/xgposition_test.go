@@ -0,0 +1,194 @@
+//
+//   xgposition_test.go - Unit tests for position helpers
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPipCount_Fixtures(t *testing.T) {
+	files, _ := filepath.Glob("../test/2025-11-04/*_EN.txt")
+	pipRegex := regexp.MustCompile(`Pip count\s+X:\s*(\d+)\s+O:\s*(\d+)`)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		matches := pipRegex.FindStringSubmatch(string(data))
+		if matches == nil {
+			continue
+		}
+		wantX, _ := strconv.Atoi(matches[1])
+		wantO, _ := strconv.Atoi(matches[2])
+
+		// ParseASCIIBoard returns the position from X's point of view
+		pos, err := ParseASCIIBoard(strings.Split(string(data), "\n"))
+		if err != nil {
+			t.Fatalf("%s: ParseASCIIBoard() error = %v", file, err)
+		}
+		if x, o := pos.PipCount(1); x != wantX || o != wantO {
+			t.Errorf("%s: PipCount(1) = %d, %d, want %d, %d", filepath.Base(file), x, o, wantX, wantO)
+		}
+
+		// Seen from O, the same position gives the same counts per player
+		if x, o := swapPosition(pos).PipCount(-1); x != wantX || o != wantO {
+			t.Errorf("%s: swapped PipCount(-1) = %d, %d, want %d, %d", filepath.Base(file), x, o, wantX, wantO)
+		}
+	}
+}
+
+func TestEffectivePipCount(t *testing.T) {
+	// Player on roll: 10 checkers on the 1 point and 5 on the 2 point
+	// Opponent: 15 checkers spread over its 6 to 4 points
+	var pos Position
+	pos.Checkers[1] = 10
+	pos.Checkers[2] = 5
+	pos.Checkers[19] = -5
+	pos.Checkers[20] = -5
+	pos.Checkers[21] = -5
+
+	pips1, pips2 := pos.PipCount(1)
+	epc1, epc2 := pos.EffectivePipCount(1)
+	if pips1 != 20 || pips2 != 75 {
+		t.Fatalf("PipCount() = %d, %d, want 20, 75", pips1, pips2)
+	}
+	// 2*9 on the 1 point, 4 on the 2 point, 3 empty points, and no 8/7
+	// factor for the player on roll as in the Keith count
+	if epc1 != 45 {
+		t.Errorf("stacked EffectivePipCount() = %v, want 45", epc1)
+	}
+	if epc1 <= float64(pips1) {
+		t.Errorf("stacked EffectivePipCount() = %v, want more than %d pips", epc1, pips1)
+	}
+	if epc2 != float64(pips2) {
+		t.Errorf("smooth EffectivePipCount() = %v, want %d", epc2, pips2)
+	}
+
+	// Contact position: no bear-off wastage
+	pos.Checkers[13] = 1
+	pos.Checkers[1] = 9
+	if epc, _ := pos.EffectivePipCount(1); epc != 9+10+13 {
+		t.Errorf("contact EffectivePipCount() = %v, want %d", epc, 9+10+13)
+	}
+}
+
+func TestCheckersOff(t *testing.T) {
+	// Player on roll: 6 checkers left in the home board, 9 off
+	// Opponent: 12 checkers left including one on the bar, 3 off
+	var pos Position
+	pos.Checkers[1] = 2
+	pos.Checkers[3] = 4
+	pos.Checkers[0] = -1
+	pos.Checkers[20] = -6
+	pos.Checkers[24] = -5
+
+	if p1, p2 := pos.CheckersOff(1); p1 != 9 || p2 != 3 {
+		t.Errorf("CheckersOff(1) = %d, %d, want 9, 3", p1, p2)
+	}
+	if p1, p2 := pos.CheckersOff(-1); p1 != 3 || p2 != 9 {
+		t.Errorf("CheckersOff(-1) = %d, %d, want 3, 9", p1, p2)
+	}
+	if p1, p2 := StartingPosition().CheckersOff(1); p1 != 0 || p2 != 0 {
+		t.Errorf("CheckersOff(1) of the opening = %d, %d, want 0, 0", p1, p2)
+	}
+}
+
+func TestRaceLead(t *testing.T) {
+	// Player on roll: 5 checkers on the 6 point and 10 on the 5 point, 80 pips
+	// Opponent: 15 checkers on its 6 point, 90 pips
+	var pos Position
+	pos.Checkers[6] = 5
+	pos.Checkers[5] = 10
+	pos.Checkers[19] = -15
+
+	if leader, diff := pos.RaceLead(1); leader != 1 || diff != 10 {
+		t.Errorf("RaceLead(1) = %d, %d, want 1, 10", leader, diff)
+	}
+	if leader, diff := pos.RaceLead(-1); leader != -1 || diff != 10 {
+		t.Errorf("RaceLead(-1) = %d, %d, want -1, 10", leader, diff)
+	}
+	if leader, diff := StartingPosition().RaceLead(1); leader != 0 || diff != 0 {
+		t.Errorf("RaceLead(1) of the opening = %d, %d, want 0, 0", leader, diff)
+	}
+}
+
+func TestAbsolute(t *testing.T) {
+	xgid := "---c--CCB---dB-B---c-BcAb-"
+	board := xgidBoardPosition(xgid)
+
+	noMove := [8]int32{-1, -1, -1, -1, -1, -1, -1, -1}
+	oToMove := testMoveRecord(-1, [2]int32{3, 1}, noMove)
+	putPosition(oToMove, offMEPositionI, board)
+	xToMove := testMoveRecord(1, [2]int32{3, 1}, noMove)
+	putPosition(xToMove, offMEPositionI, board)
+
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 7),
+		testGameHeader(1, 0, 0),
+		oToMove,
+		xToMove,
+		testGameFooter(1, 1),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+
+	for _, m := range match.Games[0].Moves {
+		move := m.CheckerMove
+		if move.ActivePlayer == -1 && move.Position.Checkers == board {
+			t.Errorf("O to move position was not stored from O's point of view")
+		}
+		if got := move.Position.Absolute(move.ActivePlayer); got.Checkers != board {
+			t.Errorf("ActivePlayer %d: Absolute() = %v, want %v", move.ActivePlayer, got.Checkers, board)
+		}
+	}
+}
+
+func TestMirror(t *testing.T) {
+	pos := StartingPosition()
+	pos.Checkers[25] = 1 // Bar checkers stay on their bar
+	pos.Checkers[6]--
+	pos.Cube = 2
+
+	mirrored := pos.Mirror()
+	if mirrored.Checkers[19] != 4 || mirrored.Checkers[1] != 2 || mirrored.Checkers[24] != -2 {
+		t.Errorf("Mirror() points 19, 1, 24 = %d, %d, %d, want 4, 2, -2",
+			mirrored.Checkers[19], mirrored.Checkers[1], mirrored.Checkers[24])
+	}
+	if mirrored.Checkers[25] != 1 || mirrored.Checkers[0] != 0 || mirrored.Cube != 2 {
+		t.Errorf("Mirror() changed the bars or the cube: %+v", mirrored)
+	}
+	if got := mirrored.Mirror(); got != pos {
+		t.Errorf("Mirror().Mirror() = %+v, want %+v", got, pos)
+	}
+}
+
+func TestStartingPosition(t *testing.T) {
+	pos := StartingPosition()
+	if p1, p2 := pos.PipCount(1); p1 != 167 || p2 != 167 {
+		t.Errorf("PipCount() = %d, %d, want 167, 167", p1, p2)
+	}
+	if n1, n2 := checkerCount(pos.Checkers); n1 != 15 || n2 != 15 {
+		t.Errorf("checkers = %d, %d, want 15, 15", n1, n2)
+	}
+	if pos.Mirror().Mirror() != pos || pos.Absolute(-1) != pos {
+		t.Errorf("StartingPosition() is not symmetric: %+v", pos)
+	}
+
+	pos = NackgammonStart()
+	if p1, p2 := pos.PipCount(1); p1 != 194 || p2 != 194 {
+		t.Errorf("NackgammonStart() PipCount() = %d, %d, want 194, 194", p1, p2)
+	}
+	if n1, n2 := checkerCount(pos.Checkers); n1 != 15 || n2 != 15 {
+		t.Errorf("NackgammonStart() checkers = %d, %d, want 15, 15", n1, n2)
+	}
+}
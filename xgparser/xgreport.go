@@ -0,0 +1,244 @@
+//
+//   xgreport.go - XG match analysis text reports
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// `; [Player 1 "Alice"]`
+	reportTagRegex = regexp.MustCompile(`^;\s*\[(.+?)\s+"(.*)"\]\s*$`)
+	// "3 point match"
+	reportLengthRegex = regexp.MustCompile(`^\s*(\d+)\s+point\s+match\s*$`)
+	// "Game 3"
+	reportGameRegex = regexp.MustCompile(`^\s*Game\s+(\d+)\s*$`)
+	// "Alice : 0                            Bob : 1"
+	reportScoreRegex = regexp.MustCompile(`^\s*(\S.*?)\s*:\s*(\d+)\s+(\S.*?)\s*:\s*(\d+)\s*$`)
+	// "  2) 64: 24/18 13/9                   Doubles => 2"
+	reportMoveLineRegex = regexp.MustCompile(`^\s*\d+\)`)
+	// "                                      Wins 2 points and the match"
+	reportWinsLineRegex = regexp.MustCompile(`^\s*Wins\s`)
+	// One action of a move line: a roll and its move, a cube action or a result
+	reportActionRegex = regexp.MustCompile(`([1-6])([1-6]):((?:\s+[^\s:]+/[^\s:]+)*)|(Doubles|Beavers)\s*=>\s*\d+|(Takes|Drops|Passes)\b|Wins\s+(\d+)\s+points?(\s+and\s+the\s+match)?`)
+	// "Error rate (mEMG)   -18.9   -2.6"
+	reportSummaryRegex = regexp.MustCompile(`^\s*(Checker error rate|Cube error rate|Error rate|PR)\b.*?\s([-+]?\d+(?:[.,]\d+)?)\s+([-+]?\d+(?:[.,]\d+)?)\s*$`)
+)
+
+// reportRightColumn is the first column of player 2 in the move lines
+const reportRightColumn = 20
+
+// ErrorSummary holds the error rates of a player from the summary of a match
+// report, in millipoints of equity lost per decision (mEMG), and the
+// performance rating
+type ErrorSummary struct {
+	CheckerErrorRate float32 `json:"checker_error_rate"`
+	CubeErrorRate    float32 `json:"cube_error_rate"`
+	ErrorRate        float32 `json:"error_rate"`
+	PR               float32 `json:"pr"`
+}
+
+// ParseMatchAnalysisText parses a match exported by XG as text into a Match.
+// The report has the layout of XG's match export:
+//
+//	; [Player 1 "Alice"]               tags: players, event date and time
+//	3 point match
+//	 Game 1
+//	 Alice : 0          Bob : 0        score at the start of the game
+//	  1) 31: 8/5 6/5    52: 13/8 13/11 numbered moves, player 1 then player 2
+//	  2) 64: 24/18 13/9 Doubles => 2   cube actions in the column of the player
+//	  3)  Drops         Wins 1 point   result
+//
+// followed by an optional error summary with one column per player:
+//
+//	Error rate (mEMG)   -18.9   -2.6
+//
+// Moves are read with ParseMoveNotation and replayed from StartingPosition to
+// fill the positions; the moves have no analysis. A double and its response
+// are one CubeMove, whose CubeAction is the response. Actions before the first
+// "Game" line belong to game 1.
+func ParseMatchAnalysisText(r io.Reader) (*Match, error) {
+	match := &Match{}
+	var game *Game
+	var board Position    // Absolute position, player 1 positive
+	var pending *CubeMove // Double waiting for its response
+	var date, clock string
+	summary := make([]ErrorSummary, 2)
+	hasSummary := false
+
+	startGame := func(number int32) {
+		next := &Game{GameNumber: number, Moves: make([]Move, 0), FinalCube: 1}
+		if game != nil {
+			next.InitialScore = game.ScoreAfter()
+			match.Games = append(match.Games, *game)
+		}
+		game = next
+		board = StartingPosition()
+		board.Score = game.InitialScore
+		game.InitialPosition = board.Checkers
+		pending = nil
+	}
+
+	// act applies one action of a move line played by side
+	act := func(side int32, m []string) {
+		if game == nil {
+			startGame(1)
+		}
+		position := board.Absolute(side)
+
+		switch {
+		case m[1] != "":
+			d1, _ := strconv.Atoi(m[1])
+			d2, _ := strconv.Atoi(m[2])
+			move := ParseMoveNotation(strings.TrimSpace(m[3]))
+			checkerMove := &CheckerMove{
+				Position:     position,
+				ActivePlayer: side,
+				Dice:         [2]int32{int32(d1), int32(d2)},
+				Analysis:     make([]CheckerAnalysis, 0),
+			}
+			for i, v := range move {
+				checkerMove.PlayedMove[i] = int32(v)
+			}
+			game.Moves = append(game.Moves, Move{MoveType: "checker", CheckerMove: checkerMove})
+			board = ApplyMove(position, move, Player1).Absolute(side)
+
+		case m[4] == "Doubles":
+			pending = &CubeMove{Position: position, ActivePlayer: side, CubeAction: CubeDouble}
+			game.Moves = append(game.Moves, Move{MoveType: "cube", CubeMove: pending})
+
+		case m[4] == "Beavers" && pending != nil:
+			// A beaver turns the cube twice, back to the doubler
+			pending.CubeAction = CubeBeaver
+			game.FinalCube *= 4
+			game.CubeTurns += 2
+			board.Cube, board.CubePos = game.FinalCube, -side
+			pending = nil
+
+		case m[5] == "Takes" && pending != nil:
+			pending.CubeAction = CubeTake
+			game.FinalCube *= 2
+			game.CubeTurns++
+			board.Cube, board.CubePos = game.FinalCube, side
+			pending = nil
+
+		case m[5] != "" && pending != nil:
+			pending.CubeAction = CubePass
+			pending = nil
+
+		case m[6] != "":
+			points, _ := strconv.ParseInt(m[6], 10, 32)
+			game.Winner = side
+			game.PointsWon = int32(points)
+			if m[7] != "" {
+				match.Winner = side
+			}
+		}
+	}
+
+	// actions applies the actions of a move line, each in its player's column
+	actions := func(line string) {
+		for _, loc := range reportActionRegex.FindAllStringSubmatchIndex(line, -1) {
+			m := make([]string, len(loc)/2)
+			for i := range m {
+				if loc[2*i] >= 0 {
+					m[i] = line[loc[2*i]:loc[2*i+1]]
+				}
+			}
+			side := Player1
+			if loc[0] >= reportRightColumn {
+				side = Player2
+			}
+			act(side, m)
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if matches := reportTagRegex.FindStringSubmatch(line); matches != nil {
+			switch matches[1] {
+			case "Player 1":
+				match.Metadata.Player1Name = matches[2]
+			case "Player 2":
+				match.Metadata.Player2Name = matches[2]
+			case "Event":
+				match.Metadata.Event = matches[2]
+			case "Round":
+				match.Metadata.Round = matches[2]
+			case "EventDate":
+				date = strings.ReplaceAll(matches[2], ".", "-")
+			case "EventTime":
+				clock = strings.ReplaceAll(matches[2], ".", ":") + ":00"
+			case "Crawford":
+				match.Metadata.CrawfordRule = matches[2] == "On"
+			}
+			continue
+		}
+
+		switch {
+		case reportLengthRegex.MatchString(line):
+			length, _ := strconv.ParseInt(reportLengthRegex.FindStringSubmatch(line)[1], 10, 32)
+			match.Metadata.MatchLength = int32(length)
+
+		case reportGameRegex.MatchString(line):
+			number, _ := strconv.ParseInt(reportGameRegex.FindStringSubmatch(line)[1], 10, 32)
+			startGame(int32(number))
+
+		case reportMoveLineRegex.MatchString(line), reportWinsLineRegex.MatchString(line):
+			actions(line)
+
+		case reportSummaryRegex.MatchString(line):
+			matches := reportSummaryRegex.FindStringSubmatch(line)
+			for i := range summary {
+				v := float32(parseDecimal(matches[2+i]))
+				switch matches[1] {
+				case "Checker error rate":
+					summary[i].CheckerErrorRate = v
+				case "Cube error rate":
+					summary[i].CubeErrorRate = v
+				case "Error rate":
+					summary[i].ErrorRate = v
+				case "PR":
+					summary[i].PR = v
+				}
+			}
+			hasSummary = true
+
+		case reportScoreRegex.MatchString(line) && game != nil && len(game.Moves) == 0:
+			matches := reportScoreRegex.FindStringSubmatch(line)
+			if match.Metadata.Player1Name == "" {
+				match.Metadata.Player1Name, match.Metadata.Player2Name = matches[1], matches[3]
+			}
+			score1, _ := strconv.ParseInt(matches[2], 10, 32)
+			score2, _ := strconv.ParseInt(matches[4], 10, 32)
+			game.InitialScore = [2]int32{int32(score1), int32(score2)}
+			board.Score = game.InitialScore
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if game == nil {
+		return nil, fmt.Errorf("no game found in match report")
+	}
+	match.Games = append(match.Games, *game)
+	if date != "" {
+		match.Metadata.DateTime = strings.TrimSpace(date + " " + clock)
+	}
+	if hasSummary {
+		match.ErrorSummaries = summary
+	}
+
+	return match, nil
+}
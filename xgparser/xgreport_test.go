@@ -0,0 +1,109 @@
+//
+//   xgreport_test.go - Unit tests for match analysis text reports
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseMatchAnalysisText(t *testing.T) {
+	file, err := os.Open("../test/matches/3point_EN.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	match, err := ParseMatchAnalysisText(file)
+	if err != nil {
+		t.Fatalf("ParseMatchAnalysisText() error = %v", err)
+	}
+
+	meta := match.Metadata
+	if meta.Player1Name != "Alice" || meta.Player2Name != "Bob" || meta.MatchLength != 3 || !meta.CrawfordRule {
+		t.Errorf("Metadata = %+v, want Alice vs Bob, 3 points with Crawford", meta)
+	}
+	if meta.DateTime != "2025-11-04 20:15:00" {
+		t.Errorf("DateTime = %q, want %q", meta.DateTime, "2025-11-04 20:15:00")
+	}
+	if winner, score := match.Result(); winner != "Bob" || score != [2]int32{0, 3} {
+		t.Errorf("Result() = %q, %v, want Bob, [0 3]", winner, score)
+	}
+	if len(match.Games) != 2 {
+		t.Fatalf("len(Games) = %d, want 2", len(match.Games))
+	}
+
+	// Game 1: Bob doubles and Alice drops
+	game := match.Games[0]
+	if game.InitialScore != [2]int32{0, 0} || len(game.Moves) != 4 || game.Winner != Player2 || game.PointsWon != 1 {
+		t.Fatalf("game 1 = %+v, want 4 moves won by Bob for 1 point", game)
+	}
+	cube := game.Moves[3].CubeMove
+	if cube == nil || cube.ActivePlayer != Player2 || cube.CubeAction != CubePass {
+		t.Errorf("game 1 cube move = %+v, want a pass of Bob's double", cube)
+	}
+
+	// Game 2: Bob opens, doubles and Alice takes
+	game = match.Games[1]
+	if game.InitialScore != [2]int32{0, 1} || len(game.Moves) != 7 || game.Winner != Player2 || game.PointsWon != 2 {
+		t.Fatalf("game 2 = %+v, want 7 moves won by Bob for 2 points", game)
+	}
+	if first := game.Moves[0].CheckerMove; first == nil || first.ActivePlayer != Player2 || first.Dice != [2]int32{4, 2} {
+		t.Errorf("game 2 first move = %+v, want Bob's 42", first)
+	}
+	cube = game.Moves[2].CubeMove
+	if cube == nil || cube.CubeAction != CubeTake || game.FinalCube != 2 || game.CubeTurns != 1 {
+		t.Errorf("game 2 cube move = %+v, FinalCube %d, CubeTurns %d, want a take at 2", cube, game.FinalCube, game.CubeTurns)
+	}
+	hit := game.Moves[4].CheckerMove
+	if hit.PlayedMove != [8]int32{13, 8, 13, 8, 6, 1, 6, 1} {
+		t.Errorf("game 2 55 PlayedMove = %v, want 13/8(2) 6/1*(2)", hit.PlayedMove)
+	}
+	enter := game.Moves[5].CheckerMove
+	if enter.Position.Checkers[25] != 1 || enter.Position.Cube != 2 || enter.Position.CubePos != -1 {
+		t.Errorf("game 2 Bob's position after the hit = %+v, want 1 checker on the bar and the cube at 2 owned by Alice", enter.Position)
+	}
+
+	// The positions come from replaying the moves: each played move must be legal
+	for _, game := range match.Games {
+		for i, move := range game.Moves {
+			m := move.CheckerMove
+			if m == nil {
+				continue
+			}
+			var played [8]int8
+			for j, v := range m.PlayedMove {
+				played[j] = int8(v)
+			}
+			want := ApplyMove(m.Position, played, Player1).Checkers
+			legal := false
+			for _, play := range m.Position.LegalMoves(m.Dice, Player1) {
+				legal = legal || ApplyMove(m.Position, play, Player1).Checkers == want
+			}
+			if !legal {
+				t.Errorf("game %d move %d: %v is not legal with %v", game.GameNumber, i, m.PlayedMove, m.Dice)
+			}
+		}
+	}
+
+	if len(match.ErrorSummaries) != 2 {
+		t.Fatalf("ErrorSummaries = %+v, want one per player", match.ErrorSummaries)
+	}
+	alice, bob := match.ErrorSummaries[0], match.ErrorSummaries[1]
+	if alice != (ErrorSummary{CheckerErrorRate: -12.4, CubeErrorRate: -45, ErrorRate: -18.9, PR: 12.7}) {
+		t.Errorf("Alice's error summary = %+v", alice)
+	}
+	if bob != (ErrorSummary{CheckerErrorRate: -3.1, ErrorRate: -2.6, PR: 2}) {
+		t.Errorf("Bob's error summary = %+v", bob)
+	}
+}
+
+func TestParseMatchAnalysisText_Empty(t *testing.T) {
+	if _, err := ParseMatchAnalysisText(strings.NewReader("3 point match\n")); err == nil {
+		t.Errorf("ParseMatchAnalysisText() without games returned no error")
+	}
+}
@@ -0,0 +1,113 @@
+//
+//   xgschema.go - JSON schema of the lightweight match structure
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// schemaGenerator builds JSON schemas from Go types.
+// Each struct type gets a single entry in definitions and is referenced by name.
+type schemaGenerator struct {
+	definitions map[string]interface{}
+}
+
+// typeSchema returns the schema of a type as marshaled by encoding/json
+func (g *schemaGenerator) typeSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Array:
+		return map[string]interface{}{
+			"type":     "array",
+			"items":    g.typeSchema(t.Elem()),
+			"minItems": t.Len(),
+			"maxItems": t.Len(),
+		}
+	case reflect.Slice:
+		// nil slices are marshaled as null
+		return map[string]interface{}{
+			"type":  []string{"array", "null"},
+			"items": g.typeSchema(t.Elem()),
+		}
+	case reflect.Ptr:
+		return map[string]interface{}{
+			"anyOf": []interface{}{g.typeSchema(t.Elem()), map[string]interface{}{"type": "null"}},
+		}
+	case reflect.Struct:
+		if _, ok := g.definitions[t.Name()]; !ok {
+			g.definitions[t.Name()] = nil // Placeholder for recursive types
+			g.definitions[t.Name()] = g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema returns the object schema of a struct from its json tags.
+// Fields tagged omitempty are optional, all others are required.
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldType := field.Type
+		omitEmpty := strings.Contains(options, "omitempty")
+		if omitEmpty && fieldType.Kind() == reflect.Ptr {
+			// A nil pointer is left out rather than written as null
+			fieldType = fieldType.Elem()
+		}
+
+		properties[name] = g.typeSchema(fieldType)
+		if !omitEmpty {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// MatchJSONSchema returns a JSON Schema (draft-07) describing the JSON form of
+// Match, as produced by Match.ToJSON. The schema is generated from the struct
+// definitions and their json tags, so it always follows the Go types.
+func MatchJSONSchema() []byte {
+	g := &schemaGenerator{definitions: make(map[string]interface{})}
+
+	schema := g.structSchema(reflect.TypeOf(Match{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "Match"
+	schema["definitions"] = g.definitions
+
+	data, _ := json.MarshalIndent(schema, "", "  ")
+	return data
+}
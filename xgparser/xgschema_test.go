@@ -0,0 +1,150 @@
+//
+//   xgschema_test.go - Unit tests for the match JSON schema
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// validateSchema checks value against the subset of JSON Schema used by MatchJSONSchema
+func validateSchema(root, schema map[string]interface{}, value interface{}, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/definitions/")
+		def, ok := root["definitions"].(map[string]interface{})[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: unresolved $ref %q", path, ref)
+		}
+		return validateSchema(root, def, value, path)
+	}
+
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		for _, sub := range anyOf {
+			if validateSchema(root, sub.(map[string]interface{}), value, path) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: no anyOf schema matches", path)
+	}
+
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, s := range t {
+			types = append(types, s.(string))
+		}
+	}
+
+	typeOK := false
+	for _, typ := range types {
+		switch v := value.(type) {
+		case nil:
+			typeOK = typeOK || typ == "null"
+		case bool:
+			typeOK = typeOK || typ == "boolean"
+		case string:
+			typeOK = typeOK || typ == "string"
+		case float64:
+			typeOK = typeOK || typ == "number" || (typ == "integer" && v == math.Trunc(v))
+		case []interface{}:
+			typeOK = typeOK || typ == "array"
+		case map[string]interface{}:
+			typeOK = typeOK || typ == "object"
+		}
+	}
+	if !typeOK {
+		return fmt.Errorf("%s: %v is not of type %v", path, value, types)
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		if min, ok := schema["minItems"].(float64); ok && float64(len(v)) < min {
+			return fmt.Errorf("%s: %d items, want at least %v", path, len(v), min)
+		}
+		if max, ok := schema["maxItems"].(float64); ok && float64(len(v)) > max {
+			return fmt.Errorf("%s: %d items, want at most %v", path, len(v), max)
+		}
+		items := schema["items"].(map[string]interface{})
+		for i, item := range v {
+			if err := validateSchema(root, items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		properties := schema["properties"].(map[string]interface{})
+		for _, name := range schema["required"].([]interface{}) {
+			if _, ok := v[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		for name, prop := range v {
+			propSchema, ok := properties[name].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s: unexpected property %q", path, name)
+			}
+			if err := validateSchema(root, propSchema, prop, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func TestMatchJSONSchema(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(MatchJSONSchema(), &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	if schema["$schema"] != "http://json-schema.org/draft-07/schema#" {
+		t.Errorf("$schema = %v", schema["$schema"])
+	}
+	for _, name := range []string{"Game", "Move", "CheckerMove", "CubeMove", "CheckerAnalysis", "CubeAnalysis", "Position"} {
+		if _, ok := schema["definitions"].(map[string]interface{})[name]; !ok {
+			t.Errorf("definition %s missing", name)
+		}
+	}
+
+	eval := [7]float32{0.01, 0.2, 0.4, 0, 0.3, 0.02, 0.25}
+	move := testMoveRecord(1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1})
+	putMoveCandidate(move, 0, [8]int8{7, 4, 5, 4, -1, -1, -1, -1}, eval, 3)
+
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 5),
+		testGameHeader(1, 0, 0),
+		move,
+		testCubeRecord(-1, 1, 1, 1),
+		testGameFooter(1, 2),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+	match.Games[0].Moves[0].Comment = "opening"
+
+	data, err := match.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := validateSchema(schema, schema, value, "match"); err != nil {
+		t.Errorf("match does not validate: %v", err)
+	}
+
+	// The validator must reject documents that do not follow the schema
+	value.(map[string]interface{})["games"].([]interface{})[0].(map[string]interface{})["winner"] = "Alice"
+	if err := validateSchema(schema, schema, value, "match"); err == nil {
+		t.Errorf("invalid match validated")
+	}
+}
@@ -0,0 +1,153 @@
+//
+//   xgstats.go - Match statistics
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+// Move classifications by equity loss, with XG's default thresholds
+const (
+	ClassificationNone     = ""         // Loss below 0.020
+	ClassificationDoubtful = "doubtful" // Loss from 0.020
+	ClassificationError    = "error"    // Loss from 0.040
+	ClassificationBlunder  = "blunder"  // Loss from 0.080
+)
+
+// ClassifyEquityLoss returns the classification of a decision losing loss
+// equity compared to the best play
+func ClassifyEquityLoss(loss float32) string {
+	switch {
+	case loss >= 0.080:
+		return ClassificationBlunder
+	case loss >= 0.040:
+		return ClassificationError
+	case loss >= 0.020:
+		return ClassificationDoubtful
+	}
+	return ClassificationNone
+}
+
+// annotationGlyphs maps each classification to its annotation glyph
+var annotationGlyphs = map[string]string{
+	ClassificationNone:     "",
+	ClassificationDoubtful: "?!",
+	ClassificationError:    "?",
+	ClassificationBlunder:  "??",
+}
+
+// AnnotateMove returns the annotation glyph of an analysed candidate, as in
+// PGN: "?!" for a doubtful play, "?" for an error and "??" for a blunder.
+// The equity loss is measured against best, the equity of the best candidate,
+// and classified with ClassifyEquityLoss; good plays get no glyph.
+func AnnotateMove(a CheckerAnalysis, best float32) string {
+	return annotationGlyphs[ClassifyEquityLoss(best-a.Equity)]
+}
+
+// samePlay reports whether a played move and an analyzed candidate move the
+// same checkers. The from/to pairs may be listed in a different order.
+func samePlay(played [8]int32, candidate [8]int8) bool {
+	counts := make(map[[2]int32]int)
+	for i := 0; i < 8 && played[i] != -1; i += 2 {
+		counts[[2]int32{played[i], played[i+1]}]++
+	}
+	for i := 0; i < 8 && candidate[i] != -1; i += 2 {
+		pair := [2]int32{int32(candidate[i]), int32(candidate[i+1])}
+		if counts[pair] == 0 {
+			return false
+		}
+		counts[pair]--
+	}
+	for _, n := range counts {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// playedAnalysis returns the analysis of the played move, or nil when the
+// played move is not among the analyzed candidates
+func (m *CheckerMove) playedAnalysis() *CheckerAnalysis {
+	for i := range m.Analysis {
+		if samePlay(m.PlayedMove, m.Analysis[i].Move) {
+			return &m.Analysis[i]
+		}
+	}
+	return nil
+}
+
+// checkerEquityLoss returns the equity lost by the played move compared to
+// the best analyzed move. ok is false when the played move is not analyzed.
+func (m *CheckerMove) checkerEquityLoss() (loss float32, ok bool) {
+	played := m.playedAnalysis()
+	if played == nil {
+		return 0, false
+	}
+	return bestCheckerAnalysis(m.Analysis).Equity - played.Equity, true
+}
+
+// cubeActionEquity returns the cubeful equity of the cube action taken
+func (m *CubeMove) cubeActionEquity() float32 {
+	a := m.Analysis
+	switch m.CubeAction {
+	case CubeDouble:
+		if a.CubefulDoublePass < a.CubefulDoubleTake {
+			return a.CubefulDoublePass
+		}
+		return a.CubefulDoubleTake
+	case CubeTake, CubeBeaver, CubeRaccoon:
+		return a.CubefulDoubleTake
+	case CubePass:
+		return a.CubefulDoublePass
+	}
+	return a.CubefulNoDouble
+}
+
+// cubeEquityLoss returns the equity lost by the cube action taken compared to
+// the best one: the doubler's loss for doubling or not, plus the taker's loss
+// for the response when it is known (CubeTake, CubePass or a beaver).
+func (m *CubeMove) cubeEquityLoss() float32 {
+	a := m.Analysis
+	_, bestEquity := a.bestCubeAction()
+
+	// Equity once doubled, with the correct response
+	doubled := a.CubefulDoubleTake
+	if a.CubefulDoublePass < doubled {
+		doubled = a.CubefulDoublePass
+	}
+
+	var loss float32
+	switch m.CubeAction {
+	case CubeNoDouble:
+		loss = bestEquity - a.CubefulNoDouble
+	case CubeDouble:
+		loss = bestEquity - doubled
+	case CubeTake, CubeBeaver, CubeRaccoon:
+		// A beaver keeps the cube like a take; the redouble is not analyzed
+		loss = bestEquity - doubled + a.CubefulDoubleTake - doubled
+	case CubePass:
+		loss = bestEquity - doubled + a.CubefulDoublePass - doubled
+	}
+	return loss
+}
+
+// TotalEquityLoss sums the equity lost by both players over the match, for
+// checker plays and cube decisions separately. Checker loss is the difference
+// between the best analyzed move and the played one; cube loss the difference
+// between the best cube action and the one taken. Decisions without analysis
+// are ignored.
+func (m *Match) TotalEquityLoss() (checker, cube float32) {
+	for _, game := range m.Games {
+		for _, move := range game.Moves {
+			switch {
+			case move.CheckerMove != nil:
+				if loss, ok := move.CheckerMove.checkerEquityLoss(); ok {
+					checker += loss
+				}
+			case move.CubeMove != nil && move.CubeMove.Analysis != nil:
+				cube += move.CubeMove.cubeEquityLoss()
+			}
+		}
+	}
+	return checker, cube
+}
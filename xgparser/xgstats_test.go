@@ -0,0 +1,135 @@
+//
+//   xgstats_test.go - Unit tests for match statistics
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTotalEquityLoss(t *testing.T) {
+	best := [8]int8{13, 10, 10, 9, -1, -1, -1, -1}
+	second := [8]int8{24, 23, 13, 10, -1, -1, -1, -1}
+	analysis := []CheckerAnalysis{
+		{Move: best, Equity: 0.05},
+		{Move: second, Equity: -0.02},
+	}
+	cube := &CubeAnalysis{CubefulNoDouble: 0.70, CubefulDoubleTake: 0.85, CubefulDoublePass: 1}
+
+	match := &Match{Games: []Game{{
+		Moves: []Move{
+			// Best move played: no loss
+			{MoveType: "checker", CheckerMove: &CheckerMove{PlayedMove: [8]int32{13, 10, 10, 9, -1, -1, -1, -1}, Analysis: analysis}},
+			// Second best, pairs in a different order: 0.07
+			{MoveType: "checker", CheckerMove: &CheckerMove{PlayedMove: [8]int32{13, 10, 24, 23, -1, -1, -1, -1}, Analysis: analysis}},
+			// Played move not analyzed: ignored
+			{MoveType: "checker", CheckerMove: &CheckerMove{PlayedMove: [8]int32{8, 5, 6, 5, -1, -1, -1, -1}, Analysis: analysis}},
+			// Missed double: 0.15
+			{MoveType: "cube", CubeMove: &CubeMove{CubeAction: 0, Analysis: cube}},
+			// Correct double: no loss
+			{MoveType: "cube", CubeMove: &CubeMove{CubeAction: 1, Analysis: cube}},
+			// Double, wrong pass: 0.15
+			{MoveType: "cube", CubeMove: &CubeMove{CubeAction: 3, Analysis: cube}},
+			// No analysis: ignored
+			{MoveType: "cube", CubeMove: &CubeMove{CubeAction: 0}},
+		},
+	}}}
+
+	checker, cubeLoss := match.TotalEquityLoss()
+	if math.Abs(float64(checker-0.07)) > 1e-6 {
+		t.Errorf("checker loss = %v, want 0.07", checker)
+	}
+	if math.Abs(float64(cubeLoss-0.30)) > 1e-6 {
+		t.Errorf("cube loss = %v, want 0.30", cubeLoss)
+	}
+}
+
+func TestCubeEquityLoss_WrongDouble(t *testing.T) {
+	// Too good to double: doubling gives away the gammon wins
+	move := &CubeMove{CubeAction: 1, Analysis: &CubeAnalysis{CubefulNoDouble: 1.2, CubefulDoubleTake: 1.6, CubefulDoublePass: 1}}
+	if loss := move.cubeEquityLoss(); math.Abs(float64(loss-0.2)) > 1e-6 {
+		t.Errorf("cubeEquityLoss() = %v, want 0.2", loss)
+	}
+}
+
+func TestParseXG_CubeResponseEquityLoss(t *testing.T) {
+	// Double/Take is right: 0.85 against 1.0 for a pass and 0.70 without double
+	cube := func(take int32) []byte {
+		rec := testCubeRecord(Player1, 1, take, 1)
+		putFloat32(rec, offCEDEquB, 0.70)
+		putFloat32(rec, offCEDEquDouble, 0.85)
+		putFloat32(rec, offCEDEquDrop, 1.0)
+		return rec
+	}
+
+	match, err := ParseXG(testGameFileSegments(
+		testMatchHeader("Alice", "Bob", 7),
+		testGameHeader(1, 0, 0),
+		cube(1), // Take: no loss
+		testGameFooter(Player1, 1),
+		testGameHeader(2, 1, 0),
+		cube(0), // Wrong pass: 0.15
+		testGameFooter(Player1, 1),
+		testGameHeader(3, 2, 0),
+		cube(2), // Beaver, kept like a take: no loss
+		testGameFooter(Player1, 4),
+	))
+	if err != nil {
+		t.Fatalf("ParseXG() error = %v", err)
+	}
+
+	for i, want := range []int32{CubeTake, CubePass, CubeBeaver} {
+		if got := match.Games[i].Moves[0].CubeMove.CubeAction; got != want {
+			t.Errorf("game %d CubeAction = %d, want %d", i+1, got, want)
+		}
+	}
+	if _, cubeLoss := match.TotalEquityLoss(); math.Abs(float64(cubeLoss-0.15)) > 1e-6 {
+		t.Errorf("cube loss = %v, want 0.15 for the wrong pass", cubeLoss)
+	}
+}
+
+func TestClassifyEquityLoss(t *testing.T) {
+	tests := []struct {
+		loss float32
+		want string
+	}{
+		{0, ClassificationNone},
+		{0.019, ClassificationNone},
+		{0.020, ClassificationDoubtful},
+		{0.039, ClassificationDoubtful},
+		{0.040, ClassificationError},
+		{0.079, ClassificationError},
+		{0.080, ClassificationBlunder},
+		{0.5, ClassificationBlunder},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyEquityLoss(tt.loss); got != tt.want {
+			t.Errorf("ClassifyEquityLoss(%v) = %q, want %q", tt.loss, got, tt.want)
+		}
+	}
+}
+
+func TestAnnotateMove(t *testing.T) {
+	tests := []struct {
+		loss float32
+		want string
+	}{
+		{0, ""},
+		{0.015, ""},
+		{0.025, "?!"},
+		{0.05, "?"},
+		{0.12, "??"},
+	}
+
+	const best = 0.25
+	for _, tt := range tests {
+		a := CheckerAnalysis{Equity: best - tt.loss}
+		if got := AnnotateMove(a, best); got != tt.want {
+			t.Errorf("AnnotateMove() with loss %v = %q, want %q", tt.loss, got, tt.want)
+		}
+	}
+}
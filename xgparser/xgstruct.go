@@ -27,10 +27,38 @@ package xgparser
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 )
 
+// ErrNotXGFile is returned when the input is not an XG file at all
+var ErrNotXGFile = errors.New("not an XG file")
+
+// EntryType identifies the kind of a game file record
+type EntryType int
+
+// Game file record types, stored in the byte at offset 8 of each record
+const (
+	EntryHeaderMatch EntryType = iota
+	EntryHeaderGame
+	EntryCube
+	EntryMove
+	EntryFooterGame
+	EntryFooterMatch
+)
+
+var entryTypeNames = []string{"HeaderMatch", "HeaderGame", "Cube", "Move", "FooterGame", "FooterMatch"}
+
+// String returns the name of the entry type
+func (t EntryType) String() string {
+	if t < 0 || int(t) >= len(entryTypeNames) {
+		return fmt.Sprintf("EntryType(%d)", int(t))
+	}
+	return entryTypeNames[t]
+}
+
 // GameDataFormatHdrRecord represents the game data format header
 type GameDataFormatHdrRecord struct {
 	MagicNumber     [4]byte
@@ -65,7 +93,7 @@ func (g *GameDataFormatHdrRecord) FromStream(r io.Reader) error {
 
 	err := binary.Read(r, binary.LittleEndian, &hdr)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %v", ErrNotXGFile, err)
 	}
 
 	// Reverse magic number bytes
@@ -75,7 +103,7 @@ func (g *GameDataFormatHdrRecord) FromStream(r io.Reader) error {
 	g.MagicNumber[3] = hdr.Magic[0]
 
 	if string(g.MagicNumber[:]) != "HMGR" || hdr.HeaderVersion != 1 {
-		return fmt.Errorf("invalid magic number or version")
+		return fmt.Errorf("%w: invalid magic number or version", ErrNotXGFile)
 	}
 
 	g.HeaderVersion = hdr.HeaderVersion
@@ -111,6 +139,22 @@ func (g *GameDataFormatHdrRecord) FromStream(r io.Reader) error {
 	return nil
 }
 
+// Validate checks that the header, and the thumbnail stored after it, fit in
+// a file of fileSize bytes. The thumbnail offset is relative to the end of the header.
+func (g *GameDataFormatHdrRecord) Validate(fileSize int64) error {
+	if g.HeaderSize < 0 || int64(g.HeaderSize) > fileSize {
+		return fmt.Errorf("invalid header size %d for a %d byte file", g.HeaderSize, fileSize)
+	}
+	if g.ThumbnailSize > 0 {
+		end := int64(g.HeaderSize) + g.ThumbnailOffset + int64(g.ThumbnailSize)
+		if g.ThumbnailOffset < 0 || end < 0 || end > fileSize {
+			return fmt.Errorf("invalid thumbnail: offset %d, size %d for a %d byte file",
+				g.ThumbnailOffset, g.ThumbnailSize, fileSize)
+		}
+	}
+	return nil
+}
+
 // TimeSettingRecord represents time settings
 type TimeSettingRecord struct {
 	ClockType    int32
@@ -146,6 +190,66 @@ func (t *TimeSettingRecord) FromStream(r io.Reader) error {
 	return nil
 }
 
+// recordDecoder decodes the little-endian fields of a record read at once,
+// in order from the start of buf
+type recordDecoder struct {
+	buf []byte
+	off int
+}
+
+func (d *recordDecoder) skip(n int) {
+	d.off += n
+}
+
+func (d *recordDecoder) int8() int8 {
+	v := int8(d.buf[d.off])
+	d.off++
+	return v
+}
+
+func (d *recordDecoder) bool() bool {
+	return d.int8() != 0
+}
+
+func (d *recordDecoder) int8s(v []int8) {
+	for i := range v {
+		v[i] = int8(d.buf[d.off+i])
+	}
+	d.off += len(v)
+}
+
+func (d *recordDecoder) uint16() uint16 {
+	v := binary.LittleEndian.Uint16(d.buf[d.off:])
+	d.off += 2
+	return v
+}
+
+func (d *recordDecoder) uint32() uint32 {
+	v := binary.LittleEndian.Uint32(d.buf[d.off:])
+	d.off += 4
+	return v
+}
+
+func (d *recordDecoder) int32() int32 {
+	return int32(d.uint32())
+}
+
+func (d *recordDecoder) int32s(v []int32) {
+	for i := range v {
+		v[i] = d.int32()
+	}
+}
+
+func (d *recordDecoder) float32() float32 {
+	return math.Float32frombits(d.uint32())
+}
+
+func (d *recordDecoder) float64() float64 {
+	v := math.Float64frombits(binary.LittleEndian.Uint64(d.buf[d.off:]))
+	d.off += 8
+	return v
+}
+
 // EvalLevelRecord represents evaluation level
 type EvalLevelRecord struct {
 	Level    int16
@@ -243,58 +347,83 @@ type EngineStructBestMoveRecord struct {
 	Choice3   int8
 }
 
+// engineStructBestMoveSize is the size of EngineStructBestMoveRecord in a record
+const engineStructBestMoveSize = 2184
+
 // FromStream reads EngineStructBestMoveRecord from stream
 func (e *EngineStructBestMoveRecord) FromStream(r io.Reader) error {
-	// Read header
-	err := binary.Read(r, binary.LittleEndian, &e.Pos)
-	if err != nil {
+	buf := make([]byte, engineStructBestMoveSize)
+	if n, err := io.ReadFull(r, buf); n < len(e.Pos) {
 		return err
 	}
+	e.decode(&recordDecoder{buf: buf}, false)
+	return nil
+}
 
-	var padding [2]byte
-	binary.Read(r, binary.LittleEndian, &padding)
-
-	binary.Read(r, binary.LittleEndian, &e.Dice)
-	binary.Read(r, binary.LittleEndian, &e.Level)
-	binary.Read(r, binary.LittleEndian, &e.Score)
-	binary.Read(r, binary.LittleEndian, &e.Cube)
-	binary.Read(r, binary.LittleEndian, &e.Cubepos) // Read into Cubepos, leave CubePos at default 0
-	binary.Read(r, binary.LittleEndian, &e.Crawford)
-	binary.Read(r, binary.LittleEndian, &e.Jacoby)
-	binary.Read(r, binary.LittleEndian, &e.NMoves)
-
-	// Read positions played
-	for i := 0; i < 32; i++ {
-		binary.Read(r, binary.LittleEndian, &e.PosPlayed[i])
+// FromStreamAnalysed reads EngineStructBestMoveRecord from stream as
+// FromStream, decoding only the NMoves analysed candidates: the other rows of
+// PosPlayed, Moves, EvalLevel and Eval are skipped and left at zero
+func (e *EngineStructBestMoveRecord) FromStreamAnalysed(r io.Reader) error {
+	buf := make([]byte, engineStructBestMoveSize)
+	if n, err := io.ReadFull(r, buf); n < len(e.Pos) {
+		return err
 	}
+	e.decode(&recordDecoder{buf: buf}, true)
+	return nil
+}
 
-	// Read moves
-	for i := 0; i < 32; i++ {
-		binary.Read(r, binary.LittleEndian, &e.Moves[i])
+// decode decodes EngineStructBestMoveRecord from a record buffer, only the
+// NMoves analysed candidates with analysedOnly
+func (e *EngineStructBestMoveRecord) decode(d *recordDecoder, analysedOnly bool) {
+	d.int8s(e.Pos[:])
+	d.skip(2)
+
+	d.int32s(e.Dice[:])
+	e.Level = d.int32()
+	d.int32s(e.Score[:])
+	e.Cube = d.int32()
+	e.Cubepos = d.int32() // Read into Cubepos, leave CubePos at default 0
+	e.Crawford = d.int32()
+	e.Jacoby = d.int32()
+	e.NMoves = d.int32()
+
+	rows := len(e.Moves)
+	if analysedOnly && int(e.NMoves) < rows {
+		rows = max(int(e.NMoves), 0)
 	}
+	skipped := len(e.Moves) - rows
 
-	// Read eval levels
-	for i := 0; i < 32; i++ {
-		e.EvalLevel[i].FromStream(r)
+	for i := range e.PosPlayed[:rows] {
+		d.int8s(e.PosPlayed[i][:])
 	}
-
-	// Read evaluations
-	for i := 0; i < 32; i++ {
-		binary.Read(r, binary.LittleEndian, &e.Eval[i])
+	d.skip(skipped * len(e.PosPlayed[0]))
+	for i := range e.Moves[:rows] {
+		d.int8s(e.Moves[i][:])
 	}
+	d.skip(skipped * len(e.Moves[0]))
+	for i := range e.EvalLevel[:rows] {
+		e.EvalLevel[i].Level = int16(d.uint16())
+		e.EvalLevel[i].IsDouble = d.bool()
+		d.skip(1)
+	}
+	d.skip(skipped * 4)
+	for i := range e.Eval[:rows] {
+		for j := range e.Eval[i] {
+			e.Eval[i][j] = d.float32()
+		}
+	}
+	d.skip(skipped * len(e.Eval[0]) * 4)
 
-	binary.Read(r, binary.LittleEndian, &e.Unused)
-	binary.Read(r, binary.LittleEndian, &e.Met)
-	binary.Read(r, binary.LittleEndian, &e.Choice0)
-	binary.Read(r, binary.LittleEndian, &e.Choice3)
-
-	return nil
+	e.Unused = d.int8()
+	e.Met = d.int8()
+	e.Choice0 = d.int8()
+	e.Choice3 = d.int8()
 }
 
 // HeaderMatchEntry represents match information
 type HeaderMatchEntry struct {
 	Name                 string
-	EntryType            int
+	EntryType            EntryType
 	Version              int32
 	SPlayer1             string
 	SPlayer2             string
@@ -355,7 +484,7 @@ type HeaderMatchEntry struct {
 // FromStream reads HeaderMatchEntry from stream
 func (h *HeaderMatchEntry) FromStream(r io.Reader, version int32) error {
 	h.Name = "MatchInfo"
-	h.EntryType = 0 // ENTRYTYPE_HEADERMATCH
+	h.EntryType = EntryHeaderMatch
 
 	// Skip 9 bytes
 	var skip [9]byte
@@ -520,7 +649,7 @@ func (h *HeaderMatchEntry) FromStream(r io.Reader, version int32) error {
 // HeaderGameEntry represents game header
 type HeaderGameEntry struct {
 	Name                string
-	EntryType           int
+	EntryType           EntryType
 	Version             int32
 	Score1              int32
 	Score2              int32
@@ -536,7 +665,7 @@ type HeaderGameEntry struct {
 // FromStream reads HeaderGameEntry from stream
 func (h *HeaderGameEntry) FromStream(r io.Reader, version int32) error {
 	h.Name = "GameHeader"
-	h.EntryType = 1 // ENTRYTYPE_HEADERGAME
+	h.EntryType = EntryHeaderGame
 	h.Version = version
 
 	var skip [9]byte
@@ -581,7 +710,7 @@ func (h *HeaderGameEntry) FromStream(r io.Reader, version int32) error {
 // CubeEntry represents cube action
 type CubeEntry struct {
 	Name                   string
-	EntryType              int
+	EntryType              EntryType
 	Version                int32
 	ActiveP                int32
 	Double                 int32
@@ -618,7 +747,7 @@ type CubeEntry struct {
 // FromStream reads CubeEntry from stream
 func (c *CubeEntry) FromStream(r io.Reader, version int32) error {
 	c.Name = "Cube"
-	c.EntryType = 2 // ENTRYTYPE_CUBE
+	c.EntryType = EntryCube
 	c.Version = version
 
 	// Python format: '<9xxxxllllll26bxx'
@@ -735,7 +864,7 @@ func (c *CubeEntry) FromStream(r io.Reader, version int32) error {
 // MoveEntry represents a move
 type MoveEntry struct {
 	Name                   string
-	EntryType              int
+	EntryType              EntryType
 	Version                int32
 	PositionI              [26]int8
 	PositionEnd            [26]int8
@@ -768,80 +897,87 @@ type MoveEntry struct {
 
 // FromStream reads MoveEntry from stream
 func (m *MoveEntry) FromStream(r io.Reader, version int32) error {
-	m.Name = "Move"
-	m.EntryType = 3 // ENTRYTYPE_MOVE
-	m.Version = version
-
-	var skip [9]byte
-	binary.Read(r, binary.LittleEndian, &skip)
+	return m.fromStream(r, version, false)
+}
 
-	binary.Read(r, binary.LittleEndian, &m.PositionI)
-	binary.Read(r, binary.LittleEndian, &m.PositionEnd)
+// FromStreamAnalysed reads MoveEntry from stream as FromStream, decoding only
+// the analysed candidates of DataMoves, see
+// EngineStructBestMoveRecord.FromStreamAnalysed
+func (m *MoveEntry) FromStreamAnalysed(r io.Reader, version int32) error {
+	return m.fromStream(r, version, true)
+}
 
-	var padding1 [3]byte
-	binary.Read(r, binary.LittleEndian, &padding1)
+func (m *MoveEntry) fromStream(r io.Reader, version int32, analysedOnly bool) error {
+	m.Name = "Move"
+	m.EntryType = EntryMove
+	m.Version = version
 
-	binary.Read(r, binary.LittleEndian, &m.ActiveP)
-	binary.Read(r, binary.LittleEndian, &m.Moves)
-	binary.Read(r, binary.LittleEndian, &m.Dice)
-	binary.Read(r, binary.LittleEndian, &m.CubeA)
-	binary.Read(r, binary.LittleEndian, &m.ErrorM)
-	binary.Read(r, binary.LittleEndian, &m.NMoveEval)
+	// The fixed-size part of the record is read at once: a short read leaves
+	// the missing fields at zero
+	size := 2528
+	if version >= 24 {
+		size++
+	}
+	if version >= 26 {
+		size += 11
+	}
+	if version >= 27 {
+		size += 4
+	}
+	buf := make([]byte, size)
+	io.ReadFull(r, buf)
+	d := &recordDecoder{buf: buf}
+
+	d.skip(9)
+	d.int8s(m.PositionI[:])
+	d.int8s(m.PositionEnd[:])
+	d.skip(3)
+
+	m.ActiveP = d.int32()
+	d.int32s(m.Moves[:])
+	d.int32s(m.Dice[:])
+	m.CubeA = d.int32()
+	m.ErrorM = d.float64()
+	m.NMoveEval = d.int32()
 
 	m.DataMoves = &EngineStructBestMoveRecord{}
-	m.DataMoves.FromStream(r)
+	m.DataMoves.decode(d, analysedOnly)
 
-	var played byte
-	binary.Read(r, binary.LittleEndian, &played)
-	m.Played = played != 0
+	m.Played = d.bool()
+	d.skip(3)
 
-	var padding2 [3]byte
-	binary.Read(r, binary.LittleEndian, &padding2)
+	m.ErrMove = d.float64()
+	m.ErrLuck = d.float64()
+	m.CompChoice = d.int32()
+	d.skip(4)
 
-	binary.Read(r, binary.LittleEndian, &m.ErrMove)
-	binary.Read(r, binary.LittleEndian, &m.ErrLuck)
-	binary.Read(r, binary.LittleEndian, &m.CompChoice)
+	m.InitEq = d.float64()
+	d.int32s(m.RolloutIndexM[:])
+	m.AnalyzeM = d.int32()
+	m.AnalyzeL = d.int32()
+	m.InvalidM = d.int32()
+	d.int8s(m.PositionTutor[:])
+	m.Tutor = d.int8()
+	d.skip(1)
 
-	var padding3 [4]byte
-	binary.Read(r, binary.LittleEndian, &padding3)
-
-	binary.Read(r, binary.LittleEndian, &m.InitEq)
-	binary.Read(r, binary.LittleEndian, &m.RolloutIndexM)
-	binary.Read(r, binary.LittleEndian, &m.AnalyzeM)
-	binary.Read(r, binary.LittleEndian, &m.AnalyzeL)
-	binary.Read(r, binary.LittleEndian, &m.InvalidM)
-	binary.Read(r, binary.LittleEndian, &m.PositionTutor)
-	binary.Read(r, binary.LittleEndian, &m.Tutor)
+	m.ErrTutorMove = d.float64()
+	m.Flagged = d.bool()
+	d.skip(3)
 
-	var padding4 byte
-	binary.Read(r, binary.LittleEndian, &padding4)
-
-	binary.Read(r, binary.LittleEndian, &m.ErrTutorMove)
-
-	var flagged byte
-	binary.Read(r, binary.LittleEndian, &flagged)
-	m.Flagged = flagged != 0
-
-	var padding5 [3]byte
-	binary.Read(r, binary.LittleEndian, &padding5)
-
-	binary.Read(r, binary.LittleEndian, &m.CommentMove)
+	m.CommentMove = d.int32()
 
 	if version >= 24 {
-		var editedMove byte
-		binary.Read(r, binary.LittleEndian, &editedMove)
-		m.EditedMove = editedMove != 0
+		m.EditedMove = d.bool()
 	}
 
 	if version >= 26 {
-		var padding6 [3]byte
-		binary.Read(r, binary.LittleEndian, &padding6)
-		binary.Read(r, binary.LittleEndian, &m.TimeDelayMove)
-		binary.Read(r, binary.LittleEndian, &m.TimeDelayMoveDone)
+		d.skip(3)
+		m.TimeDelayMove = d.uint32()
+		m.TimeDelayMoveDone = d.uint32()
 	}
 
 	if version >= 27 {
-		binary.Read(r, binary.LittleEndian, &m.NumberOfAutoDoubleMove)
+		m.NumberOfAutoDoubleMove = d.int32()
 	}
 
 	return nil
@@ -850,7 +986,7 @@ func (m *MoveEntry) FromStream(r io.Reader, version int32) error {
 // FooterGameEntry represents game footer
 type FooterGameEntry struct {
 	Name           string
-	EntryType      int
+	EntryType      EntryType
 	Version        int32
 	Score1g        int32
 	Score2g        int32
@@ -867,7 +1003,7 @@ type FooterGameEntry struct {
 // FromStream reads FooterGameEntry from stream
 func (f *FooterGameEntry) FromStream(r io.Reader, version int32) error {
 	f.Name = "GameFooter"
-	f.EntryType = 4 // ENTRYTYPE_FOOTERGAME
+	f.EntryType = EntryFooterGame
 	f.Version = version
 
 	var skip [9]byte
@@ -907,7 +1043,7 @@ func (f *FooterGameEntry) FromStream(r io.Reader, version int32) error {
 // FooterMatchEntry represents match footer
 type FooterMatchEntry struct {
 	Name      string
-	EntryType int
+	EntryType EntryType
 	Version   int32
 	Score1m   int32
 	Score2m   int32
@@ -922,7 +1058,7 @@ type FooterMatchEntry struct {
 // FromStream reads FooterMatchEntry from stream
 func (f *FooterMatchEntry) FromStream(r io.Reader, version int32) error {
 	f.Name = "MatchFooter"
-	f.EntryType = 5 // ENTRYTYPE_FOOTERMATCH
+	f.EntryType = EntryFooterMatch
 	f.Version = version
 
 	var skip [9]byte
@@ -948,11 +1084,22 @@ func (f *FooterMatchEntry) FromStream(r io.Reader, version int32) error {
 	return nil
 }
 
+// defaultRecordSize is the stride of every record in the game file
+const defaultRecordSize = 2560
+
+// recordSize returns the record stride for the given file version.
+// All known XG versions use 2560-byte records; new formats with a different
+// stride only need to be added here.
+func recordSize(version int32) int64 {
+	return defaultRecordSize
+}
+
 // GameFileRecord represents a record in the game file
 type GameFileRecord struct {
-	EntryType int
-	Version   int32
-	Record    interface{}
+	EntryType    EntryType
+	Version      int32
+	Record       interface{}
+	AnalysedOnly bool // MoveEntry records are read with FromStreamAnalysed
 }
 
 // FromStream reads GameFileRecord from stream
@@ -969,7 +1116,7 @@ func (g *GameFileRecord) FromStream(r io.Reader, version int32) error {
 		return err
 	}
 
-	g.EntryType = int(header.EntryType)
+	g.EntryType = EntryType(header.EntryType)
 	g.Version = version
 
 	// Seek back to start
@@ -977,27 +1124,31 @@ func (g *GameFileRecord) FromStream(r io.Reader, version int32) error {
 
 	// Read appropriate record type
 	switch g.EntryType {
-	case 0: // ENTRYTYPE_HEADERMATCH
+	case EntryHeaderMatch:
 		rec := &HeaderMatchEntry{}
 		err = rec.FromStream(r, version)
 		g.Record = rec
-	case 1: // ENTRYTYPE_HEADERGAME
+	case EntryHeaderGame:
 		rec := &HeaderGameEntry{}
 		err = rec.FromStream(r, version)
 		g.Record = rec
-	case 2: // ENTRYTYPE_CUBE
+	case EntryCube:
 		rec := &CubeEntry{}
 		err = rec.FromStream(r, version)
 		g.Record = rec
-	case 3: // ENTRYTYPE_MOVE
+	case EntryMove:
 		rec := &MoveEntry{}
-		err = rec.FromStream(r, version)
+		if g.AnalysedOnly {
+			err = rec.FromStreamAnalysed(r, version)
+		} else {
+			err = rec.FromStream(r, version)
+		}
 		g.Record = rec
-	case 4: // ENTRYTYPE_FOOTERGAME
+	case EntryFooterGame:
 		rec := &FooterGameEntry{}
 		err = rec.FromStream(r, version)
 		g.Record = rec
-	case 5: // ENTRYTYPE_FOOTERMATCH
+	case EntryFooterMatch:
 		rec := &FooterMatchEntry{}
 		err = rec.FromStream(r, version)
 		g.Record = rec
@@ -1010,10 +1161,106 @@ func (g *GameFileRecord) FromStream(r io.Reader, version int32) error {
 		return err
 	}
 
-	// Each record is 2560 bytes, advance to next
+	// Each record has a fixed size, advance to next
 	realRecSize, _ := r.(*bytes.Reader).Seek(0, io.SeekCurrent)
 	realRecSize -= startPos
-	r.(*bytes.Reader).Seek(2560-realRecSize, io.SeekCurrent)
+	r.(*bytes.Reader).Seek(recordSize(version)-realRecSize, io.SeekCurrent)
 
 	return nil
 }
+
+// rolloutContextSize is the stride of every record in the rollout file (temp.xgr)
+const rolloutContextSize = 2184
+
+// RolloutContextEntry represents the settings and progress of a rollout, a
+// record of the rollout file. Only the settings at the start of the record are
+// read; the per-trial sums and results that follow are skipped.
+type RolloutContextEntry struct {
+	Truncated      bool    // Trials are truncated after Truncate plies
+	ErrorLimited   bool    // Stop when the confidence interval is under ErrorLimit
+	Truncate       int32   // Truncation ply
+	MinRoll        int32   // Minimum number of trials
+	ErrorLimit     float64 // Confidence interval stopping the rollout
+	MaxRoll        int32   // Maximum number of trials
+	Level1         int32   // Checker play level before LevelCut
+	Level2         int32   // Checker play level after LevelCut
+	LevelCut       int32   // Ply switching from Level1 to Level2
+	Variance       bool    // Variance reduction
+	Cubeless       bool    // Cubeless rollout
+	Time           bool    // Time limited rollout
+	Level1C        int32   // Cube decision level before LevelCut
+	Level2C        int32   // Cube decision level after LevelCut
+	TimeLimit      int32   // Time limit in minutes
+	TruncBO        int32   // Truncation at the bear-off database
+	RandomSeed     int32
+	RandomSeedI    int32
+	RollBoth       bool    // Both No Double and Double/Take were rolled out
+	SearchInterval float32 // Search interval, in standard deviations
+	Met            int32
+	FirstRoll      bool  // Rollout of the first roll of a game
+	DoDouble       bool  // Rolled out as Double/Take
+	Extent         bool  // The rollout was extended
+	Rolled         int32 // Trials played
+	DoubleFirst    bool  // A double happens immediately
+}
+
+// FromStream reads RolloutContextEntry from stream, consuming the whole record
+func (c *RolloutContextEntry) FromStream(r io.Reader) error {
+	// Python format: '<BBxxllxxxxdllllBBBxllllllBxxxflBBBxlB'
+	var settings struct {
+		Truncated, ErrorLimited              bool
+		_                                    [2]byte
+		Truncate, MinRoll                    int32
+		_                                    [4]byte
+		ErrorLimit                           float64
+		MaxRoll, Level1, Level2, LevelCut    int32
+		Variance, Cubeless, Time             bool
+		_                                    byte
+		Level1C, Level2C, TimeLimit, TruncBO int32
+		RandomSeed, RandomSeedI              int32
+		RollBoth                             bool
+		_                                    [3]byte
+		SearchInterval                       float32
+		Met                                  int32
+		FirstRoll, DoDouble, Extent          bool
+		_                                    byte
+		Rolled                               int32
+		DoubleFirst                          bool
+	}
+	if err := binary.Read(r, binary.LittleEndian, &settings); err != nil {
+		return err
+	}
+
+	*c = RolloutContextEntry{
+		Truncated:      settings.Truncated,
+		ErrorLimited:   settings.ErrorLimited,
+		Truncate:       settings.Truncate,
+		MinRoll:        settings.MinRoll,
+		ErrorLimit:     settings.ErrorLimit,
+		MaxRoll:        settings.MaxRoll,
+		Level1:         settings.Level1,
+		Level2:         settings.Level2,
+		LevelCut:       settings.LevelCut,
+		Variance:       settings.Variance,
+		Cubeless:       settings.Cubeless,
+		Time:           settings.Time,
+		Level1C:        settings.Level1C,
+		Level2C:        settings.Level2C,
+		TimeLimit:      settings.TimeLimit,
+		TruncBO:        settings.TruncBO,
+		RandomSeed:     settings.RandomSeed,
+		RandomSeedI:    settings.RandomSeedI,
+		RollBoth:       settings.RollBoth,
+		SearchInterval: settings.SearchInterval,
+		Met:            settings.Met,
+		FirstRoll:      settings.FirstRoll,
+		DoDouble:       settings.DoDouble,
+		Extent:         settings.Extent,
+		Rolled:         settings.Rolled,
+		DoubleFirst:    settings.DoubleFirst,
+	}
+
+	// Skip the rest of the record
+	_, err := io.CopyN(io.Discard, r, rolloutContextSize-int64(binary.Size(settings)))
+	return err
+}
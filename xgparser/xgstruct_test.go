@@ -0,0 +1,512 @@
+//
+//   xgstruct_test.go - Unit tests for XG binary records
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// Byte offsets of the fields used by the tests inside a 2560-byte game file
+// record. They follow the struct layouts read by the FromStream methods.
+const (
+	testRecSize = 2560
+
+	// HeaderMatchEntry
+	offHMPlayer1     = 9
+	offHMPlayer2     = 50
+	offHMMatchLength = 92
+	offHMVariation   = 96
+	offHMCrawford    = 100
+	offHMDate        = 128
+	offHMEvent       = 136
+	offHMGameId      = 268
+	offHMVersion     = 552
+	offHMMagic       = 556
+	offHMCommentHdr  = 576
+	offHMCommentFtr  = 580
+	offHMWinMoney    = 588
+	offHMLoseMoney   = 592
+	offHMCurrency    = 596
+	offHMFeeMoney    = 600
+	offHMTableStake  = 604
+	offHMSiteId      = 608
+
+	// HeaderGameEntry
+	offHGScore1     = 12
+	offHGScore2     = 16
+	offHGPosInit    = 21
+	offHGGameNumber = 48
+	offHGInProgress = 52
+	offHGCommentHdr = 56
+	offHGCommentFtr = 60
+
+	// CubeEntry
+	offCEActiveP     = 12
+	offCEDouble      = 16
+	offCETake        = 20
+	offCECubeB       = 32
+	offCEDCube       = 104
+	offCEDCubePos    = 108
+	offCEDEval       = 124
+	offCEDEquB       = 152
+	offCEDEquDouble  = 156
+	offCEDEquDrop    = 160
+	offCERolloutIdx  = 224
+	offCECompChoiceD = 228
+	offCEAnalyzeC    = 232
+	offCECommentCube = 292
+
+	// MoveEntry
+	offMEPositionI   = 9
+	offMEActiveP     = 64
+	offMEMoves       = 68
+	offMEDice        = 100
+	offMECubeA       = 108
+	offMEDDice       = 152
+	offMEDCube       = 172
+	offMEDCubePos    = 176
+	offMEDNMoves     = 188
+	offMEDPosPlayed  = 192
+	offMEDMoves      = 1024
+	offMEDEvalLevel  = 1280
+	offMEDEval       = 1408
+	offMEErrMove     = 2312
+	offMEErrLuck     = 2320
+	offMECompChoice  = 2328
+	offMEInitEq      = 2336
+	offMERolloutIdx  = 2344
+	offMEAnalyzeM    = 2472
+	offMEInvalidM    = 2480
+	offMECommentMove = 2524
+
+	// FooterGameEntry
+	offFGWinner      = 24
+	offFGPointsWon   = 28
+	offFGTermination = 32
+	offFGErrResign   = 40
+	offFGErrTakeRes  = 48
+
+	// FooterMatchEntry
+	offFMScore1 = 12
+	offFMScore2 = 16
+	offFMWinner = 20
+	offFMElo1   = 24
+	offFMElo2   = 32
+
+	// RolloutContextEntry
+	offRCTruncated = 0
+	offRCTruncate  = 4
+	offRCMinRoll   = 8
+	offRCMaxRoll   = 24
+	offRCLevel1    = 28
+	offRCVariance  = 40
+	offRCLevel1C   = 44
+	offRCSeed      = 60
+	offRCRolled    = 84
+)
+
+// testRecord returns a zeroed game file record of the given entry type
+func testRecord(entryType byte) []byte {
+	rec := make([]byte, testRecSize)
+	rec[8] = entryType
+	return rec
+}
+
+func putInt32(rec []byte, off int, v int32) {
+	binary.LittleEndian.PutUint32(rec[off:], uint32(v))
+}
+
+func putFloat32(rec []byte, off int, v float32) {
+	binary.LittleEndian.PutUint32(rec[off:], math.Float32bits(v))
+}
+
+func putFloat64(rec []byte, off int, v float64) {
+	binary.LittleEndian.PutUint64(rec[off:], math.Float64bits(v))
+}
+
+func putShortStr(rec []byte, off int, s string) {
+	rec[off] = byte(len(s))
+	copy(rec[off+1:], s)
+}
+
+func putPosition(rec []byte, off int, pos [26]int8) {
+	for i, v := range pos {
+		rec[off+i] = byte(v)
+	}
+}
+
+// testMatchHeader builds a version 30 HeaderMatchEntry record
+func testMatchHeader(player1, player2 string, matchLength int32) []byte {
+	rec := testRecord(0)
+	putShortStr(rec, offHMPlayer1, player1)
+	putShortStr(rec, offHMPlayer2, player2)
+	putInt32(rec, offHMMatchLength, matchLength)
+	putInt32(rec, offHMVersion, 30)
+	copy(rec[offHMMagic:], "DMLI")
+	putInt32(rec, offHMCommentHdr, -1)
+	putInt32(rec, offHMCommentFtr, -1)
+	return rec
+}
+
+// testGameHeader builds a HeaderGameEntry record
+func testGameHeader(gameNumber, score1, score2 int32) []byte {
+	rec := testRecord(1)
+	putInt32(rec, offHGScore1, score1)
+	putInt32(rec, offHGScore2, score2)
+	putInt32(rec, offHGGameNumber, gameNumber)
+	putInt32(rec, offHGCommentHdr, -1)
+	putInt32(rec, offHGCommentFtr, -1)
+	return rec
+}
+
+// testCubeRecord builds a CubeEntry record
+func testCubeRecord(activeP, double, take, cube int32) []byte {
+	rec := testRecord(2)
+	putInt32(rec, offCEActiveP, activeP)
+	putInt32(rec, offCEDouble, double)
+	putInt32(rec, offCETake, take)
+	putInt32(rec, offCECubeB, cube)
+	putInt32(rec, offCEDCube, cube)
+	putInt32(rec, offCERolloutIdx, -1)
+	putInt32(rec, offCECommentCube, -1)
+	return rec
+}
+
+// testMoveRecord builds a MoveEntry record with no analysis
+func testMoveRecord(activeP int32, dice [2]int32, moves [8]int32) []byte {
+	rec := testRecord(3)
+	putInt32(rec, offMEActiveP, activeP)
+	for i, m := range moves {
+		putInt32(rec, offMEMoves+4*i, m)
+	}
+	putInt32(rec, offMEDice, dice[0])
+	putInt32(rec, offMEDice+4, dice[1])
+	putInt32(rec, offMECubeA, 1)
+	putInt32(rec, offMEDDice, dice[0])
+	putInt32(rec, offMEDDice+4, dice[1])
+	putInt32(rec, offMEDCube, 1)
+	for i := 0; i < 32; i++ {
+		putInt32(rec, offMERolloutIdx+4*i, -1)
+	}
+	putInt32(rec, offMECommentMove, -1)
+	return rec
+}
+
+// putMoveCandidate fills analysis candidate i of a MoveEntry record
+func putMoveCandidate(rec []byte, i int, move [8]int8, eval [7]float32, level int16) {
+	if nMoves := int32(binary.LittleEndian.Uint32(rec[offMEDNMoves:])); int32(i) >= nMoves {
+		putInt32(rec, offMEDNMoves, int32(i+1))
+	}
+	for j, m := range move {
+		rec[offMEDMoves+8*i+j] = byte(m)
+	}
+	binary.LittleEndian.PutUint16(rec[offMEDEvalLevel+4*i:], uint16(level))
+	for j, v := range eval {
+		putFloat32(rec, offMEDEval+28*i+4*j, v)
+	}
+}
+
+// testGameFooter builds a FooterGameEntry record
+func testGameFooter(winner, pointsWon int32) []byte {
+	rec := testRecord(4)
+	putInt32(rec, offFGWinner, winner)
+	putInt32(rec, offFGPointsWon, pointsWon)
+	return rec
+}
+
+// testMatchFooter builds a FooterMatchEntry record
+func testMatchFooter(score1, score2, winner int32) []byte {
+	rec := testRecord(5)
+	putInt32(rec, offFMScore1, score1)
+	putInt32(rec, offFMScore2, score2)
+	putInt32(rec, offFMWinner, winner)
+	return rec
+}
+
+// testGameFileSegments wraps records into the segment list accepted by ParseXG
+func testGameFileSegments(records ...[]byte) []*Segment {
+	return []*Segment{{
+		Type:     SegmentXGGameFile,
+		Data:     bytes.Join(records, nil),
+		Filename: "temp.xg",
+	}}
+}
+
+func TestTestRecordLayout(t *testing.T) {
+	me := testMoveRecord(-1, [2]int32{6, 5}, [8]int32{23, 12, -1, -1, -1, -1, -1, -1})
+	putInt32(me, offMEInvalidM, 1)
+
+	records, err := ParseGameFile(bytes.Join([][]byte{
+		testMatchHeader("Alice", "Bob", 7),
+		testGameHeader(1, 2, 3),
+		me,
+		testGameFooter(-1, 2),
+	}, nil), -1)
+	if err != nil {
+		t.Fatalf("ParseGameFile() error = %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("record count = %d, want 4", len(records))
+	}
+
+	h := records[0].(*HeaderMatchEntry)
+	if h.SPlayer1 != "Alice" || h.SPlayer2 != "Bob" || h.MatchLength != 7 || h.Version != 30 {
+		t.Errorf("HeaderMatchEntry = %+v", h)
+	}
+	g := records[1].(*HeaderGameEntry)
+	if g.GameNumber != 1 || g.Score1 != 2 || g.Score2 != 3 {
+		t.Errorf("HeaderGameEntry = %+v", g)
+	}
+	m := records[2].(*MoveEntry)
+	if m.ActiveP != -1 || m.Dice != [2]int32{6, 5} || m.Moves[1] != 12 || m.InvalidM != 1 {
+		t.Errorf("MoveEntry = %+v", m)
+	}
+	f := records[3].(*FooterGameEntry)
+	if f.Winner != -1 || f.PointsWon != 2 {
+		t.Errorf("FooterGameEntry = %+v", f)
+	}
+}
+
+// testRolloutRecord builds a RolloutContextEntry record of trials truncated
+// at truncate plies, no truncation when truncate is 0
+func testRolloutRecord(trials, truncate int32) []byte {
+	rec := make([]byte, rolloutContextSize)
+	if truncate > 0 {
+		rec[offRCTruncated] = 1
+		putInt32(rec, offRCTruncate, truncate)
+	}
+	putInt32(rec, offRCMinRoll, 144)
+	putInt32(rec, offRCMaxRoll, 1296)
+	putInt32(rec, offRCLevel1, 3)
+	rec[offRCVariance] = 1
+	putInt32(rec, offRCLevel1C, 3)
+	putInt32(rec, offRCSeed, 271828)
+	putInt32(rec, offRCRolled, trials)
+	return rec
+}
+
+func TestParseRolloutFile(t *testing.T) {
+	rollouts, err := ParseRolloutFile(bytes.Join([][]byte{testRolloutRecord(1296, 0), testRolloutRecord(648, 7)}, nil))
+	if err != nil {
+		t.Fatalf("ParseRolloutFile() error = %v", err)
+	}
+	if len(rollouts) != 2 {
+		t.Fatalf("len(rollouts) = %d, want 2", len(rollouts))
+	}
+	r := rollouts[1]
+	if !r.Truncated || r.Truncate != 7 || r.Rolled != 648 || r.MinRoll != 144 || r.MaxRoll != 1296 ||
+		r.Level1 != 3 || !r.Variance || r.Level1C != 3 || r.RandomSeed != 271828 {
+		t.Errorf("RolloutContextEntry = %+v", r)
+	}
+	if rollouts[0].Truncated || rollouts[0].Rolled != 1296 {
+		t.Errorf("RolloutContextEntry = %+v, want 1296 trials without truncation", rollouts[0])
+	}
+
+	if _, err := ParseRolloutFile(make([]byte, rolloutContextSize+1)); err == nil {
+		t.Errorf("ParseRolloutFile() of a partial record returned no error")
+	}
+}
+
+func TestRecordSize(t *testing.T) {
+	for _, version := range []int32{-1, 8, 24, 26, 27, 28, 30} {
+		if got := recordSize(version); got != 2560 {
+			t.Errorf("recordSize(%d) = %d, want 2560", version, got)
+		}
+	}
+}
+
+func TestEntryTypeString(t *testing.T) {
+	tests := []struct {
+		entryType EntryType
+		want      string
+	}{
+		{EntryHeaderMatch, "HeaderMatch"},
+		{EntryHeaderGame, "HeaderGame"},
+		{EntryCube, "Cube"},
+		{EntryMove, "Move"},
+		{EntryFooterGame, "FooterGame"},
+		{EntryFooterMatch, "FooterMatch"},
+		{EntryType(9), "EntryType(9)"},
+	}
+	for _, tt := range tests {
+		if got := tt.entryType.String(); got != tt.want {
+			t.Errorf("EntryType(%d).String() = %q, want %q", int(tt.entryType), got, tt.want)
+		}
+	}
+
+	records, err := ParseGameFile(testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0)), -1)
+	if err != nil {
+		t.Fatalf("ParseGameFile() error = %v", err)
+	}
+	if got := records[1].(*HeaderGameEntry).EntryType; got != EntryHeaderGame {
+		t.Errorf("HeaderGameEntry.EntryType = %v, want %v", got, EntryHeaderGame)
+	}
+}
+
+// moveEntryLayout is the layout of a version 30 MoveEntry record, decoded
+// with a single binary.Read as a reference for MoveEntry.FromStream
+type moveEntryLayout struct {
+	_                      [9]byte
+	PositionI, PositionEnd [26]int8
+	_                      [3]byte
+	ActiveP                int32
+	Moves                  [8]int32
+	Dice                   [2]int32
+	CubeA                  int32
+	ErrorM                 float64
+	NMoveEval              int32
+	Pos                    [26]int8
+	_                      [2]byte
+	DataDice               [2]int32
+	Level                  int32
+	Score                  [2]int32
+	Cube, Cubepos          int32
+	Crawford, Jacoby       int32
+	NMoves                 int32
+	PosPlayed              [32][26]int8
+	DataMoves              [32][8]int8
+	EvalLevel              [32]struct {
+		Level    int16
+		IsDouble uint8
+		_        byte
+	}
+	Eval                             [32][7]float32
+	Unused, Met, Choice0, Choice3    int8
+	Played                           uint8
+	_                                [3]byte
+	ErrMove, ErrLuck                 float64
+	CompChoice                       int32
+	_                                [4]byte
+	InitEq                           float64
+	RolloutIndexM                    [32]int32
+	AnalyzeM, AnalyzeL, InvalidM     int32
+	PositionTutor                    [26]int8
+	Tutor                            int8
+	_                                byte
+	ErrTutorMove                     float64
+	Flagged                          uint8
+	_                                [3]byte
+	CommentMove                      int32
+	EditedMove                       uint8
+	_                                [3]byte
+	TimeDelayMove, TimeDelayMoveDone uint32
+	NumberOfAutoDoubleMove           int32
+}
+
+func TestMoveEntryFromStream_Layout(t *testing.T) {
+	rec := make([]byte, testRecSize)
+	rand.New(rand.NewSource(1)).Read(rec)
+	rec[8] = byte(EntryMove)
+
+	var want moveEntryLayout
+	if err := binary.Read(bytes.NewReader(rec), binary.LittleEndian, &want); err != nil {
+		t.Fatal(err)
+	}
+	var m MoveEntry
+	if err := m.FromStream(bytes.NewReader(rec), 30); err != nil {
+		t.Fatalf("FromStream() error = %v", err)
+	}
+	d := m.DataMoves
+
+	// Floats are compared as printed: random bytes give NaNs
+	got := fmt.Sprint(m.PositionI, m.PositionEnd, m.ActiveP, m.Moves, m.Dice, m.CubeA, m.ErrorM, m.NMoveEval,
+		d.Pos, d.Dice, d.Level, d.Score, d.Cube, d.Cubepos, d.Crawford, d.Jacoby, d.NMoves, d.PosPlayed, d.Moves, d.Eval,
+		d.Unused, d.Met, d.Choice0, d.Choice3, m.Played, m.ErrMove, m.ErrLuck, m.CompChoice, m.InitEq, m.RolloutIndexM,
+		m.AnalyzeM, m.AnalyzeL, m.InvalidM, m.PositionTutor, m.Tutor, m.ErrTutorMove, m.Flagged, m.CommentMove,
+		m.EditedMove, m.TimeDelayMove, m.TimeDelayMoveDone, m.NumberOfAutoDoubleMove)
+	w := want
+	wantStr := fmt.Sprint(w.PositionI, w.PositionEnd, w.ActiveP, w.Moves, w.Dice, w.CubeA, w.ErrorM, w.NMoveEval,
+		w.Pos, w.DataDice, w.Level, w.Score, w.Cube, w.Cubepos, w.Crawford, w.Jacoby, w.NMoves, w.PosPlayed, w.DataMoves, w.Eval,
+		w.Unused, w.Met, w.Choice0, w.Choice3, w.Played != 0, w.ErrMove, w.ErrLuck, w.CompChoice, w.InitEq, w.RolloutIndexM,
+		w.AnalyzeM, w.AnalyzeL, w.InvalidM, w.PositionTutor, w.Tutor, w.ErrTutorMove, w.Flagged != 0, w.CommentMove,
+		w.EditedMove != 0, w.TimeDelayMove, w.TimeDelayMoveDone, w.NumberOfAutoDoubleMove)
+	if got != wantStr {
+		t.Errorf("FromStream() =\n%s\nwant\n%s", got, wantStr)
+	}
+	for i, l := range want.EvalLevel {
+		if d.EvalLevel[i].Level != l.Level || d.EvalLevel[i].IsDouble != (l.IsDouble != 0) {
+			t.Errorf("EvalLevel[%d] = %+v, want %+v", i, d.EvalLevel[i], l)
+		}
+	}
+}
+
+func TestMoveEntryFromStreamAnalysed(t *testing.T) {
+	rec := make([]byte, testRecSize)
+	rand.New(rand.NewSource(1)).Read(rec)
+	rec[8] = byte(EntryMove)
+	putInt32(rec, offMEDNMoves, 5)
+
+	var all, analysed MoveEntry
+	if err := all.FromStream(bytes.NewReader(rec), 30); err != nil {
+		t.Fatalf("FromStream() error = %v", err)
+	}
+	if err := analysed.FromStreamAnalysed(bytes.NewReader(rec), 30); err != nil {
+		t.Fatalf("FromStreamAnalysed() error = %v", err)
+	}
+
+	// The analysed candidates are the same, the other rows are left at zero
+	var zero EngineStructBestMoveRecord
+	a, d := all.DataMoves, analysed.DataMoves
+	want, got := *a, *d
+	for i := 5; i < 32; i++ {
+		want.PosPlayed[i], want.Moves[i], want.EvalLevel[i], want.Eval[i] = zero.PosPlayed[i], zero.Moves[i], zero.EvalLevel[i], zero.Eval[i]
+	}
+	// Floats are compared as printed: random bytes give NaNs
+	if fmt.Sprintf("%+v", got) != fmt.Sprintf("%+v", want) {
+		t.Errorf("FromStreamAnalysed() DataMoves =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// The fields after DataMoves are read as by FromStream
+	all.DataMoves, analysed.DataMoves = nil, nil
+	if fmt.Sprintf("%+v", analysed) != fmt.Sprintf("%+v", all) {
+		t.Errorf("FromStreamAnalysed() =\n%+v\nwant\n%+v", analysed, all)
+	}
+}
+
+// benchmarkGameFile builds the game file of a long match: 20 games of 50
+// moves with the given number of analysed candidates
+func benchmarkGameFile(candidates int) []byte {
+	records := [][]byte{testMatchHeader("Alice", "Bob", 25)}
+	for g := int32(1); g <= 20; g++ {
+		records = append(records, testGameHeader(g, 0, 0))
+		for i := 0; i < 50; i++ {
+			rec := testMoveRecord(1, [2]int32{3, 1}, [8]int32{7, 4, 5, 4, -1, -1, -1, -1})
+			for c := 0; c < candidates; c++ {
+				putMoveCandidate(rec, c, [8]int8{7, 4, 5, 4, -1, -1, -1, -1}, [7]float32{0, 0.1, 0.4, 0, 0.2, 0, 0.1}, 2)
+			}
+			records = append(records, rec)
+		}
+		records = append(records, testGameFooter(1, 1))
+	}
+	return bytes.Join(records, nil)
+}
+
+func BenchmarkParseGameFile(b *testing.B) {
+	data := benchmarkGameFile(5)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseGameFile(data, -1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseGameFileAnalysed(b *testing.B) {
+	data := benchmarkGameFile(5)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseGameFileAnalysed(data, -1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -45,6 +45,8 @@ type XGTextPosition struct {
 	Comment      string          // User comment extracted from text
 	Version      string          // XG version
 	MET          string          // Match equity table
+	CubeValue    int             // Value shown in the cube box next to the board, 0 without box
+	CubeOwner    int             // Side of the cube box: 1=X (bottom), -1=O (top), 0=centered
 }
 
 // XGMove represents a move analysis
@@ -97,6 +99,7 @@ func ParseXGTextPosition(r io.Reader) (*XGTextPosition, error) {
 	}
 
 	inBoard := false
+	boardRow := 0 // Row of the board diagram, 6 is the BAR row
 	lineNum := 0
 	var allLines []string    // collect all lines for comment extraction
 	var lastMoveIdx int = -1 // track last parsed move for attaching player/opponent stats
@@ -104,11 +107,15 @@ func ParseXGTextPosition(r io.Reader) (*XGTextPosition, error) {
 	for scanner.Scan() {
 		line := scanner.Text()
 		lineNum++
+		if lineNum == 1 {
+			// Files saved on Windows may start with a UTF-8 byte order mark
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
 		allLines = append(allLines, line)
 
-		// Parse XGID (first line)
-		if strings.HasPrefix(line, "XGID=") {
-			pos.XGID = strings.TrimSpace(line)
+		// Parse XGID (first line), possibly indented
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "XGID=") {
+			pos.XGID = trimmed
 			continue
 		}
 
@@ -137,12 +144,15 @@ func ParseXGTextPosition(r io.Reader) (*XGTextPosition, error) {
 		// Skip board display lines (position is in XGID)
 		if strings.Contains(line, "+13-14-15-16-17-18") {
 			inBoard = true
+			boardRow = 0
 			continue
 		}
 		if inBoard {
 			if strings.Contains(line, "+12-11-10--9--8--7") {
 				inBoard = false
 			}
+			boardRow++
+			parseCubeBox(line, boardRow, pos)
 			continue
 		}
 
@@ -237,15 +247,15 @@ func parseMovePlayerStats(line string, move *XGMove) bool {
 		return false
 	}
 
-	re := regexp.MustCompile(`(\d+\.\d+)%\s+\(G:(\d+\.\d+)%\s+B:(\d+\.\d+)%\)`)
+	re := regexp.MustCompile(`(\d+[.,]\d+)%\s+\(G:(\d+[.,]\d+)%\s+B:(\d+[.,]\d+)%\)`)
 	matches := re.FindStringSubmatch(line)
 	if matches == nil {
 		return false
 	}
 
-	win, _ := strconv.ParseFloat(matches[1], 64)
-	g, _ := strconv.ParseFloat(matches[2], 64)
-	b, _ := strconv.ParseFloat(matches[3], 64)
+	win := parseDecimal(matches[1])
+	g := parseDecimal(matches[2])
+	b := parseDecimal(matches[3])
 
 	if isPlayer {
 		move.PlayerWin = win
@@ -259,6 +269,27 @@ func parseMovePlayerStats(line string, move *XGMove) bool {
 	return true
 }
 
+// parseCubeBox reads the cube box drawn right of the board, "| 2 |" between
+// two "+---+" lines. The box sits on the owner's side: top rows for O,
+// bottom rows for X and the BAR row (row 6) when the cube is centered.
+func parseCubeBox(line string, row int, pos *XGTextPosition) {
+	re := regexp.MustCompile(`\|\s+\|\s*(\d+)\s*\|\s*$`)
+	matches := re.FindStringSubmatch(line)
+	if matches == nil {
+		return
+	}
+
+	pos.CubeValue, _ = strconv.Atoi(matches[1])
+	switch {
+	case row < 6:
+		pos.CubeOwner = -1
+	case row > 6:
+		pos.CubeOwner = 1
+	default:
+		pos.CubeOwner = 0
+	}
+}
+
 // parseMoveAnalysis parses a move analysis line
 func parseMoveAnalysis(line string) (XGMove, bool) {
 	// "    1. 4-ply       19/18 14/12                  eq:-0.491"
@@ -276,7 +307,7 @@ func parseMoveAnalysis(line string) (XGMove, bool) {
 	// Japanese: "      プレーヤー: 25.45% (G:0.00% B:0.00%)"
 	// "      対戦相手:  74.55% (G:31.09% B:0.09%)"
 
-	movePattern := `^\s+(\d+)\.\s+(\d+)-pl(?:y|is)\s+(.+?)\s+(?:eq|éq):([+-]?\d+\.\d+)(?:\s+\(([+-]?\d+\.\d+)\))?`
+	movePattern := `^\s+(\d+)\.\s+(\d+)-pl(?:y|is)\s+(.+?)\s+(?:eq|éq|экв):([+-]?\d+[.,]\d+)(?:\s+\(([+-]?\d+[.,]\d+)\))?`
 	re := regexp.MustCompile(movePattern)
 
 	if matches := re.FindStringSubmatch(line); matches != nil {
@@ -284,9 +315,9 @@ func parseMoveAnalysis(line string) (XGMove, bool) {
 		move.Rank, _ = strconv.Atoi(matches[1])
 		move.Ply, _ = strconv.Atoi(matches[2])
 		move.Move = strings.TrimSpace(matches[3])
-		move.Equity, _ = strconv.ParseFloat(matches[4], 64)
+		move.Equity = parseDecimal(matches[4])
 		if len(matches) > 5 && matches[5] != "" {
-			move.EquityDiff, _ = strconv.ParseFloat(matches[5], 64)
+			move.EquityDiff = parseDecimal(matches[5])
 		}
 		return move, true
 	}
@@ -415,11 +446,11 @@ func parseCubeAnalysis(line string, pos *XGTextPosition, scanner *bufio.Scanner,
 // parseWinningChances parses winning chances line
 func parseWinningChances(line string, cube *XGCubeAnalysis, isPlayer bool) {
 	// "Player Winning Chances:   54.40% (G:18.22% B:0.53%)"
-	re := regexp.MustCompile(`(\d+\.\d+)%\s+\(G:(\d+\.\d+)%\s+B:(\d+\.\d+)%\)`)
+	re := regexp.MustCompile(`(\d+[.,]\d+)%\s+\(G:(\d+[.,]\d+)%\s+B:(\d+[.,]\d+)%\)`)
 	if matches := re.FindStringSubmatch(line); matches != nil {
-		win, _ := strconv.ParseFloat(matches[1], 64)
-		g, _ := strconv.ParseFloat(matches[2], 64)
-		b, _ := strconv.ParseFloat(matches[3], 64)
+		win := parseDecimal(matches[1])
+		g := parseDecimal(matches[2])
+		b := parseDecimal(matches[3])
 
 		if isPlayer {
 			cube.PlayerWin = win
@@ -440,16 +471,16 @@ func parseCubelessEquities(line string, cube *XGCubeAnalysis) {
 	// German: "Equities ohne Dopplerwürfel: Nicht Doppeln=+0.103, Doppeln=+0.259"
 
 	patterns := []string{
-		`No Double=([+-]?\d+\.\d+),\s*Double=([+-]?\d+\.\d+)`,
-		`Pas de double=([+-]?\d+\.\d+),\s*Double=([+-]?\d+\.\d+)`,
-		`Nicht Doppeln=([+-]?\d+\.\d+),\s*Doppeln=([+-]?\d+\.\d+)`,
+		`No Double=([+-]?\d+[.,]\d+),\s*Double=([+-]?\d+[.,]\d+)`,
+		`Pas de double=([+-]?\d+[.,]\d+),\s*Double=([+-]?\d+[.,]\d+)`,
+		`Nicht Doppeln=([+-]?\d+[.,]\d+),\s*Doppeln=([+-]?\d+[.,]\d+)`,
 	}
 
 	for _, pattern := range patterns {
 		re := regexp.MustCompile(pattern)
 		if matches := re.FindStringSubmatch(line); matches != nil {
-			cube.CubelessNoDouble, _ = strconv.ParseFloat(matches[1], 64)
-			cube.CubelessDouble, _ = strconv.ParseFloat(matches[2], 64)
+			cube.CubelessNoDouble = parseDecimal(matches[1])
+			cube.CubelessDouble = parseDecimal(matches[2])
 			return
 		}
 	}
@@ -459,12 +490,12 @@ func parseCubelessEquities(line string, cube *XGCubeAnalysis) {
 func parseCubefulEquity(line string, cube *XGCubeAnalysis, equityType string) {
 	// "       No double:     +0.337"
 	// "       Double/Take:   +0.215 (-0.122)"
-	re := regexp.MustCompile(`([+-]?\d+\.\d+)(?:\s+\(([+-]?\d+\.\d+)\))?`)
+	re := regexp.MustCompile(`([+-]?\d+[.,]\d+)(?:\s+\(([+-]?\d+[.,]\d+)\))?`)
 	if matches := re.FindStringSubmatch(line); matches != nil {
-		equity, _ := strconv.ParseFloat(matches[1], 64)
+		equity := parseDecimal(matches[1])
 		var equityErr float64
 		if len(matches) > 2 && matches[2] != "" {
-			equityErr, _ = strconv.ParseFloat(matches[2], 64)
+			equityErr = parseDecimal(matches[2])
 		}
 
 		switch equityType {
@@ -556,9 +587,9 @@ func extractAnalyzerType(line string) string {
 
 // parseWrongPercentage parses wrong pass/take percentage lines
 func parseWrongPercentage(line string, cube *XGCubeAnalysis, pctType string) {
-	re := regexp.MustCompile(`(\d+\.\d+)%`)
+	re := regexp.MustCompile(`(\d+[.,]\d+)%`)
 	if matches := re.FindStringSubmatch(line); matches != nil {
-		pct, _ := strconv.ParseFloat(matches[1], 64)
+		pct := parseDecimal(matches[1])
 		switch pctType {
 		case "pass":
 			cube.WrongPassPct = pct
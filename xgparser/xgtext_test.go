@@ -6,6 +6,7 @@
 package xgparser
 
 import (
+"os"
 "strings"
 "testing"
 )
@@ -569,3 +570,88 @@ if pos.Comment != "My checker comment here." {
 t.Errorf("Comment: got %q, want %q", pos.Comment, "My checker comment here.")
 }
 }
+
+func TestParseXGTextPosition_DecimalComma(t *testing.T) {
+input := strings.Replace(testPositionEN, "eq:-0.556 (-0.065)", "eq:-0,556 (-0,065)", 1)
+pos, err := ParseXGTextPosition(strings.NewReader(input))
+if err != nil {
+t.Fatalf("Failed to parse: %v", err)
+}
+
+if len(pos.Analysis) < 2 {
+t.Fatalf("Expected at least 2 moves, got %d", len(pos.Analysis))
+}
+if pos.Analysis[1].Equity != -0.556 || pos.Analysis[1].EquityDiff != -0.065 {
+t.Errorf("Move 2 equity: got %.3f (%.3f), want -0.556 (-0.065)", pos.Analysis[1].Equity, pos.Analysis[1].EquityDiff)
+}
+}
+
+func TestParseXGTextPosition_CubeDecimalComma(t *testing.T) {
+file, err := os.Open("../test/decimal_comma/03_DT_EN.txt")
+if err != nil {
+t.Fatal(err)
+}
+defer file.Close()
+pos, err := ParseXGTextPosition(file)
+if err != nil {
+t.Fatalf("Failed to parse: %v", err)
+}
+
+ca := pos.CubeAnalysis
+if ca == nil {
+t.Fatal("Expected cube analysis")
+}
+if ca.PlayerWin != 61.38 || ca.PlayerG != 24.55 || ca.OppWin != 38.62 || ca.OppB != 0.46 {
+t.Errorf("Winning chances: got %.2f (G:%.2f) %.2f (B:%.2f)", ca.PlayerWin, ca.PlayerG, ca.OppWin, ca.OppB)
+}
+if ca.CubelessNoDouble != 0.365 || ca.CubelessDouble != 0.791 {
+t.Errorf("Cubeless: got %.3f, %.3f, want 0.365, 0.791", ca.CubelessNoDouble, ca.CubelessDouble)
+}
+if ca.NoDouble != 0.580 || ca.DoubleTake != 0.689 {
+t.Errorf("Cubeful: got %.3f, %.3f, want 0.580, 0.689", ca.NoDouble, ca.DoubleTake)
+}
+}
+
+func TestParseXGTextPosition_CubeBox(t *testing.T) {
+pos, err := ParseXGTextPosition(strings.NewReader(testPositionEN))
+if err != nil {
+t.Fatalf("Failed to parse: %v", err)
+}
+if pos.CubeValue != 2 || pos.CubeOwner != -1 {
+t.Errorf("Cube box: got value %d owner %d, want 2 owned by O (-1)", pos.CubeValue, pos.CubeOwner)
+}
+
+data, err := os.ReadFile("../test/2025-11-04/05_NRT_EN.txt")
+if err != nil {
+t.Fatal(err)
+}
+pos, err = ParseXGTextPosition(strings.NewReader(string(data)))
+if err != nil {
+t.Fatalf("Failed to parse cube fixture: %v", err)
+}
+if pos.CubeValue != 2 || pos.CubeOwner != 1 {
+t.Errorf("Cube box: got value %d owner %d, want 2 owned by X (1)", pos.CubeValue, pos.CubeOwner)
+}
+}
+
+func TestParseXGTextPosition_BOM(t *testing.T) {
+data, err := os.ReadFile("../test/2025-11-04/01_checkerPosition_EN.txt")
+if err != nil {
+t.Fatal(err)
+}
+
+// Saved on Windows: UTF-8 byte order mark, indented XGID line and CRLF
+for _, prefix := range []string{"\ufeff", "\ufeff  ", "\t"} {
+input := prefix + strings.ReplaceAll(string(data), "\n", "\r\n")
+pos, err := ParseXGTextPosition(strings.NewReader(input))
+if err != nil {
+t.Fatalf("prefix %q: Failed to parse: %v", prefix, err)
+}
+if pos.XGID != "XGID=-B-CBBB---a---A---ABcbbbd-:1:-1:1:21:3:6:0:7:10" {
+t.Errorf("prefix %q: Wrong XGID: %q", prefix, pos.XGID)
+}
+if len(pos.Analysis) == 0 {
+t.Errorf("prefix %q: no analysis parsed", prefix)
+}
+}
+}
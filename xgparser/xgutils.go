@@ -28,7 +28,9 @@ import (
 	"encoding/binary"
 	"hash/crc32"
 	"io"
+	"strings"
 	"time"
+	"unicode"
 	"unicode/utf16"
 )
 
@@ -113,3 +115,15 @@ func ReadUTF16Array(r io.Reader, count int) ([]uint16, error) {
 	err := binary.Read(r, binary.LittleEndian, &result)
 	return result, err
 }
+
+// SanitizeName removes null bytes and other non-printing runes left over from
+// fixed-size string fields, and trims surrounding spaces
+func SanitizeName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if !unicode.IsPrint(r) {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.TrimSpace(s)
+}
@@ -28,6 +28,7 @@ import (
 	"bytes"
 	"compress/zlib"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"hash/crc32"
 	"io"
@@ -35,6 +36,16 @@ import (
 
 const maxBufSize = 32768
 
+// ErrTooLarge is returned when an archive, or a stream buffered to read it,
+// is larger than allowed
+var ErrTooLarge = errors.New("archive exceeds the size limit")
+
+// archiveRecordSize is the size of the ArchiveRecord stored at the end of the archive
+const archiveRecordSize = 36
+
+// fileRecordSize is the size of a file record in the archive registry
+const fileRecordSize = 532
+
 // ArchiveRecord represents the archive metadata
 type ArchiveRecord struct {
 	CRC                uint32
@@ -54,8 +65,15 @@ type FileRecord struct {
 	CSize            int32
 	Start            int32
 	CRC              uint32
-	Compressed       byte
-	CompressionLevel byte
+	Compressed       byte // 0 for zlib compressed data, non-zero for stored data
+	CompressionLevel byte // zlib level the file was compressed with, any level is read
+}
+
+// isCompressed reports whether the file data is zlib compressed. XG writes 0 in
+// Compressed for compressed files and 1 for stored ones, the opposite of the
+// CompressedRegistry flag of the ArchiveRecord.
+func (f *FileRecord) isCompressed() bool {
+	return f.Compressed == 0
 }
 
 // ZlibArchive represents a zlib compressed archive
@@ -65,14 +83,27 @@ type ZlibArchive struct {
 	StartOfArcData int64
 	EndOfArcData   int64
 	stream         io.ReadSeeker
+	maxBytes       int64 // Limit on the total extracted bytes, 0 for none
+	extracted      int64 // Bytes extracted so far
+	skipArchiveCRC bool  // Do not read the whole archive to check its CRC
 }
 
 // NewZlibArchive creates a new ZlibArchive from a stream
 func NewZlibArchive(stream io.ReadSeeker) (*ZlibArchive, error) {
-	za := &ZlibArchive{
-		stream: stream,
-	}
+	return newLimitedZlibArchive(stream, 0)
+}
+
+// newLimitedZlibArchive creates a ZlibArchive that fails with ErrTooLarge once
+// the index and extracted files exceed maxBytes in total (0 for no limit)
+func newLimitedZlibArchive(stream io.ReadSeeker, maxBytes int64) (*ZlibArchive, error) {
+	return openZlibArchive(&ZlibArchive{
+		stream:   stream,
+		maxBytes: maxBytes,
+	})
+}
 
+// openZlibArchive reads the archive index of za, whose stream and options are set
+func openZlibArchive(za *ZlibArchive) (*ZlibArchive, error) {
 	err := za.getArchiveIndex()
 	if err != nil {
 		return nil, err
@@ -90,20 +121,25 @@ func (za *ZlibArchive) getArchiveIndex() error {
 	defer za.stream.Seek(currentPos, io.SeekStart)
 
 	// Read archive record at the end
-	_, err = za.stream.Seek(-36, io.SeekEnd) // ArchiveRecord size = 36
+	za.EndOfArcData, err = za.stream.Seek(-archiveRecordSize, io.SeekEnd)
 	if err != nil {
 		return err
 	}
 
-	za.EndOfArcData, _ = za.stream.Seek(0, io.SeekCurrent)
-
 	err = binary.Read(za.stream, binary.LittleEndian, &za.ArcRec)
 	if err != nil {
 		return err
 	}
 
+	// Sizes come from the file, make sure they fit before seeking or allocating
+	if za.ArcRec.RegistrySize < 0 || za.ArcRec.ArchiveSize < 0 ||
+		int64(za.ArcRec.RegistrySize)+int64(za.ArcRec.ArchiveSize) > za.EndOfArcData {
+		return fmt.Errorf("invalid archive record: registry size %d, archive size %d",
+			za.ArcRec.RegistrySize, za.ArcRec.ArchiveSize)
+	}
+
 	// Position at beginning of archive file index
-	_, err = za.stream.Seek(-36-int64(za.ArcRec.RegistrySize), io.SeekEnd)
+	_, err = za.stream.Seek(-archiveRecordSize-int64(za.ArcRec.RegistrySize), io.SeekEnd)
 	if err != nil {
 		return err
 	}
@@ -112,18 +148,24 @@ func (za *ZlibArchive) getArchiveIndex() error {
 	za.StartOfArcData -= int64(za.ArcRec.ArchiveSize)
 
 	// Verify CRC
-	crc, err := StreamCRC32(za.stream, za.EndOfArcData-za.StartOfArcData, za.StartOfArcData)
-	if err != nil {
-		return err
-	}
-	if crc != za.ArcRec.CRC {
-		return fmt.Errorf("archive CRC check failed - file corrupt")
+	if !za.skipArchiveCRC {
+		crc, err := StreamCRC32(za.stream, za.EndOfArcData-za.StartOfArcData, za.StartOfArcData)
+		if err != nil {
+			return err
+		}
+		if crc != za.ArcRec.CRC {
+			return fmt.Errorf("archive CRC check failed - file corrupt")
+		}
 	}
 
 	// Decompress index
-	indexData, err := za.extractSegment(za.ArcRec.CompressedRegistry != 0, 0)
+	indexData, err := za.extractSegment(za.ArcRec.CompressedRegistry != 0, za.ArcRec.RegistrySize)
 	if err != nil {
-		return fmt.Errorf("error extracting archive index: %v", err)
+		return fmt.Errorf("error extracting archive index: %w", err)
+	}
+
+	if za.ArcRec.FileCount < 0 || int64(za.ArcRec.FileCount)*fileRecordSize > int64(len(indexData)) {
+		return fmt.Errorf("invalid archive record: %d files in a %d byte index", za.ArcRec.FileCount, len(indexData))
 	}
 
 	// Read file records from index
@@ -189,11 +231,20 @@ func (za *ZlibArchive) extractSegment(isCompressed bool, numBytes int32) ([]byte
 		}
 		defer r.Close()
 
+		var src io.Reader = r
+		if za.maxBytes > 0 {
+			// Read one byte past the limit to detect oversized data
+			src = io.LimitReader(r, za.maxBytes-za.extracted+1)
+		}
+
 		var buf bytes.Buffer
-		_, err = io.Copy(&buf, r)
+		n, err := io.Copy(&buf, src)
 		if err != nil {
 			return nil, err
 		}
+		if err := za.addExtracted(n); err != nil {
+			return nil, err
+		}
 
 		return buf.Bytes(), nil
 	} else {
@@ -201,6 +252,9 @@ func (za *ZlibArchive) extractSegment(isCompressed bool, numBytes int32) ([]byte
 		if numBytes == 0 {
 			return nil, fmt.Errorf("numBytes must be specified for uncompressed segments")
 		}
+		if err := za.addExtracted(int64(numBytes)); err != nil {
+			return nil, err
+		}
 
 		data := make([]byte, numBytes)
 		_, err := io.ReadFull(za.stream, data)
@@ -212,16 +266,38 @@ func (za *ZlibArchive) extractSegment(isCompressed bool, numBytes int32) ([]byte
 	}
 }
 
+// addExtracted counts n more extracted bytes against the size limit
+func (za *ZlibArchive) addExtracted(n int64) error {
+	za.extracted += n
+	if za.maxBytes > 0 && za.extracted > za.maxBytes {
+		return fmt.Errorf("%w: more than %d bytes", ErrTooLarge, za.maxBytes)
+	}
+	return nil
+}
+
+// checkFileRecord verifies that a file record lies within the archive data
+func (za *ZlibArchive) checkFileRecord(filerec *FileRecord) error {
+	if filerec.Start < 0 || filerec.CSize < 0 ||
+		int64(filerec.Start)+int64(filerec.CSize) > int64(za.ArcRec.ArchiveSize) {
+		return fmt.Errorf("invalid file record %q: start %d, size %d", filerec.Name, filerec.Start, filerec.CSize)
+	}
+	return nil
+}
+
 // GetArchiveFile extracts a file from the archive
 func (za *ZlibArchive) GetArchiveFile(filerec *FileRecord) ([]byte, error) {
+	if err := za.checkFileRecord(filerec); err != nil {
+		return nil, err
+	}
+
 	_, err := za.stream.Seek(int64(filerec.Start)+za.StartOfArcData, io.SeekStart)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := za.extractSegment(filerec.Compressed == 0, filerec.CSize)
+	data, err := za.extractSegment(filerec.isCompressed(), filerec.CSize)
 	if err != nil {
-		return nil, fmt.Errorf("error extracting archived file: %v", err)
+		return nil, fmt.Errorf("error extracting archived file: %w", err)
 	}
 
 	// Verify CRC
@@ -232,3 +308,78 @@ func (za *ZlibArchive) GetArchiveFile(filerec *FileRecord) ([]byte, error) {
 
 	return data, nil
 }
+
+// getArchiveFilePrefix extracts at most the first n bytes of a file from the archive.
+// Only the needed part of the segment is decompressed, so no CRC check is done.
+func (za *ZlibArchive) getArchiveFilePrefix(filerec *FileRecord, n int64) ([]byte, error) {
+	if err := za.checkFileRecord(filerec); err != nil {
+		return nil, err
+	}
+
+	_, err := za.stream.Seek(int64(filerec.Start)+za.StartOfArcData, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = io.LimitReader(za.stream, int64(filerec.CSize))
+	if filerec.isCompressed() {
+		zr, err := zlib.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("error extracting archived file: %v", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, n))
+	if err != nil {
+		return nil, fmt.Errorf("error extracting archived file: %v", err)
+	}
+
+	return data, nil
+}
+
+// FileStats holds the size information of an archived file
+type FileStats struct {
+	Name           string
+	OriginalSize   int64
+	CompressedSize int64
+	Ratio          float64 // CompressedSize / OriginalSize
+}
+
+// ArchiveStats holds the size information of the whole archive
+type ArchiveStats struct {
+	Files          []FileStats
+	OriginalSize   int64
+	CompressedSize int64
+	Ratio          float64 // CompressedSize / OriginalSize
+}
+
+// compressionRatio returns compressed/original, or 0 for an empty file
+func compressionRatio(original, compressed int64) float64 {
+	if original == 0 {
+		return 0
+	}
+	return float64(compressed) / float64(original)
+}
+
+// Stats reports original and compressed sizes per file and for the whole archive
+func (za *ZlibArchive) Stats() ArchiveStats {
+	stats := ArchiveStats{
+		Files: make([]FileStats, 0, len(za.ArcRegistry)),
+	}
+
+	for _, rec := range za.ArcRegistry {
+		stats.Files = append(stats.Files, FileStats{
+			Name:           rec.Name,
+			OriginalSize:   int64(rec.OSize),
+			CompressedSize: int64(rec.CSize),
+			Ratio:          compressionRatio(int64(rec.OSize), int64(rec.CSize)),
+		})
+		stats.OriginalSize += int64(rec.OSize)
+		stats.CompressedSize += int64(rec.CSize)
+	}
+	stats.Ratio = compressionRatio(stats.OriginalSize, stats.CompressedSize)
+
+	return stats
+}
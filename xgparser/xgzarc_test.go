@@ -0,0 +1,234 @@
+//
+//   xgzarc_test.go - Unit tests for the XG zlib archive
+//   Copyright (C) 2025 Kevin Unger
+//
+
+package xgparser
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"strings"
+	"testing"
+)
+
+// testArchiveFile describes a file stored in a synthetic archive
+type testArchiveFile struct {
+	name   string
+	data   []byte
+	stored bool // keep the data uncompressed
+	level  int  // zlib compression level, 0 for the default level
+}
+
+func zlibCompress(data []byte) []byte {
+	return zlibCompressLevel(data, zlib.DefaultCompression)
+}
+
+func zlibCompressLevel(data []byte, level int) []byte {
+	var buf bytes.Buffer
+	w, _ := zlib.NewWriterLevel(&buf, level)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+// buildTestArchive builds a zlib archive in the layout read by NewZlibArchive:
+// file data, compressed registry, then the 36-byte ArchiveRecord trailer.
+func buildTestArchive(files ...testArchiveFile) []byte {
+	var body, registry bytes.Buffer
+
+	for _, f := range files {
+		start := body.Len()
+		level := f.level
+		if level == 0 {
+			level = 6
+		}
+		payload := zlibCompressLevel(f.data, level)
+		compressed := byte(0) // 0 marks a deflated file, as read by GetArchiveFile
+		if f.stored {
+			payload = f.data
+			compressed = 1
+		}
+		body.Write(payload)
+
+		var name, path [256]byte
+		name[0] = byte(len(f.name))
+		copy(name[1:], f.name)
+		registry.Write(name[:])
+		registry.Write(path[:])
+		binary.Write(&registry, binary.LittleEndian, int32(len(f.data)))
+		binary.Write(&registry, binary.LittleEndian, int32(len(payload)))
+		binary.Write(&registry, binary.LittleEndian, int32(start))
+		binary.Write(&registry, binary.LittleEndian, crc32.ChecksumIEEE(f.data))
+		registry.Write([]byte{compressed, byte(level), 0, 0})
+	}
+
+	archiveSize := body.Len()
+	body.Write(zlibCompress(registry.Bytes()))
+
+	rec := ArchiveRecord{
+		CRC:                crc32.ChecksumIEEE(body.Bytes()),
+		FileCount:          int32(len(files)),
+		Version:            1,
+		RegistrySize:       int32(body.Len() - archiveSize),
+		ArchiveSize:        int32(archiveSize),
+		CompressedRegistry: 1,
+	}
+	binary.Write(&body, binary.LittleEndian, &rec)
+
+	return body.Bytes()
+}
+
+func TestZlibArchive(t *testing.T) {
+	data := buildTestArchive(
+		testArchiveFile{name: "temp.xg", data: bytes.Repeat([]byte("game"), 1000)},
+		testArchiveFile{name: "temp.xgc", data: []byte("comments"), stored: true},
+	)
+
+	za, err := NewZlibArchive(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewZlibArchive() error = %v", err)
+	}
+	if len(za.ArcRegistry) != 2 {
+		t.Fatalf("registry size = %d, want 2", len(za.ArcRegistry))
+	}
+	for i, want := range []string{strings.Repeat("game", 1000), "comments"} {
+		got, err := za.GetArchiveFile(&za.ArcRegistry[i])
+		if err != nil {
+			t.Fatalf("GetArchiveFile(%s) error = %v", za.ArcRegistry[i].Name, err)
+		}
+		if string(got) != want {
+			t.Errorf("GetArchiveFile(%s) = %d bytes, want %d", za.ArcRegistry[i].Name, len(got), len(want))
+		}
+	}
+}
+
+func TestZlibArchive_CompressionLevels(t *testing.T) {
+	game := bytes.Repeat([]byte("game"), 1000)
+	files := []testArchiveFile{
+		{name: "temp.xg", data: game, level: zlib.BestSpeed},
+		{name: "temp.xgr", data: game, level: zlib.BestCompression},
+		{name: "temp.xgi", data: game, level: zlib.HuffmanOnly},
+		{name: "temp.xgc", data: game, stored: true},
+	}
+
+	za, err := NewZlibArchive(bytes.NewReader(buildTestArchive(files...)))
+	if err != nil {
+		t.Fatalf("NewZlibArchive() error = %v", err)
+	}
+	for i, f := range files {
+		rec := &za.ArcRegistry[i]
+		if rec.isCompressed() == f.stored {
+			t.Errorf("%s: isCompressed() = %v, want %v", rec.Name, rec.isCompressed(), !f.stored)
+		}
+		got, err := za.GetArchiveFile(rec)
+		if err != nil {
+			t.Fatalf("GetArchiveFile(%s) error = %v", rec.Name, err)
+		}
+		if !bytes.Equal(got, game) {
+			t.Errorf("GetArchiveFile(%s) = %d bytes, want %d", rec.Name, len(got), len(game))
+		}
+		prefix, err := za.getArchiveFilePrefix(rec, 8)
+		if err != nil || string(prefix) != "gamegame" {
+			t.Errorf("getArchiveFilePrefix(%s) = %q, %v, want \"gamegame\"", rec.Name, prefix, err)
+		}
+	}
+}
+
+func TestZlibArchive_InvalidSizes(t *testing.T) {
+	valid := buildTestArchive(testArchiveFile{name: "temp.xg", data: []byte("game")})
+	recOffset := len(valid) - archiveRecordSize
+
+	tests := []struct {
+		name  string
+		off   int // offset inside the ArchiveRecord
+		value int32
+	}{
+		{"huge registry size", 12, 1 << 30},
+		{"negative registry size", 12, -100},
+		{"huge archive size", 16, 1 << 30},
+		{"huge file count", 4, 1 << 30},
+		{"negative file count", 4, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append([]byte(nil), valid...)
+			binary.LittleEndian.PutUint32(data[recOffset+tt.off:], uint32(tt.value))
+			if _, err := NewZlibArchive(bytes.NewReader(data)); err == nil {
+				t.Error("NewZlibArchive() succeeded, want error")
+			}
+		})
+	}
+
+	za, err := NewZlibArchive(bytes.NewReader(valid))
+	if err != nil {
+		t.Fatalf("NewZlibArchive() error = %v", err)
+	}
+	rec := za.ArcRegistry[0]
+	rec.CSize = 1 << 30
+	if _, err := za.GetArchiveFile(&rec); err == nil {
+		t.Error("GetArchiveFile() with huge CSize succeeded, want error")
+	}
+}
+
+func TestZlibArchiveStats(t *testing.T) {
+	data := buildTestArchive(
+		testArchiveFile{name: "temp.xg", data: bytes.Repeat([]byte("game"), 1000)},
+		testArchiveFile{name: "temp.xgc", data: []byte("comments"), stored: true},
+		testArchiveFile{name: "temp.xgr", data: nil},
+	)
+	za, err := NewZlibArchive(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewZlibArchive() error = %v", err)
+	}
+
+	stats := za.Stats()
+	if len(stats.Files) != len(za.ArcRegistry) {
+		t.Fatalf("file count = %d, want %d", len(stats.Files), len(za.ArcRegistry))
+	}
+
+	var original, compressed int64
+	for i, rec := range za.ArcRegistry {
+		original += int64(rec.OSize)
+		compressed += int64(rec.CSize)
+		if stats.Files[i].Name != rec.Name || stats.Files[i].OriginalSize != int64(rec.OSize) ||
+			stats.Files[i].CompressedSize != int64(rec.CSize) {
+			t.Errorf("Files[%d] = %+v, registry = %+v", i, stats.Files[i], rec)
+		}
+	}
+	if stats.OriginalSize != original || stats.CompressedSize != compressed {
+		t.Errorf("totals = %d/%d, want %d/%d", stats.OriginalSize, stats.CompressedSize, original, compressed)
+	}
+	if stats.OriginalSize != 4008 {
+		t.Errorf("OriginalSize = %d, want 4008", stats.OriginalSize)
+	}
+	if stats.Files[0].Ratio >= 0.1 {
+		t.Errorf("Files[0].Ratio = %v, want < 0.1 for repetitive data", stats.Files[0].Ratio)
+	}
+	if stats.Files[1].Ratio != 1 || stats.Files[2].Ratio != 0 {
+		t.Errorf("stored/empty ratios = %v/%v, want 1/0", stats.Files[1].Ratio, stats.Files[2].Ratio)
+	}
+}
+
+func FuzzZlibArchive(f *testing.F) {
+	f.Add(buildTestArchive(
+		testArchiveFile{name: "temp.xgi", data: []byte("index")},
+		testArchiveFile{name: "temp.xg", data: testGameFile(testMatchHeader("Alice", "Bob", 5), testGameHeader(1, 0, 0))},
+		testArchiveFile{name: "temp.xgc", data: []byte("comments"), stored: true},
+	))
+	f.Add(buildTestGDFHeader("eXtreme Gammon 2.19")[:64])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		za, err := NewZlibArchive(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		for i := range za.ArcRegistry {
+			za.GetArchiveFile(&za.ArcRegistry[i])
+			za.getArchiveFilePrefix(&za.ArcRegistry[i], defaultRecordSize)
+		}
+	})
+}